@@ -1 +1,33 @@
 package v1
+
+import (
+  "time"
+)
+
+type SessionInfo struct {
+  ID          string    `json:"id"`
+  Account     string    `json:"account"`
+  TwitterID   int64     `json:"twitter_id"`
+  Node        int       `json:"node"`
+  Proxy       string    `json:"proxy"`
+  Type        int       `json:"type"`
+  Status      int       `json:"status"`
+  UnblockedAt int64     `json:"unblocked_at"`
+  FlushedAt   int64     `json:"flushed_at"`
+  ProbedAt    int64     `json:"probed_at"`
+  Timestamp   int64     `json:"timestamp"`
+  LastError   string    `json:"last_error"`
+  CreatedAt   time.Time `json:"created_at"`
+  UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type SessionEventInfo struct {
+  ID        string `json:"id"`
+  Kind      string `json:"kind"`
+  Operation string `json:"operation"`
+  Code      int    `json:"code"`
+  Status    int    `json:"status"`
+  Success   bool   `json:"success"`
+  Reason    string `json:"reason"`
+  Timestamp int64  `json:"timestamp"`
+}
@@ -14,6 +14,7 @@ func NewScrapersRouter(apiContext *common.ApiContext) http.Handler {
   r := chi.NewRouter()
   r.Mount("/posts", scrapers.NewPostsRouter(apiContext))
   r.Mount("/replies", scrapers.NewRepliesRouter(apiContext))
+  r.Mount("/follows", scrapers.NewFollowsRouter(apiContext))
   r.Mount("/media", clouds.NewMediaRouter(apiContext))
   return r
 }
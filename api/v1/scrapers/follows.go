@@ -0,0 +1,132 @@
+package scrapers
+
+import (
+  "fmt"
+  "net/http"
+  "strconv"
+
+  "github.com/go-chi/chi/v5"
+
+  "scraper.local/twitter-scraper/api"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type FollowsHandler struct {
+  ApiContext      *common.ApiContext
+  Response        *api.ResponseHandler
+  Repository      *repositories.FollowsRepository
+  UsersRepository *repositories.UsersRepository
+}
+
+func NewFollowsRouter(apiContext *common.ApiContext) http.Handler {
+  h := FollowsHandler{
+    ApiContext: apiContext,
+  }
+  h.Repository = &repositories.FollowsRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.UsersRepository = &repositories.UsersRepository{
+    Db: h.ApiContext.Db,
+  }
+
+  r := chi.NewRouter()
+  r.Get("/", h.Listings)
+  return r
+}
+
+func (h *FollowsHandler) Listings(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.ApiContext.Mux.Lock()
+  defer h.ApiContext.Mux.Unlock()
+
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  q := r.URL.Query()
+
+  var current int
+  if !q.Has("current") {
+    current = 1
+  }
+  current, _ = strconv.Atoi(q.Get("current"))
+  if current < 1 {
+    h.Response.Error(http.StatusForbidden, 1004, "current not valid")
+    return
+  }
+
+  var pageSize int
+  if !q.Has("page_size") {
+    pageSize = 50
+  } else {
+    pageSize, _ = strconv.Atoi(q.Get("page_size"))
+  }
+  if pageSize < 1 || pageSize > 100 {
+    h.Response.Error(http.StatusForbidden, 1004, "page size not valid")
+    return
+  }
+
+  if q.Get("account") == "" {
+    h.Response.Error(http.StatusForbidden, 1004, "account is empty")
+    return
+  }
+  user, err := h.UsersRepository.Get(q.Get("account"))
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1004, "account not found")
+    return
+  }
+
+  followType := q.Get("type")
+  conditions := make(map[string]interface{})
+  if followType == "followers" {
+    conditions["user_id"] = user.ID
+  } else if followType == "following" {
+    conditions["follower_id"] = user.ID
+  } else {
+    h.Response.Error(http.StatusForbidden, 1004, "type not valid")
+    return
+  }
+
+  if q.Get("status") != "" {
+    conditions["status"], _ = strconv.Atoi(q.Get("status"))
+  }
+
+  total := h.Repository.Count(conditions)
+  follows := h.Repository.Listings(conditions, current, pageSize)
+  data := make([]*FollowInfo, len(follows))
+  for i, follow := range follows {
+    data[i] = &FollowInfo{
+      ID:           follow.ID,
+      FirstSeenAt:  follow.FirstSeenAt,
+      LastSeenAt:   follow.LastSeenAt,
+      UnfollowedAt: follow.UnfollowedAt,
+      Status:       follow.Status,
+    }
+    otherID := follow.FollowerID
+    if followType == "following" {
+      otherID = follow.UserID
+    }
+    if other, err := h.UsersRepository.Find(otherID); err == nil {
+      data[i].UserInfo = &UserInfo{
+        ID:              other.ID,
+        Account:         other.Account,
+        UserID:          fmt.Sprint(other.UserID),
+        Name:            other.Name,
+        Description:     other.Description,
+        Avatar:          other.Avatar,
+        FavouritesCount: other.FavouritesCount,
+        FollowersCount:  other.FollowersCount,
+        FriendsCount:    other.FriendsCount,
+        ListedCount:     other.ListedCount,
+        MediaCount:      other.MediaCount,
+        RepliesCount:    other.RepliesCount,
+        Timestamp:       other.Timestamp,
+      }
+    }
+  }
+
+  h.Response.Pagenate(data, total, current, pageSize)
+}
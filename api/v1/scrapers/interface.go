@@ -1,23 +1,81 @@
 package scrapers
 
 type PostInfo struct {
+  ID            string         `json:"id"`
+  UserInfo      *UserInfo      `json:"user"`
+  TwitterID     string         `json:"twitter_id"`
+  StatusID      string         `json:"status_id"`
+  Kind          int            `json:"kind"`
+  Reference     *PostShortInfo `json:"reference"`
+  Content       string         `json:"content"`
+  Media         *MediaInfo     `json:"media"`
+  Metrics       *MetricsInfo   `json:"metrics"`
+  RemovedReason int            `json:"removed_reason"`
+  RemovedAt     int64          `json:"removed_at"`
+  Timestamp     int64          `json:"timestamp"`
+}
+
+type MetricsInfo struct {
+  FavoriteCount int   `json:"favorite_count"`
+  RetweetCount  int   `json:"retweet_count"`
+  ReplyCount    int   `json:"reply_count"`
+  QuoteCount    int   `json:"quote_count"`
+  BookmarkCount int   `json:"bookmark_count"`
+  ViewCount     int64 `json:"view_count"`
+}
+
+type MetricPointInfo struct {
+  MetricsInfo
+  Elapsed   int64 `json:"elapsed"`
+  Timestamp int64 `json:"timestamp"`
+}
+
+type MetricsCurveInfo struct {
+  Post   *PostShortInfo     `json:"post"`
+  Points []*MetricPointInfo `json:"points"`
+}
+
+type RevisionInfo struct {
   ID        string     `json:"id"`
-  UserInfo  *UserInfo  `json:"user"`
   TwitterID string     `json:"twitter_id"`
-  StatusID  string     `json:"status_id"`
   Content   string     `json:"content"`
   Media     *MediaInfo `json:"media"`
   Timestamp int64      `json:"timestamp"`
 }
 
+type RevisionsInfo struct {
+  Post          *PostShortInfo  `json:"post"`
+  EditTweetIDs  []string        `json:"edit_tweet_ids"`
+  EditableUntil int64           `json:"editable_until"`
+  Revisions     []*RevisionInfo `json:"revisions"`
+}
+
 type ReplyInfo struct {
-  ID        string         `json:"id"`
-  UserInfo  *UserInfo      `json:"user"`
-  Post      *PostShortInfo `json:"post"`
-  TwitterID string         `json:"twitter_id"`
-  Content   string         `json:"content"`
-  Media     *MediaInfo     `json:"media"`
-  Timestamp int64          `json:"timestamp"`
+  ID                string         `json:"id"`
+  UserInfo          *UserInfo      `json:"user"`
+  Post              *PostShortInfo `json:"post"`
+  TwitterID         string         `json:"twitter_id"`
+  InReplyToStatusID string         `json:"in_reply_to_status_id"`
+  ConversationID    string         `json:"conversation_id"`
+  Content           string         `json:"content"`
+  Media             *MediaInfo     `json:"media"`
+  Timestamp         int64          `json:"timestamp"`
+}
+
+type ReplyTreeInfo struct {
+  Post    *PostShortInfo   `json:"post"`
+  Replies []*ReplyNodeInfo `json:"replies"`
+}
+
+type ReplyNodeInfo struct {
+  ID                string           `json:"id"`
+  UserInfo          *UserInfo        `json:"user"`
+  TwitterID         string           `json:"twitter_id"`
+  InReplyToStatusID string           `json:"in_reply_to_status_id"`
+  Content           string           `json:"content"`
+  Media             *MediaInfo       `json:"media"`
+  Timestamp         int64            `json:"timestamp"`
+  Replies           []*ReplyNodeInfo `json:"replies"`
 }
 
 type PostShortInfo struct {
@@ -63,3 +121,12 @@ type VideoVariant struct {
   ContentType string `json:"content_type"`
   Url         string `json:"url"`
 }
+
+type FollowInfo struct {
+  ID           string    `json:"id"`
+  UserInfo     *UserInfo `json:"user"`
+  FirstSeenAt  int64     `json:"first_seen_at"`
+  LastSeenAt   int64     `json:"last_seen_at"`
+  UnfollowedAt int64     `json:"unfollowed_at"`
+  Status       int       `json:"status"`
+}
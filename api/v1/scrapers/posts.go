@@ -11,6 +11,7 @@ import (
   "regexp"
   "sort"
   "strconv"
+  "strings"
   "time"
 
   "github.com/go-chi/chi/v5"
@@ -20,6 +21,7 @@ import (
   "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/repositories"
   mediaRepositories "scraper.local/twitter-scraper/repositories/media"
+  scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
 )
 
 type PostsHandler struct {
@@ -30,6 +32,12 @@ type PostsHandler struct {
   UsersRepository       *repositories.UsersRepository
   MediaPhotosRepository *mediaRepositories.PhotosRepository
   MediaVideosRepository *mediaRepositories.VideosRepository
+  MetricsRepository     *repositories.MetricsRepository
+  EntitiesRepository    *repositories.EntitiesRepository
+  RevisionsRepository   *repositories.PostRevisionsRepository
+  SessionsRepository    *repositories.SessionsRepository
+  TasksRepository       *repositories.TasksRepository
+  ScrapersRepository    *scrapersRepositories.PostsRepository
 }
 
 func NewPostsRouter(apiContext *common.ApiContext) http.Handler {
@@ -56,8 +64,41 @@ func NewPostsRouter(apiContext *common.ApiContext) http.Handler {
     Ctx: h.ApiContext.Ctx,
   }
 
+  h.MetricsRepository = &repositories.MetricsRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.EntitiesRepository = &repositories.EntitiesRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.RevisionsRepository = &repositories.PostRevisionsRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.SessionsRepository = &repositories.SessionsRepository{
+    Db:  h.ApiContext.Db,
+    Rdb: h.ApiContext.Rdb,
+    Ctx: h.ApiContext.Ctx,
+  }
+  h.TasksRepository = &repositories.TasksRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.ScrapersRepository = &scrapersRepositories.PostsRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.ScrapersRepository.SessionsRepository = h.SessionsRepository
+  h.ScrapersRepository.UsersRepository = &repositories.UsersRepository{
+    Db:   h.ApiContext.Db,
+    Nats: h.ApiContext.Nats,
+  }
+  h.ScrapersRepository.PostsRepository = &repositories.PostsRepository{
+    Db:   h.ApiContext.Db,
+    Nats: h.ApiContext.Nats,
+  }
+
   r := chi.NewRouter()
   r.Get("/", h.Listings)
+  r.Get("/metrics", h.Metrics)
+  r.Get("/revisions", h.Revisions)
+  r.Post("/", h.Get)
   return r
 }
 
@@ -106,6 +147,30 @@ func (h *PostsHandler) Listings(
     conditions["account"] = r.URL.Query().Get("account")
   }
 
+  if r.URL.Query().Get("task_id") != "" {
+    conditions["task_id"] = r.URL.Query().Get("task_id")
+  }
+
+  if r.URL.Query().Get("hashtag") != "" {
+    conditions["hashtag"] = strings.ToLower(strings.TrimPrefix(r.URL.Query().Get("hashtag"), "#"))
+  }
+
+  if r.URL.Query().Get("mention") != "" {
+    conditions["mention"] = strings.ToLower(strings.TrimPrefix(r.URL.Query().Get("mention"), "@"))
+  }
+
+  if r.URL.Query().Get("cashtag") != "" {
+    conditions["cashtag"] = strings.ToLower(strings.TrimPrefix(r.URL.Query().Get("cashtag"), "$"))
+  }
+
+  if r.URL.Query().Get("kind") != "" {
+    conditions["kind"], _ = strconv.Atoi(r.URL.Query().Get("kind"))
+  }
+
+  if r.URL.Query().Get("retweets") == "0" {
+    conditions["retweets"] = false
+  }
+
   if r.URL.Query().Get("status") != "" {
     conditions["status"] = status
   }
@@ -132,6 +197,24 @@ func (h *PostsHandler) Listings(
 
   posts := h.Repository.Listings(conditions, current, pageSize)
   data := make([]*PostInfo, len(posts))
+
+  links := make(map[int64][]string)
+  if hideLinks == 1 {
+    twitterIDs := make([]int64, len(posts))
+    for i, post := range posts {
+      twitterIDs[i] = post.TwitterID
+    }
+    entities := h.EntitiesRepository.Listings(twitterIDs, []int{})
+    for _, entity := range entities {
+      if _, ok := links[entity.TwitterID]; !ok {
+        links[entity.TwitterID] = []string{}
+      }
+      if entity.Url != "" {
+        links[entity.TwitterID] = append(links[entity.TwitterID], entity.Url)
+      }
+    }
+  }
+
   for i, post := range posts {
     var mediaInfo *MediaInfo
     buf, _ := post.Media.MarshalJSON()
@@ -212,9 +295,29 @@ func (h *PostsHandler) Listings(
       ID:        post.ID,
       TwitterID: fmt.Sprint(post.TwitterID),
       StatusID:  fmt.Sprint(post.StatusID),
+      Kind:      post.Kind,
       Content:   post.Content,
       Media:     mediaInfo,
-      Timestamp: post.Timestamp,
+      Metrics: &MetricsInfo{
+        FavoriteCount: post.FavoriteCount,
+        RetweetCount:  post.RetweetCount,
+        ReplyCount:    post.ReplyCount,
+        QuoteCount:    post.QuoteCount,
+        BookmarkCount: post.BookmarkCount,
+        ViewCount:     post.ViewCount,
+      },
+      RemovedReason: post.RemovedReason,
+      RemovedAt:     post.RemovedAt,
+      Timestamp:     post.Timestamp,
+    }
+    if post.ReferenceID != "" {
+      if reference, err := h.Repository.Find(post.ReferenceID); err == nil {
+        data[i].Reference = &PostShortInfo{
+          ID:        reference.ID,
+          TwitterID: fmt.Sprint(reference.TwitterID),
+          StatusID:  fmt.Sprint(reference.StatusID),
+        }
+      }
     }
     if user, err := h.UsersRepository.Find(post.UserID); err == nil {
       url := user.Avatar
@@ -253,10 +356,157 @@ func (h *PostsHandler) Listings(
     }
 
     if hideLinks == 1 {
-      data[i].Content = m.ReplaceAllString(data[i].Content, "")
+      if urls, ok := links[post.TwitterID]; ok {
+        for _, url := range urls {
+          data[i].Content = strings.ReplaceAll(data[i].Content, url, "")
+        }
+      } else {
+        // posts scraped before entities were extracted
+        data[i].Content = m.ReplaceAllString(data[i].Content, "")
+      }
       data[i].UserInfo.Description = m.ReplaceAllString(data[i].UserInfo.Description, "")
     }
   }
 
   h.Response.Pagenate(data, total, current, pageSize)
 }
+
+func (h *PostsHandler) Get(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  r.ParseForm()
+
+  d := r.Form
+
+  twitterID, err := scrapersRepositories.ParseStatusUrl(d.Get("url"))
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1004, "url not valid")
+    return
+  }
+  withReplies, _ := strconv.ParseBool(d.Get("replies"))
+
+  session := h.SessionsRepository.Special("TweetResultByRestId")
+  if session == nil {
+    h.Response.Error(http.StatusForbidden, 1000, "current session is empty")
+    return
+  }
+
+  post, err := h.ScrapersRepository.Get(r.Context(), session, twitterID)
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1000, "post scraper failed")
+    return
+  }
+
+  if withReplies {
+    name := fmt.Sprintf("%v@replies", post.ID)
+    params := map[string]interface{}{
+      "post_id": post.ID,
+    }
+    if err = h.TasksRepository.Apply(name, config.TASK_ACTION_SCRAPERS_REPLIES, params); err != nil {
+      h.Response.Error(http.StatusForbidden, 1000, "task apply failed")
+      return
+    }
+  }
+
+  h.Response.Json(&PostShortInfo{
+    ID:        post.ID,
+    TwitterID: fmt.Sprint(post.TwitterID),
+    StatusID:  fmt.Sprint(post.StatusID),
+  })
+}
+
+func (h *PostsHandler) Metrics(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.ApiContext.Mux.Lock()
+  defer h.ApiContext.Mux.Unlock()
+
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  post, err := h.Repository.Find(r.URL.Query().Get("post_id"))
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1004, "post not found")
+    return
+  }
+
+  metrics := h.MetricsRepository.Listings(post.TwitterID, config.SCRAPERS_METRICS_CURVE_LIMIT)
+  data := &MetricsCurveInfo{
+    Post: &PostShortInfo{
+      ID:        post.ID,
+      TwitterID: fmt.Sprint(post.TwitterID),
+      StatusID:  fmt.Sprint(post.StatusID),
+    },
+    Points: make([]*MetricPointInfo, len(metrics)),
+  }
+  for i, metric := range metrics {
+    data.Points[i] = &MetricPointInfo{
+      MetricsInfo: MetricsInfo{
+        FavoriteCount: metric.FavoriteCount,
+        RetweetCount:  metric.RetweetCount,
+        ReplyCount:    metric.ReplyCount,
+        QuoteCount:    metric.QuoteCount,
+        BookmarkCount: metric.BookmarkCount,
+        ViewCount:     metric.ViewCount,
+      },
+      Elapsed:   metric.Timestamp - post.Timestamp,
+      Timestamp: metric.Timestamp,
+    }
+  }
+
+  h.Response.Json(data)
+}
+
+func (h *PostsHandler) Revisions(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.ApiContext.Mux.Lock()
+  defer h.ApiContext.Mux.Unlock()
+
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  post, err := h.Repository.Find(r.URL.Query().Get("post_id"))
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1004, "post not found")
+    return
+  }
+
+  revisions := h.RevisionsRepository.Listings(post.ID, config.SCRAPERS_REVISIONS_LIMIT)
+  data := &RevisionsInfo{
+    Post: &PostShortInfo{
+      ID:        post.ID,
+      TwitterID: fmt.Sprint(post.TwitterID),
+      StatusID:  fmt.Sprint(post.StatusID),
+    },
+    EditTweetIDs:  []string{},
+    EditableUntil: post.EditableUntil,
+    Revisions:     make([]*RevisionInfo, len(revisions)),
+  }
+  if post.EditTweetIDs != "" {
+    data.EditTweetIDs = strings.Split(post.EditTweetIDs, ",")
+  }
+  for i, revision := range revisions {
+    var mediaInfo *MediaInfo
+    buf, _ := revision.Media.MarshalJSON()
+    json.Unmarshal(buf, &mediaInfo)
+    data.Revisions[i] = &RevisionInfo{
+      ID:        revision.ID,
+      TwitterID: fmt.Sprint(revision.TwitterID),
+      Content:   revision.Content,
+      Media:     mediaInfo,
+      Timestamp: revision.Timestamp,
+    }
+  }
+
+  h.Response.Json(data)
+}
@@ -57,6 +57,7 @@ func NewRepliesRouter(apiContext *common.ApiContext) http.Handler {
 
   r := chi.NewRouter()
   r.Get("/", h.Listings)
+  r.Get("/tree", h.Tree)
   return r
 }
 
@@ -213,11 +214,13 @@ func (h *RepliesHandler) Listings(
     }
 
     data[i] = &ReplyInfo{
-      ID:        reply.ID,
-      TwitterID: fmt.Sprint(reply.TwitterID),
-      Content:   reply.Content,
-      Media:     mediaInfo,
-      Timestamp: reply.Timestamp,
+      ID:                reply.ID,
+      TwitterID:         fmt.Sprint(reply.TwitterID),
+      InReplyToStatusID: fmt.Sprint(reply.InReplyToStatusID),
+      ConversationID:    fmt.Sprint(reply.ConversationID),
+      Content:           reply.Content,
+      Media:             mediaInfo,
+      Timestamp:         reply.Timestamp,
     }
     if post, err := h.PostsRepository.Find(reply.PostID); err == nil {
       data[i].Post = &PostShortInfo{
@@ -270,3 +273,81 @@ func (h *RepliesHandler) Listings(
 
   h.Response.Pagenate(data, total, current, pageSize)
 }
+
+func (h *RepliesHandler) Tree(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.ApiContext.Mux.Lock()
+  defer h.ApiContext.Mux.Unlock()
+
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  post, err := h.PostsRepository.Find(r.URL.Query().Get("post_id"))
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1004, "post not found")
+    return
+  }
+
+  replies := h.Repository.Thread(post.ID, config.SCRAPERS_REPLIES_THREAD_LIMIT)
+  nodes := make(map[int64]*ReplyNodeInfo, len(replies))
+  users := make(map[string]*UserInfo)
+  for _, reply := range replies {
+    var mediaInfo *MediaInfo
+    buf, _ := reply.Media.MarshalJSON()
+    json.Unmarshal(buf, &mediaInfo)
+
+    if _, ok := users[reply.UserID]; !ok {
+      if user, err := h.UsersRepository.Find(reply.UserID); err == nil {
+        users[reply.UserID] = &UserInfo{
+          ID:              user.ID,
+          Account:         user.Account,
+          UserID:          fmt.Sprint(user.UserID),
+          Name:            user.Name,
+          Description:     user.Description,
+          Avatar:          user.Avatar,
+          FavouritesCount: user.FavouritesCount,
+          FollowersCount:  user.FollowersCount,
+          FriendsCount:    user.FriendsCount,
+          ListedCount:     user.ListedCount,
+          MediaCount:      user.MediaCount,
+          RepliesCount:    user.RepliesCount,
+          Timestamp:       user.Timestamp,
+        }
+      }
+    }
+
+    nodes[reply.TwitterID] = &ReplyNodeInfo{
+      ID:                reply.ID,
+      UserInfo:          users[reply.UserID],
+      TwitterID:         fmt.Sprint(reply.TwitterID),
+      InReplyToStatusID: fmt.Sprint(reply.InReplyToStatusID),
+      Content:           reply.Content,
+      Media:             mediaInfo,
+      Timestamp:         reply.Timestamp,
+      Replies:           []*ReplyNodeInfo{},
+    }
+  }
+
+  // replies whose parent was not scraped are attached to the post itself
+  data := &ReplyTreeInfo{
+    Post: &PostShortInfo{
+      ID:        post.ID,
+      TwitterID: fmt.Sprint(post.TwitterID),
+      StatusID:  fmt.Sprint(post.StatusID),
+    },
+    Replies: []*ReplyNodeInfo{},
+  }
+  for _, reply := range replies {
+    node := nodes[reply.TwitterID]
+    if parent, ok := nodes[reply.InReplyToStatusID]; ok && reply.InReplyToStatusID != reply.TwitterID {
+      parent.Replies = append(parent.Replies, node)
+    } else {
+      data.Replies = append(data.Replies, node)
+    }
+  }
+
+  h.Response.Json(data)
+}
@@ -0,0 +1,455 @@
+package v1
+
+import (
+  "io"
+  "net/http"
+  "strconv"
+  "strings"
+  "time"
+
+  "github.com/go-chi/chi/v5"
+
+  "scraper.local/twitter-scraper/api"
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type SessionsHandler struct {
+  ApiContext *common.ApiContext
+  Repository *repositories.SessionsRepository
+}
+
+func NewSessionsRouter(apiContext *common.ApiContext) http.Handler {
+  h := SessionsHandler{
+    ApiContext: apiContext,
+  }
+  h.Repository = &repositories.SessionsRepository{
+    Db:  h.ApiContext.Db,
+    Rdb: h.ApiContext.Rdb,
+    Ctx: h.ApiContext.Ctx,
+  }
+
+  r := chi.NewRouter()
+  r.Use(api.Authenticator)
+  r.Get("/", h.Listings)
+  r.Post("/", h.Import)
+  r.Post("/import", h.Import)
+  r.Put("/{id}/cookie", h.Cookie)
+  r.Put("/{id}/proxy", h.Proxy)
+  r.Post("/{id}/flush", h.Flush)
+  r.Post("/{id}/block", h.Block)
+  r.Post("/{id}/unblock", h.Unblock)
+  r.Post("/{id}/probe", h.Probe)
+  r.Get("/{id}/health", h.Health)
+  r.Get("/{id}/events", h.Events)
+  r.Put("/{id}/status", h.Status)
+  r.Delete("/{id}", h.Delete)
+
+  return r
+}
+
+func (h *SessionsHandler) Listings(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  q := r.URL.Query()
+
+  current := 1
+  if q.Has("current") {
+    current, _ = strconv.Atoi(q.Get("current"))
+  }
+  if current < 1 {
+    response.Error(http.StatusForbidden, 1004, "current not valid")
+    return
+  }
+
+  pageSize := 50
+  if q.Has("page_size") {
+    pageSize, _ = strconv.Atoi(q.Get("page_size"))
+  }
+  if pageSize < 1 || pageSize > 100 {
+    response.Error(http.StatusForbidden, 1004, "page size not valid")
+    return
+  }
+
+  conditions := map[string]interface{}{}
+  if q.Get("account") != "" {
+    conditions["account"] = q.Get("account")
+  }
+  for _, key := range []string{"node", "type", "status"} {
+    if q.Get(key) != "" {
+      conditions[key], _ = strconv.Atoi(q.Get(key))
+    }
+  }
+
+  total := h.Repository.Count(conditions)
+  sessions := h.Repository.Listings(conditions, current, pageSize)
+  data := make([]*SessionInfo, len(sessions))
+  for i, session := range sessions {
+    data[i] = h.info(session)
+  }
+
+  response.Pagenate(data, total, current, pageSize)
+}
+
+// Import creates the session of the cookies, or replaces the cookies of the
+// account. The cookies are a cookie header, a cookies.txt or a json export.
+func (h *SessionsHandler) Import(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  r.ParseMultipartForm(1 << 20)
+
+  account := strings.TrimSpace(r.Form.Get("account"))
+  proxy, err := h.proxy(r)
+  if err != nil {
+    response.Error(http.StatusForbidden, 1004, err.Error())
+    return
+  }
+
+  h.apply(response, r, account, proxy)
+}
+
+func (h *SessionsHandler) Cookie(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  r.ParseMultipartForm(1 << 20)
+
+  h.apply(response, r, session.Account, "")
+}
+
+// Proxy routes the session through the proxy reference, an empty reference
+// dials directly.
+func (h *SessionsHandler) Proxy(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  r.ParseForm()
+
+  proxy, err := h.proxy(r)
+  if err != nil {
+    response.Error(http.StatusForbidden, 1004, err.Error())
+    return
+  }
+
+  h.Repository.Update(session, "proxy", common.Secret(proxy))
+
+  response.Json(nil)
+}
+
+func (h *SessionsHandler) Flush(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  if err := h.Repository.Flush(r.Context(), session); err != nil {
+    response.Error(http.StatusForbidden, 1000, "session flush failed")
+    return
+  }
+
+  response.Json(nil)
+}
+
+// Block keeps the session out of the scheduler for the duration in seconds.
+func (h *SessionsHandler) Block(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  r.ParseForm()
+
+  duration, _ := strconv.ParseInt(r.Form.Get("duration"), 10, 64)
+  if duration < 1 {
+    response.Error(http.StatusForbidden, 1004, "duration not valid")
+    return
+  }
+
+  unblockedAt := time.Now().Add(time.Duration(duration) * time.Second).UnixMicro()
+  if err := h.Repository.Block(session, unblockedAt); err != nil {
+    response.Error(http.StatusForbidden, 1000, "session block failed")
+    return
+  }
+
+  response.Json(nil)
+}
+
+func (h *SessionsHandler) Unblock(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  if err := h.Repository.Unblock(session); err != nil {
+    response.Error(http.StatusForbidden, 1000, "session unblock failed")
+    return
+  }
+
+  response.Json(nil)
+}
+
+// Status switches the role of the session, 1 for normal and 8 for special.
+func (h *SessionsHandler) Status(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  r.ParseForm()
+
+  status, _ := strconv.Atoi(r.Form.Get("status"))
+  if status != 1 && status != 8 {
+    response.Error(http.StatusForbidden, 1004, "status not valid")
+    return
+  }
+
+  h.Repository.Status(session, status, "status changed by api")
+
+  response.Json(nil)
+}
+
+// Probe runs the health probe of the session right away.
+func (h *SessionsHandler) Probe(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  if err := h.Repository.Probe(r.Context(), session); err != nil {
+    response.Error(http.StatusForbidden, 1000, "session probe failed")
+    return
+  }
+
+  response.Json(h.Repository.Health(session))
+}
+
+func (h *SessionsHandler) Health(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  response.Json(h.Repository.Health(session))
+}
+
+func (h *SessionsHandler) Events(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  q := r.URL.Query()
+
+  current := 1
+  if q.Has("current") {
+    current, _ = strconv.Atoi(q.Get("current"))
+  }
+  if current < 1 {
+    response.Error(http.StatusForbidden, 1004, "current not valid")
+    return
+  }
+
+  pageSize := 50
+  if q.Has("page_size") {
+    pageSize, _ = strconv.Atoi(q.Get("page_size"))
+  }
+  if pageSize < 1 || pageSize > 100 {
+    response.Error(http.StatusForbidden, 1004, "page size not valid")
+    return
+  }
+
+  total := h.Repository.Events().Count(session.ID)
+  events := h.Repository.Events().Listings(session.ID, current, pageSize)
+  data := make([]*SessionEventInfo, len(events))
+  for i, event := range events {
+    data[i] = &SessionEventInfo{
+      ID:        event.ID,
+      Kind:      event.Kind,
+      Operation: event.Operation,
+      Code:      event.Code,
+      Status:    event.Status,
+      Success:   event.Success,
+      Reason:    event.Reason,
+      Timestamp: event.Timestamp,
+    }
+  }
+
+  response.Pagenate(data, total, current, pageSize)
+}
+
+func (h *SessionsHandler) Delete(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  response := &api.ResponseHandler{
+    Writer: w,
+  }
+
+  session := h.session(response, r)
+  if session == nil {
+    return
+  }
+
+  if err := h.Repository.Delete(session); err != nil {
+    response.Error(http.StatusForbidden, 1000, "session delete failed")
+    return
+  }
+
+  response.Json(nil)
+}
+
+func (h *SessionsHandler) apply(
+  response *api.ResponseHandler,
+  r *http.Request,
+  account string,
+  proxy string,
+) {
+  content := []byte(r.Form.Get("cookies"))
+  if file, _, err := r.FormFile("file"); err == nil {
+    defer file.Close()
+    content, _ = io.ReadAll(io.LimitReader(file, 1<<20))
+  }
+
+  cookies, err := clients.ParseCookies(content)
+  if err != nil {
+    response.Error(http.StatusForbidden, 1004, err.Error())
+    return
+  }
+
+  session, err := h.Repository.Import(r.Context(), account, cookies, proxy)
+  if err != nil {
+    response.Error(http.StatusForbidden, 1000, err.Error())
+    return
+  }
+
+  if err := h.Repository.Flush(r.Context(), session); err != nil {
+    response.Error(http.StatusForbidden, 1000, "session flush failed")
+    return
+  }
+
+  response.Json(h.info(session))
+}
+
+// proxy reads the proxy reference of the form, the slot of older clients
+// stands for its tor slot.
+func (h *SessionsHandler) proxy(r *http.Request) (string, error) {
+  value := strings.TrimSpace(r.Form.Get("proxy"))
+  if value == "" {
+    value = strings.TrimSpace(r.Form.Get("slot"))
+  }
+  u, err := common.ParseProxy(value)
+  if err != nil || u == nil {
+    return "", err
+  }
+  return u.String(), nil
+}
+
+func (h *SessionsHandler) session(
+  response *api.ResponseHandler,
+  r *http.Request,
+) *models.Session {
+  session, err := h.Repository.Find(chi.URLParam(r, "id"))
+  if err != nil {
+    response.Error(http.StatusNotFound, 1000, "session not exists")
+    return nil
+  }
+  return session
+}
+
+func (h *SessionsHandler) info(session *models.Session) *SessionInfo {
+  return &SessionInfo{
+    ID:          session.ID,
+    Account:     session.Account,
+    TwitterID:   session.TwitterID,
+    Node:        session.Node,
+    Proxy:       common.ProxyName(string(session.Proxy)),
+    Type:        session.Type,
+    Status:      session.Status,
+    UnblockedAt: session.UnblockedAt,
+    FlushedAt:   session.FlushedAt,
+    ProbedAt:    session.ProbedAt,
+    Timestamp:   session.Timestamp,
+    LastError:   session.LastError,
+    CreatedAt:   session.CreatedAt,
+    UpdatedAt:   session.UpdatedAt,
+  }
+}
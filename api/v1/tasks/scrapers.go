@@ -12,5 +12,7 @@ import (
 func NewScrapersRouter(apiContext *common.ApiContext) http.Handler {
   r := chi.NewRouter()
   r.Mount("/posts", scrapers.NewPostsRouter(apiContext))
+  r.Mount("/search", scrapers.NewSearchRouter(apiContext))
+  r.Mount("/follows", scrapers.NewFollowsRouter(apiContext))
   return r
 }
@@ -0,0 +1,195 @@
+package scrapers
+
+import (
+  "errors"
+  "fmt"
+  "net/http"
+  "regexp"
+  "strconv"
+  "strings"
+
+  "github.com/go-chi/chi/v5"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/api"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/repositories"
+  scrapersRepository "scraper.local/twitter-scraper/repositories/scrapers"
+)
+
+type FollowsHandler struct {
+  ApiContext         *common.ApiContext
+  Response           *api.ResponseHandler
+  Repository         *repositories.TasksRepository
+  SessionsRepository *repositories.SessionsRepository
+  UsersRepository    *repositories.UsersRepository
+  FollowsRepository  *repositories.FollowsRepository
+  ScrapersRepository *scrapersRepository.UsersRepository
+}
+
+func NewFollowsRouter(apiContext *common.ApiContext) http.Handler {
+  h := FollowsHandler{
+    ApiContext: apiContext,
+  }
+  h.Repository = &repositories.TasksRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.SessionsRepository = &repositories.SessionsRepository{
+    Db:  h.ApiContext.Db,
+    Rdb: h.ApiContext.Rdb,
+    Ctx: h.ApiContext.Ctx,
+  }
+  h.UsersRepository = &repositories.UsersRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.FollowsRepository = &repositories.FollowsRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.ScrapersRepository = &scrapersRepository.UsersRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.ScrapersRepository.SessionsRepository = h.SessionsRepository
+  h.ScrapersRepository.UsersRepository = h.UsersRepository
+
+  r := chi.NewRouter()
+  r.Get("/", h.Listings)
+  r.Post("/", h.Apply)
+  r.Put("/", h.Apply)
+
+  return r
+}
+
+func (h *FollowsHandler) Listings(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.ApiContext.Mux.Lock()
+  defer h.ApiContext.Mux.Unlock()
+
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  q := r.URL.Query()
+
+  var current int
+  if !q.Has("current") {
+    current = 1
+  }
+  current, _ = strconv.Atoi(r.URL.Query().Get("current"))
+  if current < 1 {
+    h.Response.Error(http.StatusForbidden, 1004, "current not valid")
+    return
+  }
+
+  var pageSize int
+  if !q.Has("page_size") {
+    pageSize = 50
+  } else {
+    pageSize, _ = strconv.Atoi(r.URL.Query().Get("page_size"))
+  }
+  if pageSize < 1 || pageSize > 100 {
+    h.Response.Error(http.StatusForbidden, 1004, "page size not valid")
+    return
+  }
+
+  conditions := map[string]interface{}{
+    "action": config.TASK_ACTION_SCRAPERS_FOLLOWS,
+  }
+
+  if q.Get("status") != "" {
+    conditions["status"], _ = strconv.Atoi(r.URL.Query().Get("status"))
+  }
+
+  total := h.Repository.Count(conditions)
+  tasks := h.Repository.Listings(conditions, current, pageSize)
+  data := make([]*FollowInfo, len(tasks))
+  for i, task := range tasks {
+    data[i] = &FollowInfo{
+      ID:        task.ID,
+      Timestamp: task.Timestamp,
+      Status:    task.Status,
+      CreatedAt: task.CreatedAt,
+      UpdatedAt: task.UpdatedAt,
+    }
+    userID := task.Params["user_id"].(string)
+    if user, err := h.UsersRepository.Find(userID); err == nil {
+      data[i].Account = user.Account
+    }
+    if followType, ok := task.Params["type"]; ok {
+      data[i].Type = followType.(string)
+    }
+    edges := map[string]interface{}{
+      "status": 1,
+    }
+    if data[i].Type == "followers" {
+      edges["user_id"] = userID
+    } else {
+      edges["follower_id"] = userID
+    }
+    data[i].ActiveCount = h.FollowsRepository.Count(edges)
+  }
+
+  h.Response.Pagenate(data, total, current, pageSize)
+}
+
+func (h *FollowsHandler) Apply(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  r.ParseForm()
+
+  d := r.Form
+
+  account := strings.TrimSpace(d.Get("account"))
+  followType := strings.TrimSpace(d.Get("type"))
+
+  if account == "" {
+    h.Response.Error(http.StatusForbidden, 1004, "account is empty")
+    return
+  }
+
+  if followType != "followers" && followType != "following" {
+    h.Response.Error(http.StatusForbidden, 1004, "type not valid")
+    return
+  }
+
+  re := regexp.MustCompile(`([a-zA-Z0-9-_]*)$`)
+  matches := re.FindStringSubmatch(account)
+  if len(matches) > 1 {
+    account = matches[1]
+  }
+
+  user, err := h.UsersRepository.Get(account)
+  if errors.Is(err, gorm.ErrRecordNotFound) {
+    session := h.SessionsRepository.Special("UserByScreenName")
+    if session == nil {
+      h.Response.Error(http.StatusForbidden, 1000, "current session is empty")
+      return
+    }
+    user, err = h.ScrapersRepository.Process(r.Context(), session, account)
+    if err != nil {
+      h.Response.Error(http.StatusForbidden, 1000, "user scraper failed")
+      return
+    }
+  }
+
+  name := fmt.Sprintf("%v@%v", user.ID, followType)
+  params := map[string]interface{}{
+    "user_id": user.ID,
+    "type":    followType,
+  }
+
+  err = h.Repository.Apply(name, config.TASK_ACTION_SCRAPERS_FOLLOWS, params)
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1000, "task apply failed")
+    return
+  }
+
+  h.Response.Json(nil)
+}
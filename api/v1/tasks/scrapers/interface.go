@@ -7,8 +7,30 @@ import (
 type PostInfo struct {
   ID        string    `json:"id"`
   Account   string    `json:"account"`
+  MediaOnly bool      `json:"media_only"`
   Timestamp int64     `json:"timestamp"`
   Status    int       `json:"status"`
   CreatedAt time.Time `json:"created_at"`
   UpdatedAt time.Time `json:"updated_at"`
 }
+
+type SearchInfo struct {
+  ID         string    `json:"id"`
+  Query      string    `json:"query"`
+  PostsCount int64     `json:"posts_count"`
+  Timestamp  int64     `json:"timestamp"`
+  Status     int       `json:"status"`
+  CreatedAt  time.Time `json:"created_at"`
+  UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type FollowInfo struct {
+  ID          string    `json:"id"`
+  Account     string    `json:"account"`
+  Type        string    `json:"type"`
+  ActiveCount int64     `json:"active_count"`
+  Timestamp   int64     `json:"timestamp"`
+  Status      int       `json:"status"`
+  CreatedAt   time.Time `json:"created_at"`
+  UpdatedAt   time.Time `json:"updated_at"`
+}
@@ -35,7 +35,9 @@ func NewPostsRouter(apiContext *common.ApiContext) http.Handler {
     Db: h.ApiContext.Db,
   }
   h.SessionsRepository = &repositories.SessionsRepository{
-    Db: h.ApiContext.Db,
+    Db:  h.ApiContext.Db,
+    Rdb: h.ApiContext.Rdb,
+    Ctx: h.ApiContext.Ctx,
   }
   h.UsersRepository = &repositories.UsersRepository{
     Db: h.ApiContext.Db,
@@ -43,6 +45,7 @@ func NewPostsRouter(apiContext *common.ApiContext) http.Handler {
   h.ScrapersRepository = &scrapersRepository.UsersRepository{
     Db: h.ApiContext.Db,
   }
+  h.ScrapersRepository.SessionsRepository = h.SessionsRepository
   h.ScrapersRepository.UsersRepository = &repositories.UsersRepository{
     Db: h.ApiContext.Db,
   }
@@ -115,6 +118,9 @@ func (h *PostsHandler) Listings(
       CreatedAt: task.CreatedAt,
       UpdatedAt: task.UpdatedAt,
     }
+    if mediaOnly, ok := task.Params["media_only"]; ok {
+      data[i].MediaOnly = mediaOnly.(bool)
+    }
   }
 
   h.Response.Pagenate(data, total, current, pageSize)
@@ -133,6 +139,7 @@ func (h *PostsHandler) Apply(
   d := r.Form
 
   account := strings.TrimSpace(d.Get("account"))
+  mediaOnly, _ := strconv.ParseBool(d.Get("media_only"))
 
   if account == "" {
     h.Response.Error(http.StatusForbidden, 1004, "account is empty")
@@ -147,12 +154,12 @@ func (h *PostsHandler) Apply(
 
   user, err := h.UsersRepository.Get(account)
   if errors.Is(err, gorm.ErrRecordNotFound) {
-    session := h.SessionsRepository.Special()
+    session := h.SessionsRepository.Special("UserByScreenName")
     if session == nil {
       h.Response.Error(http.StatusForbidden, 1000, "current session is empty")
       return
     }
-    user, err = h.ScrapersRepository.Process(session, account)
+    user, err = h.ScrapersRepository.Process(r.Context(), session, account)
     if err != nil {
       h.Response.Error(http.StatusForbidden, 1000, "user scraper failed")
       return
@@ -161,7 +168,8 @@ func (h *PostsHandler) Apply(
 
   name := fmt.Sprintf("%v@posts", user.ID)
   params := map[string]interface{}{
-    "user_id": user.ID,
+    "user_id":    user.ID,
+    "media_only": mediaOnly,
   }
 
   err = h.Repository.Apply(name, config.TASK_ACTION_SCRAPERS_POSTS, params)
@@ -0,0 +1,139 @@
+package scrapers
+
+import (
+  "crypto/md5"
+  "encoding/hex"
+  "fmt"
+  "net/http"
+  "strconv"
+  "strings"
+
+  "github.com/go-chi/chi/v5"
+
+  "scraper.local/twitter-scraper/api"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type SearchHandler struct {
+  ApiContext       *common.ApiContext
+  Response         *api.ResponseHandler
+  Repository       *repositories.TasksRepository
+  SearchRepository *repositories.SearchRepository
+}
+
+func NewSearchRouter(apiContext *common.ApiContext) http.Handler {
+  h := SearchHandler{
+    ApiContext: apiContext,
+  }
+  h.Repository = &repositories.TasksRepository{
+    Db: h.ApiContext.Db,
+  }
+  h.SearchRepository = &repositories.SearchRepository{
+    Db: h.ApiContext.Db,
+  }
+
+  r := chi.NewRouter()
+  r.Get("/", h.Listings)
+  r.Post("/", h.Apply)
+  r.Put("/", h.Apply)
+
+  return r
+}
+
+func (h *SearchHandler) Listings(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.ApiContext.Mux.Lock()
+  defer h.ApiContext.Mux.Unlock()
+
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  q := r.URL.Query()
+
+  var current int
+  if !q.Has("current") {
+    current = 1
+  }
+  current, _ = strconv.Atoi(r.URL.Query().Get("current"))
+  if current < 1 {
+    h.Response.Error(http.StatusForbidden, 1004, "current not valid")
+    return
+  }
+
+  var pageSize int
+  if !q.Has("page_size") {
+    pageSize = 50
+  } else {
+    pageSize, _ = strconv.Atoi(r.URL.Query().Get("page_size"))
+  }
+  if pageSize < 1 || pageSize > 100 {
+    h.Response.Error(http.StatusForbidden, 1004, "page size not valid")
+    return
+  }
+
+  conditions := map[string]interface{}{
+    "action": config.TASK_ACTION_SCRAPERS_SEARCH,
+  }
+
+  if q.Get("status") != "" {
+    conditions["status"], _ = strconv.Atoi(r.URL.Query().Get("status"))
+  }
+
+  total := h.Repository.Count(conditions)
+  tasks := h.Repository.Listings(conditions, current, pageSize)
+  data := make([]*SearchInfo, len(tasks))
+  for i, task := range tasks {
+    data[i] = &SearchInfo{
+      ID:         task.ID,
+      PostsCount: h.SearchRepository.Count(task.ID),
+      Timestamp:  task.Timestamp,
+      Status:     task.Status,
+      CreatedAt:  task.CreatedAt,
+      UpdatedAt:  task.UpdatedAt,
+    }
+    if query, ok := task.Params["query"]; ok {
+      data[i].Query = query.(string)
+    }
+  }
+
+  h.Response.Pagenate(data, total, current, pageSize)
+}
+
+func (h *SearchHandler) Apply(
+  w http.ResponseWriter,
+  r *http.Request,
+) {
+  h.Response = &api.ResponseHandler{
+    Writer: w,
+  }
+
+  r.ParseForm()
+
+  d := r.Form
+
+  query := strings.TrimSpace(d.Get("query"))
+
+  if query == "" {
+    h.Response.Error(http.StatusForbidden, 1004, "query is empty")
+    return
+  }
+
+  hash := md5.Sum([]byte(query))
+  name := fmt.Sprintf("%v@search", hex.EncodeToString(hash[:]))
+  params := map[string]interface{}{
+    "query": query,
+  }
+
+  err := h.Repository.Apply(name, config.TASK_ACTION_SCRAPERS_SEARCH, params)
+  if err != nil {
+    h.Response.Error(http.StatusForbidden, 1000, "task apply failed")
+    return
+  }
+
+  h.Response.Json(nil)
+}
@@ -0,0 +1,129 @@
+package clients
+
+import (
+  "context"
+  "errors"
+  "io"
+  "log"
+  "math/rand"
+  "net"
+  "net/http"
+  "syscall"
+  "time"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+)
+
+type Client struct {
+  Proxy   string
+  Timeout time.Duration
+  Retries int
+}
+
+func NewClient(proxy string, timeout time.Duration) *Client {
+  return &Client{
+    Proxy:   proxy,
+    Timeout: timeout,
+    Retries: config.HTTP_RETRIES_LIMIT,
+  }
+}
+
+// Do sends the request with the context, transient network errors and
+// gateway failures are retried with a jittered exponential backoff.
+func (c *Client) Do(ctx context.Context, req *http.Request) (resp *http.Response, err error) {
+  httpClient := &http.Client{
+    Transport: c.Transport(),
+    Timeout:   c.Timeout,
+  }
+
+  for attempt := 0; ; attempt++ {
+    r := req.Clone(ctx)
+    if req.Body != nil && req.GetBody != nil {
+      if r.Body, err = req.GetBody(); err != nil {
+        return
+      }
+    }
+
+    resp, err = httpClient.Do(r)
+    if err == nil && !IsTransientStatus(resp.StatusCode) {
+      return
+    }
+    if err != nil && !IsTransient(err) {
+      return
+    }
+    if attempt >= c.Retries || (req.Body != nil && req.GetBody == nil) {
+      return
+    }
+    if resp != nil {
+      io.Copy(io.Discard, resp.Body)
+      resp.Body.Close()
+    }
+
+    delay := Backoff(attempt)
+    if err != nil {
+      log.Println("request retrying", common.ProxyName(c.Proxy), attempt+1, delay, err)
+    } else {
+      log.Println("request retrying", common.ProxyName(c.Proxy), attempt+1, delay, resp.Status)
+    }
+
+    select {
+    case <-ctx.Done():
+      return nil, ctx.Err()
+    case <-time.After(delay):
+    }
+  }
+}
+
+// Transport wraps the pooled transport of the proxy with the record or
+// replay transport when one of them is turned on.
+func (c *Client) Transport() http.RoundTripper {
+  var tr http.RoundTripper = Transport(c.Proxy)
+  if dir := ReplayDir(); dir != "" {
+    return &ReplayTransport{
+      Dir:  dir,
+      Base: tr,
+    }
+  }
+  if dir := RecordDir(); dir != "" {
+    return &RecordTransport{
+      Dir:  dir,
+      Base: tr,
+    }
+  }
+  return tr
+}
+
+// Backoff doubles the base delay on every attempt and picks a random point
+// in the upper half of it.
+func Backoff(attempt int) time.Duration {
+  delay := time.Duration(config.HTTP_RETRY_BACKOFF) * time.Millisecond << attempt
+  if delay > time.Duration(config.HTTP_RETRY_BACKOFF_LIMIT)*time.Millisecond {
+    delay = time.Duration(config.HTTP_RETRY_BACKOFF_LIMIT) * time.Millisecond
+  }
+  return delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+}
+
+func IsTransient(err error) bool {
+  if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+    return false
+  }
+  if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+    return true
+  }
+  if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) {
+    return true
+  }
+  var netErr net.Error
+  if errors.As(err, &netErr) && netErr.Timeout() {
+    return true
+  }
+  var opErr *net.OpError
+  return errors.As(err, &opErr)
+}
+
+func IsTransientStatus(code int) bool {
+  return code == http.StatusBadGateway ||
+    code == http.StatusServiceUnavailable ||
+    code == http.StatusGatewayTimeout
+}
@@ -0,0 +1,145 @@
+package clients
+
+import (
+  "bufio"
+  "bytes"
+  "encoding/json"
+  "errors"
+  "net/url"
+  "sort"
+  "strconv"
+  "strings"
+)
+
+type exportCookie struct {
+  Domain string `json:"domain"`
+  Name   string `json:"name"`
+  Value  string `json:"value"`
+}
+
+// ParseCookies reads the twitter cookies of a Netscape cookies.txt, a browser
+// extension JSON export or a raw cookie header.
+func ParseCookies(content []byte) (cookies map[string]string, err error) {
+  content = bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+  if len(content) == 0 {
+    return nil, errors.New("cookies are empty")
+  }
+
+  switch {
+  case content[0] == '[' || content[0] == '{':
+    cookies, err = parseJsonCookies(content)
+  case isNetscapeCookies(content):
+    cookies = parseNetscapeCookies(content)
+  default:
+    cookies = parseHeaderCookies(string(content))
+  }
+  if err != nil {
+    return
+  }
+  if len(cookies) == 0 {
+    return nil, errors.New("cookies not found")
+  }
+  return
+}
+
+// CookieHeader joins the cookies into a cookie header in a stable order.
+func CookieHeader(cookies map[string]string) string {
+  names := make([]string, 0, len(cookies))
+  for name := range cookies {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+  parts := make([]string, len(names))
+  for i, name := range names {
+    parts[i] = name + "=" + cookies[name]
+  }
+  return strings.Join(parts, "; ")
+}
+
+// TwitterID reads the user id of the twid cookie, which looks like u%3D123.
+func TwitterID(twid string) int64 {
+  if value, err := url.QueryUnescape(strings.Trim(twid, `"`)); err == nil {
+    twid = value
+  }
+  id, _ := strconv.ParseInt(strings.TrimPrefix(twid, "u="), 10, 64)
+  return id
+}
+
+func parseJsonCookies(content []byte) (cookies map[string]string, err error) {
+  var items []*exportCookie
+  if content[0] == '{' {
+    var export struct {
+      Cookies []*exportCookie `json:"cookies"`
+    }
+    if err = json.Unmarshal(content, &export); err == nil && len(export.Cookies) > 0 {
+      items = export.Cookies
+    } else {
+      values := make(map[string]string)
+      if err = json.Unmarshal(content, &values); err != nil {
+        return
+      }
+      return values, nil
+    }
+  } else if err = json.Unmarshal(content, &items); err != nil {
+    return
+  }
+
+  cookies = make(map[string]string)
+  for _, item := range items {
+    if item.Name != "" && isTwitterDomain(item.Domain) {
+      cookies[item.Name] = item.Value
+    }
+  }
+  return
+}
+
+func isNetscapeCookies(content []byte) bool {
+  if bytes.HasPrefix(content, []byte("# Netscape")) || bytes.HasPrefix(content, []byte("# HTTP Cookie File")) {
+    return true
+  }
+  line, _, _ := bytes.Cut(content, []byte("\n"))
+  return bytes.Count(line, []byte("\t")) >= 6
+}
+
+func parseNetscapeCookies(content []byte) map[string]string {
+  cookies := make(map[string]string)
+  scanner := bufio.NewScanner(bytes.NewReader(content))
+  for scanner.Scan() {
+    line := strings.TrimRight(scanner.Text(), "\r")
+    line = strings.TrimPrefix(line, "#HttpOnly_")
+    if line == "" || strings.HasPrefix(line, "#") {
+      continue
+    }
+    fields := strings.Split(line, "\t")
+    if len(fields) < 7 || !isTwitterDomain(fields[0]) {
+      continue
+    }
+    cookies[fields[5]] = fields[6]
+  }
+  return cookies
+}
+
+func parseHeaderCookies(content string) map[string]string {
+  cookies := make(map[string]string)
+  content = strings.TrimPrefix(content, "Cookie:")
+  for _, p := range strings.Split(content, ";") {
+    parts := strings.SplitN(p, "=", 2)
+    if len(parts) == 2 && strings.TrimSpace(parts[0]) != "" {
+      cookies[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+    }
+  }
+  return cookies
+}
+
+func isTwitterDomain(domain string) bool {
+  if domain == "" {
+    return true
+  }
+  domain = strings.TrimPrefix(domain, ".")
+  for _, host := range []string{"twitter.com", "x.com"} {
+    if domain == host || strings.HasSuffix(domain, "."+host) {
+      return true
+    }
+  }
+  return false
+}
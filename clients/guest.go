@@ -0,0 +1,45 @@
+package clients
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "time"
+
+  "github.com/tidwall/gjson"
+)
+
+// ActivateGuest asks guest/activate.json for a guest token, public graphql
+// operations accept it in place of a logged-in session.
+func ActivateGuest(ctx context.Context, proxy string, agent string) (token string, err error) {
+  req, _ := http.NewRequestWithContext(ctx, "POST", LoginBaseUrl()+"/1.1/guest/activate.json", nil)
+  req.Header.Set("User-Agent", agent)
+  req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", BearerToken()))
+  resp, err := NewClient(proxy, time.Duration(15)*time.Second).Do(ctx, req)
+  if err != nil {
+    return
+  }
+  defer resp.Body.Close()
+
+  body, err := io.ReadAll(resp.Body)
+  if err != nil {
+    return
+  }
+  if resp.StatusCode != http.StatusOK {
+    return "", errors.New(
+      fmt.Sprintf(
+        "request error: status[%s] code[%d]",
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+  }
+
+  token = gjson.GetBytes(body, "guest_token").Str
+  if token == "" {
+    err = errors.New("guest token not found")
+  }
+  return
+}
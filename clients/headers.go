@@ -0,0 +1,39 @@
+package clients
+
+import (
+  "fmt"
+  "net/http"
+  "strings"
+
+  "scraper.local/twitter-scraper/models"
+)
+
+// Browse sets the headers of a page request made with the session cookies.
+func Browse(req *http.Request, session *models.Session) {
+  req.Header.Set("User-Agent", session.Agent)
+  req.Header.Set("Cookie", string(session.Cookie))
+}
+
+// Authorize sets the headers of a web api request, the ct0 cookie is
+// echoed back as the csrf token and the gt cookie as the guest token.
+func Authorize(req *http.Request, session *models.Session, accessToken string) {
+  Browse(req, session)
+  req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", accessToken))
+  cookie := string(session.Cookie)
+  if csrf := Cookie(cookie, "ct0"); csrf != "" {
+    req.Header.Set("X-Csrf-Token", csrf)
+  }
+  if guest := Cookie(cookie, "gt"); guest != "" && Cookie(cookie, "auth_token") == "" {
+    req.Header.Set("X-Guest-Token", guest)
+  }
+}
+
+func Cookie(cookie string, name string) string {
+  for _, p := range strings.Split(cookie, ";") {
+    parts := strings.SplitN(p, "=", 2)
+    if len(parts) == 2 && strings.Trim(parts[0], " ") == name {
+      return strings.Trim(parts[1], " ")
+    }
+  }
+  return ""
+}
@@ -0,0 +1,275 @@
+package clients
+
+import (
+  "bytes"
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "net/http/cookiejar"
+  "net/url"
+  "strings"
+  "time"
+
+  "github.com/tidwall/gjson"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+)
+
+type Credentials struct {
+  Username            string
+  Password            string
+  AlternateIdentifier string
+  TotpSecret          string
+}
+
+// LoginFlow walks the onboarding task.json subtasks of the web login, the
+// cookies of the jar become the session once LoginSuccessSubtask shows up.
+type LoginFlow struct {
+  BaseUrl     string
+  Agent       string
+  Credentials *Credentials
+  Client      *http.Client
+  GuestToken  string
+  FlowToken   string
+}
+
+func NewLoginFlow(proxy string, agent string, credentials *Credentials) *LoginFlow {
+  jar, _ := cookiejar.New(nil)
+  return &LoginFlow{
+    BaseUrl:     LoginBaseUrl(),
+    Agent:       agent,
+    Credentials: credentials,
+    Client: &http.Client{
+      Transport: NewClient(proxy, 0).Transport(),
+      Jar:       jar,
+      Timeout:   time.Duration(30) * time.Second,
+    },
+  }
+}
+
+// LoginBaseUrl points the flow at SCRAPER_LOGIN_URL when set, a local
+// stand-in server replays the flow steps this way.
+func LoginBaseUrl() string {
+  if baseUrl := common.GetEnvString("SCRAPER_LOGIN_URL"); baseUrl != "" {
+    return strings.TrimRight(baseUrl, "/")
+  }
+  return config.TWITTER_LOGIN_URL
+}
+
+func BearerToken() string {
+  if token := common.GetEnvString("SCRAPER_BEARER_TOKEN"); token != "" {
+    return token
+  }
+  return config.TWITTER_BEARER_TOKEN
+}
+
+func (f *LoginFlow) Login(ctx context.Context) (cookies map[string]string, err error) {
+  if err = f.Activate(ctx); err != nil {
+    return
+  }
+
+  subtasks, err := f.Task(ctx, "login", map[string]interface{}{
+    "input_flow_data": map[string]interface{}{
+      "flow_context": map[string]interface{}{
+        "debug_overrides": map[string]interface{}{},
+        "start_location": map[string]interface{}{
+          "location": "unknown",
+        },
+      },
+    },
+    "subtask_versions": map[string]interface{}{},
+  })
+  if err != nil {
+    return
+  }
+
+  for step := 0; step < config.LOGIN_FLOW_STEPS_LIMIT; step++ {
+    if len(subtasks) == 0 {
+      return nil, errors.New("login subtasks are empty")
+    }
+
+    subtaskID := subtasks[0].Get("subtask_id").Str
+    if subtaskID == "LoginSuccessSubtask" {
+      return f.Cookies(), nil
+    }
+
+    input, err := f.Input(subtasks[0])
+    if err != nil {
+      return nil, err
+    }
+    subtasks, err = f.Task(ctx, "", map[string]interface{}{
+      "flow_token":     f.FlowToken,
+      "subtask_inputs": []interface{}{input},
+    })
+    if err != nil {
+      return nil, err
+    }
+  }
+
+  return nil, errors.New("login flow steps exceeded")
+}
+
+// Input answers the subtask, challenges we can not answer end the flow.
+func (f *LoginFlow) Input(subtask gjson.Result) (input map[string]interface{}, err error) {
+  subtaskID := subtask.Get("subtask_id").Str
+  input = map[string]interface{}{
+    "subtask_id": subtaskID,
+  }
+
+  switch subtaskID {
+  case "LoginJsInstrumentationSubtask":
+    input["js_instrumentation"] = map[string]interface{}{
+      "response": "{}",
+      "link":     "next_link",
+    }
+  case "LoginEnterUserIdentifierSSO":
+    input["settings_list"] = map[string]interface{}{
+      "setting_responses": []interface{}{
+        map[string]interface{}{
+          "key": "user_identifier",
+          "response_data": map[string]interface{}{
+            "text_data": map[string]interface{}{
+              "result": f.Credentials.Username,
+            },
+          },
+        },
+      },
+      "link": "next_link",
+    }
+  case "LoginEnterAlternateIdentifierSubtask":
+    if f.Credentials.AlternateIdentifier == "" {
+      return nil, errors.New("login alternate identifier is empty")
+    }
+    input["enter_text"] = map[string]interface{}{
+      "text": f.Credentials.AlternateIdentifier,
+      "link": "next_link",
+    }
+  case "LoginEnterPassword":
+    input["enter_password"] = map[string]interface{}{
+      "password": f.Credentials.Password,
+      "link":     "next_link",
+    }
+  case "AccountDuplicationCheck":
+    input["check_logged_in_account"] = map[string]interface{}{
+      "link": "AccountDuplicationCheck_false",
+    }
+  case "LoginTwoFactorAuthChallenge":
+    if f.Credentials.TotpSecret == "" {
+      return nil, errors.New("login totp secret is empty")
+    }
+    code, err := common.GenerateTotp(f.Credentials.TotpSecret, time.Now().Unix())
+    if err != nil {
+      return nil, err
+    }
+    input["enter_text"] = map[string]interface{}{
+      "text": code,
+      "link": "next_link",
+    }
+  case "DenyLoginSubtask":
+    return nil, errors.New(fmt.Sprintf("login denied: %v", f.Message(subtask)))
+  default:
+    return nil, errors.New(fmt.Sprintf("login subtask %v not supported", subtaskID))
+  }
+
+  return
+}
+
+func (f *LoginFlow) Activate(ctx context.Context) (err error) {
+  req, _ := http.NewRequestWithContext(ctx, "POST", f.BaseUrl+"/1.1/guest/activate.json", nil)
+  f.headers(req)
+  body, err := f.do(req)
+  if err != nil {
+    return
+  }
+  f.GuestToken = gjson.GetBytes(body, "guest_token").Str
+  if f.GuestToken == "" {
+    return errors.New("guest token not found")
+  }
+  return
+}
+
+func (f *LoginFlow) Task(ctx context.Context, flowName string, data map[string]interface{}) (subtasks []gjson.Result, err error) {
+  taskUrl := f.BaseUrl + "/1.1/onboarding/task.json"
+  if flowName != "" {
+    taskUrl += "?flow_name=" + url.QueryEscape(flowName)
+  }
+  buf, _ := json.Marshal(data)
+  req, _ := http.NewRequestWithContext(ctx, "POST", taskUrl, bytes.NewReader(buf))
+  f.headers(req)
+  req.Header.Set("Content-Type", "application/json")
+  body, err := f.do(req)
+  if err != nil {
+    return
+  }
+
+  result := gjson.ParseBytes(body)
+  if errs := result.Get("errors"); errs.Exists() && len(errs.Array()) > 0 {
+    return nil, errors.New(fmt.Sprintf("login error: %v", errs.Array()[0].Get("message").Str))
+  }
+  f.FlowToken = result.Get("flow_token").Str
+  if f.FlowToken == "" {
+    return nil, errors.New("flow token not found")
+  }
+  return result.Get("subtasks").Array(), nil
+}
+
+// Cookies returns the cookies the flow collected for the base url.
+func (f *LoginFlow) Cookies() map[string]string {
+  cookies := make(map[string]string)
+  u, _ := url.Parse(f.BaseUrl)
+  for _, cookie := range f.Client.Jar.Cookies(u) {
+    cookies[cookie.Name] = cookie.Value
+  }
+  return cookies
+}
+
+func (f *LoginFlow) Message(subtask gjson.Result) string {
+  for _, path := range []string{"cta.secondary_text.text", "cta.primary_text.text", "enter_text.header.primary_text.text"} {
+    if value := subtask.Get(path).Str; value != "" {
+      return value
+    }
+  }
+  return subtask.Get("subtask_id").Str
+}
+
+func (f *LoginFlow) headers(req *http.Request) {
+  req.Header.Set("User-Agent", f.Agent)
+  req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", BearerToken()))
+  req.Header.Set("X-Twitter-Active-User", "yes")
+  req.Header.Set("X-Twitter-Client-Language", "en")
+  if f.GuestToken != "" {
+    req.Header.Set("X-Guest-Token", f.GuestToken)
+  }
+  if csrf := f.Cookies()["ct0"]; csrf != "" {
+    req.Header.Set("X-Csrf-Token", csrf)
+  }
+}
+
+func (f *LoginFlow) do(req *http.Request) (body []byte, err error) {
+  resp, err := f.Client.Do(req)
+  if err != nil {
+    return
+  }
+  defer resp.Body.Close()
+
+  body, err = io.ReadAll(resp.Body)
+  if err != nil {
+    return
+  }
+  if resp.StatusCode != http.StatusOK {
+    message := gjson.GetBytes(body, "errors.0.message").Str
+    return nil, errors.New(
+      fmt.Sprintf(
+        "request error: status[%s] code[%d] message[%v]",
+        resp.Status,
+        resp.StatusCode,
+        message,
+      ),
+    )
+  }
+  return
+}
@@ -0,0 +1,128 @@
+package clients
+
+import (
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "net/url"
+  "os"
+  "path"
+  "regexp"
+  "sort"
+  "strings"
+  "sync"
+
+  "github.com/tidwall/gjson"
+)
+
+// LoginStub stands in for the login endpoints and replays the exchanges of
+// the directory in file name order, guest/activate.json starts over. The
+// subtask inputs posted are checked against the expect of the step.
+type LoginStub struct {
+  Steps []*Exchange
+  step  int
+  mu    sync.Mutex
+}
+
+func NewLoginStub(dir string) (stub *LoginStub, err error) {
+  entries, err := os.ReadDir(dir)
+  if err != nil {
+    return
+  }
+  names := make([]string, 0, len(entries))
+  for _, entry := range entries {
+    if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+      names = append(names, entry.Name())
+    }
+  }
+  sort.Strings(names)
+
+  stub = &LoginStub{}
+  for _, name := range names {
+    buf, err := os.ReadFile(path.Join(dir, name))
+    if err != nil {
+      return nil, err
+    }
+    var exchange *Exchange
+    if err = json.Unmarshal(buf, &exchange); err != nil {
+      return nil, errors.New(fmt.Sprintf("login step %v: %v", name, err))
+    }
+    stub.Steps = append(stub.Steps, exchange)
+  }
+  if len(stub.Steps) == 0 {
+    return nil, errors.New("login steps are empty")
+  }
+  return
+}
+
+func (s *LoginStub) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+  s.mu.Lock()
+  defer s.mu.Unlock()
+
+  if strings.HasSuffix(req.URL.Path, "/guest/activate.json") {
+    s.step = 0
+  }
+  if s.step >= len(s.Steps) {
+    s.error(w, "login steps exhausted")
+    return
+  }
+
+  exchange := s.Steps[s.step]
+  if u, err := url.Parse(exchange.Url); err == nil && u.Path != req.URL.Path {
+    s.error(w, fmt.Sprintf("login step %v expects %v", s.step, u.Path))
+    return
+  }
+  if err := s.expect(exchange, req); err != nil {
+    s.error(w, fmt.Sprintf("login step %v: %v", s.step, err))
+    return
+  }
+  s.step++
+
+  for key, values := range exchange.Header {
+    switch http.CanonicalHeaderKey(key) {
+    case "Content-Length", "Content-Encoding", "Transfer-Encoding":
+      continue
+    }
+    for _, value := range values {
+      w.Header().Add(key, value)
+    }
+  }
+  w.WriteHeader(exchange.Status)
+  w.Write([]byte(exchange.Body))
+}
+
+func (s *LoginStub) expect(exchange *Exchange, req *http.Request) error {
+  if len(exchange.Expect) == 0 {
+    return nil
+  }
+  var body []byte
+  if req.Body != nil {
+    body, _ = io.ReadAll(req.Body)
+  }
+  for key, pattern := range exchange.Expect {
+    re, err := regexp.Compile(pattern)
+    if err != nil {
+      return err
+    }
+    value := gjson.GetBytes(body, key)
+    if !value.Exists() || !re.MatchString(value.String()) {
+      return errors.New(fmt.Sprintf("%v not match %v", key, pattern))
+    }
+  }
+  return nil
+}
+
+func (s *LoginStub) error(w http.ResponseWriter, message string) {
+  w.Header().Set("Content-Type", "application/json")
+  w.WriteHeader(http.StatusBadRequest)
+  buf, _ := json.Marshal(map[string]interface{}{
+    "errors": []interface{}{
+      map[string]interface{}{
+        "message": message,
+      },
+    },
+  })
+  w.Write(buf)
+}
@@ -0,0 +1,126 @@
+package clients
+
+import (
+  "context"
+  "net/http/httptest"
+  "strings"
+  "testing"
+)
+
+func newStubLoginFlow(t *testing.T, credentials *Credentials) *LoginFlow {
+  stub, err := NewLoginStub("testdata/login")
+  if err != nil {
+    t.Fatal(err)
+  }
+  server := httptest.NewServer(stub)
+  t.Cleanup(server.Close)
+
+  flow := NewLoginFlow("", "stub-agent", credentials)
+  flow.BaseUrl = server.URL
+  return flow
+}
+
+func TestLoginFlowLogin(t *testing.T) {
+  flow := newStubLoginFlow(t, &Credentials{
+    Username:            "stub_user",
+    Password:            "stub password",
+    AlternateIdentifier: "stub@example.com",
+    TotpSecret:          "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+  })
+
+  cookies, err := flow.Login(context.Background())
+  if err != nil {
+    t.Fatal(err)
+  }
+  for name, value := range map[string]string{
+    "auth_token": "stubauthtoken",
+    "ct0":        "stubcsrf",
+  } {
+    if cookies[name] != value {
+      t.Errorf("cookie %v = %q, want %q", name, cookies[name], value)
+    }
+  }
+  if id := TwitterID(cookies["twid"]); id != 1234567890 {
+    t.Errorf("twid = %v, want 1234567890", id)
+  }
+  if flow.GuestToken != "1700000000000000000" {
+    t.Errorf("guest token = %q", flow.GuestToken)
+  }
+}
+
+func TestLoginFlowLoginFailures(t *testing.T) {
+  tests := []struct {
+    name        string
+    credentials *Credentials
+    err         string
+  }{
+    {
+      name: "wrong password",
+      credentials: &Credentials{
+        Username:            "stub_user",
+        Password:            "wrong",
+        AlternateIdentifier: "stub@example.com",
+        TotpSecret:          "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+      },
+      err: "enter_password.password not match",
+    },
+    {
+      name: "wrong alternate identifier",
+      credentials: &Credentials{
+        Username:            "stub_user",
+        Password:            "stub password",
+        AlternateIdentifier: "other@example.com",
+        TotpSecret:          "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+      },
+      err: "enter_text.text not match",
+    },
+    {
+      name: "missing alternate identifier",
+      credentials: &Credentials{
+        Username: "stub_user",
+        Password: "stub password",
+      },
+      err: "login alternate identifier is empty",
+    },
+    {
+      name: "missing totp secret",
+      credentials: &Credentials{
+        Username:            "stub_user",
+        Password:            "stub password",
+        AlternateIdentifier: "stub@example.com",
+      },
+      err: "login totp secret is empty",
+    },
+  }
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      _, err := newStubLoginFlow(t, tt.credentials).Login(context.Background())
+      if err == nil || !strings.Contains(err.Error(), tt.err) {
+        t.Fatalf("err = %v, want %q", err, tt.err)
+      }
+    })
+  }
+}
+
+func TestLoginStubStartsOver(t *testing.T) {
+  credentials := &Credentials{
+    Username:            "stub_user",
+    Password:            "stub password",
+    AlternateIdentifier: "stub@example.com",
+    TotpSecret:          "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+  }
+  stub, err := NewLoginStub("testdata/login")
+  if err != nil {
+    t.Fatal(err)
+  }
+  server := httptest.NewServer(stub)
+  defer server.Close()
+
+  for i := 0; i < 2; i++ {
+    flow := NewLoginFlow("", "stub-agent", credentials)
+    flow.BaseUrl = server.URL
+    if _, err := flow.Login(context.Background()); err != nil {
+      t.Fatalf("login %v: %v", i, err)
+    }
+  }
+}
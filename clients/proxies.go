@@ -0,0 +1,120 @@
+package clients
+
+import (
+  "bufio"
+  "bytes"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "net"
+  "net/url"
+  "strings"
+
+  "scraper.local/twitter-scraper/common"
+)
+
+type exportProxy struct {
+  Host     string      `json:"host"`
+  Ip       string      `json:"ip"`
+  Port     json.Number `json:"port"`
+  Username string      `json:"username"`
+  Password string      `json:"password"`
+  Protocol string      `json:"protocol"`
+}
+
+// ParseProxies reads the proxy list of a provider, one proxy a line as an
+// url, host:port, host:port:user:pass or user:pass@host:port, or a JSON
+// array of urls or objects. Lines without a scheme take the given scheme,
+// lines which can not be read are left out and counted.
+func ParseProxies(content []byte, scheme string) (proxies []string, skipped int, err error) {
+  content = bytes.TrimSpace(bytes.TrimPrefix(content, []byte("\xef\xbb\xbf")))
+  if len(content) == 0 {
+    return nil, 0, errors.New("proxies are empty")
+  }
+  if scheme == "" {
+    scheme = "http"
+  }
+
+  var lines []string
+  if content[0] == '[' {
+    lines, err = parseJsonProxies(content)
+    if err != nil {
+      return
+    }
+  } else {
+    scanner := bufio.NewScanner(bytes.NewReader(content))
+    for scanner.Scan() {
+      lines = append(lines, scanner.Text())
+    }
+  }
+
+  for _, line := range lines {
+    line = strings.TrimSpace(line)
+    if line == "" || strings.HasPrefix(line, "#") {
+      continue
+    }
+    proxy, err := parseProxyLine(line, scheme)
+    if err != nil {
+      skipped++
+      continue
+    }
+    proxies = append(proxies, proxy)
+  }
+  if len(proxies) == 0 {
+    return nil, skipped, errors.New("proxies not found")
+  }
+  return proxies, skipped, nil
+}
+
+func parseJsonProxies(content []byte) (lines []string, err error) {
+  var values []json.RawMessage
+  if err = json.Unmarshal(content, &values); err != nil {
+    return
+  }
+  for _, value := range values {
+    var line string
+    if json.Unmarshal(value, &line) == nil {
+      lines = append(lines, line)
+      continue
+    }
+    var item *exportProxy
+    if json.Unmarshal(value, &item) != nil || item == nil {
+      continue
+    }
+    host := item.Host
+    if host == "" {
+      host = item.Ip
+    }
+    line = net.JoinHostPort(host, item.Port.String())
+    if item.Username != "" {
+      line = url.UserPassword(item.Username, item.Password).String() + "@" + line
+    }
+    if item.Protocol != "" {
+      line = item.Protocol + "://" + line
+    }
+    lines = append(lines, line)
+  }
+  return
+}
+
+func parseProxyLine(line string, scheme string) (string, error) {
+  if !strings.Contains(line, "://") {
+    parts := strings.Split(line, ":")
+    if len(parts) == 4 && !strings.Contains(line, "@") {
+      line = fmt.Sprintf(
+        "%v@%v",
+        url.UserPassword(parts[2], parts[3]).String(),
+        net.JoinHostPort(parts[0], parts[1]),
+      )
+    }
+    line = scheme + "://" + line
+  }
+  u, err := common.ParseProxy(line)
+  if err != nil {
+    return "", err
+  }
+  if u == nil || u.Port() == "" || u.Scheme == "tor" || u.Scheme == "group" {
+    return "", errors.New("proxy not valid")
+  }
+  return u.String(), nil
+}
@@ -0,0 +1,145 @@
+package clients
+
+import (
+  "bytes"
+  "crypto/sha1"
+  "encoding/hex"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "os"
+  "path"
+  "strings"
+
+  "scraper.local/twitter-scraper/common"
+)
+
+var (
+  recordDir string
+  replayDir string
+)
+
+// Exchange is a recorded request and its response, Expect maps the paths of
+// the posted json to the patterns the login stub checks them against.
+type Exchange struct {
+  Method string              `json:"method"`
+  Url    string              `json:"url"`
+  Status int                 `json:"status"`
+  Header map[string][]string `json:"header"`
+  Body   string              `json:"body"`
+  Expect map[string]string   `json:"expect,omitempty"`
+}
+
+// RecordTransport saves the graphql exchanges of the base transport into
+// the directory without the cookies set, other requests pass through
+// untouched.
+type RecordTransport struct {
+  Dir  string
+  Base http.RoundTripper
+}
+
+// ReplayTransport serves the recorded graphql exchanges in place of the
+// network, a request without recording fails.
+type ReplayTransport struct {
+  Dir  string
+  Base http.RoundTripper
+}
+
+// Record turns on recording, an empty dir falls back to SCRAPER_RECORD_DIR.
+func Record(dir string) {
+  recordDir = dir
+}
+
+// Replay turns on replaying, an empty dir falls back to SCRAPER_REPLAY_DIR.
+func Replay(dir string) {
+  replayDir = dir
+}
+
+func RecordDir() string {
+  if recordDir != "" {
+    return recordDir
+  }
+  return common.GetEnvString("SCRAPER_RECORD_DIR")
+}
+
+func ReplayDir() string {
+  if replayDir != "" {
+    return replayDir
+  }
+  return common.GetEnvString("SCRAPER_REPLAY_DIR")
+}
+
+func IsGraphql(req *http.Request) bool {
+  return strings.Contains(req.URL.Path, "/i/api/graphql/")
+}
+
+// ExchangePath keys the exchange by operation and variables, the query id
+// and features change with every client release.
+func ExchangePath(dir string, req *http.Request) string {
+  operation := path.Base(req.URL.Path)
+  hash := sha1.Sum([]byte(req.Method + " " + operation + " " + req.URL.Query().Get("variables")))
+  return path.Join(dir, operation, hex.EncodeToString(hash[:])+".json")
+}
+
+func (t *RecordTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+  resp, err = t.Base.RoundTrip(req)
+  if err != nil || !IsGraphql(req) {
+    return
+  }
+
+  body, err := io.ReadAll(resp.Body)
+  resp.Body.Close()
+  if err != nil {
+    return nil, err
+  }
+  resp.Body = io.NopCloser(bytes.NewReader(body))
+
+  header := resp.Header.Clone()
+  header.Del("Set-Cookie")
+  exchange := &Exchange{
+    Method: req.Method,
+    Url:    req.URL.String(),
+    Status: resp.StatusCode,
+    Header: header,
+    Body:   string(body),
+  }
+  filepath := ExchangePath(t.Dir, req)
+  if err := os.MkdirAll(path.Dir(filepath), os.ModePerm); err != nil {
+    return resp, nil
+  }
+  buf, _ := json.MarshalIndent(exchange, "", "  ")
+  os.WriteFile(filepath, buf, 0644)
+
+  return resp, nil
+}
+
+func (t *ReplayTransport) RoundTrip(req *http.Request) (resp *http.Response, err error) {
+  if !IsGraphql(req) {
+    return t.Base.RoundTrip(req)
+  }
+
+  filepath := ExchangePath(t.Dir, req)
+  buf, err := os.ReadFile(filepath)
+  if err != nil {
+    return nil, errors.New(fmt.Sprintf("replay %v not recorded", path.Base(req.URL.Path)))
+  }
+  var exchange *Exchange
+  if err = json.Unmarshal(buf, &exchange); err != nil {
+    return
+  }
+
+  resp = &http.Response{
+    Status:        fmt.Sprintf("%d %s", exchange.Status, http.StatusText(exchange.Status)),
+    StatusCode:    exchange.Status,
+    Proto:         "HTTP/1.1",
+    ProtoMajor:    1,
+    ProtoMinor:    1,
+    Header:        http.Header(exchange.Header),
+    Body:          io.NopCloser(strings.NewReader(exchange.Body)),
+    ContentLength: int64(len(exchange.Body)),
+    Request:       req,
+  }
+  return
+}
@@ -0,0 +1,36 @@
+package clients
+
+import (
+  "net/http"
+  "sync"
+  "time"
+
+  "scraper.local/twitter-scraper/common"
+)
+
+var (
+  transports   = make(map[string]*http.Transport)
+  transportsMu sync.Mutex
+)
+
+// Transport returns the pooled transport of the proxy, an empty proxy dials
+// directly and the others go through the dialer of the proxy session.
+func Transport(proxy string) *http.Transport {
+  transportsMu.Lock()
+  defer transportsMu.Unlock()
+
+  if tr, ok := transports[proxy]; ok {
+    return tr
+  }
+
+  tr := &http.Transport{
+    MaxIdleConns:        100,
+    MaxIdleConnsPerHost: 10,
+    IdleConnTimeout:     90 * time.Second,
+    TLSHandshakeTimeout: 10 * time.Second,
+    DialContext:         common.NewProxySession(proxy).DialContext,
+  }
+  transports[proxy] = tr
+
+  return tr
+}
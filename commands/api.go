@@ -9,6 +9,7 @@ import (
 
   "github.com/go-chi/chi/v5"
   "github.com/go-redis/redis/v8"
+  "github.com/nats-io/nats.go"
   "github.com/urfave/cli/v2"
   "gorm.io/gorm"
 
@@ -17,9 +18,10 @@ import (
 )
 
 type ApiHandler struct {
-  Db  *gorm.DB
-  Rdb *redis.Client
-  Ctx context.Context
+  Db   *gorm.DB
+  Rdb  *redis.Client
+  Ctx  context.Context
+  Nats *nats.Conn
 }
 
 func NewApiCommand() *cli.Command {
@@ -29,9 +31,10 @@ func NewApiCommand() *cli.Command {
     Usage: "",
     Before: func(c *cli.Context) error {
       h = ApiHandler{
-        Db:  common.NewDB(),
-        Rdb: common.NewRedis(),
-        Ctx: context.Background(),
+        Db:   common.NewDB(),
+        Rdb:  common.NewRedis(),
+        Ctx:  context.Background(),
+        Nats: common.NewNats(),
       }
       return nil
     },
@@ -48,9 +51,10 @@ func (h *ApiHandler) Run() error {
   log.Println("api running...")
 
   apiContext := &common.ApiContext{
-    Db:  h.Db,
-    Rdb: h.Rdb,
-    Ctx: h.Ctx,
+    Db:   h.Db,
+    Rdb:  h.Rdb,
+    Ctx:  h.Ctx,
+    Nats: h.Nats,
   }
 
   r := chi.NewRouter()
@@ -59,6 +63,7 @@ func (h *ApiHandler) Run() error {
     r.Mount("/scrapers", v1.NewScrapersRouter(apiContext))
     r.Mount("/login", v1.NewLoginRouter(apiContext))
     r.Mount("/tasks", v1.NewTasksRouter(apiContext))
+    r.Mount("/sessions", v1.NewSessionsRouter(apiContext))
   })
 
   err := http.ListenAndServe(
@@ -12,6 +12,7 @@ import (
   "gorm.io/gorm"
 
   "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/tasks"
 )
 
@@ -60,6 +61,7 @@ func (h *CronHandler) run() error {
 
   sessions := tasks.NewSessionsTask(ansqContext)
   scrapers := tasks.NewScrapersTask(ansqContext)
+  proxies := tasks.NewProxiesTask(ansqContext)
 
   c := cron.New()
   c.AddFunc("@every 30s", func() {
@@ -67,9 +69,19 @@ func (h *CronHandler) run() error {
     scrapers.Posts().Process(5)
     scrapers.Replies().Flush(30)
     scrapers.Replies().Process(30)
+    scrapers.Search().Flush(5)
+    scrapers.Search().Process(5)
+    scrapers.Follows().Flush(5)
+    scrapers.Follows().Process(5)
+  })
+  c.AddFunc("@every 5m", func() {
+    proxies.Check()
   })
   c.AddFunc("@every 15m", func() {
     sessions.Flush()
+    sessions.Relogin()
+    sessions.Probe()
+    scrapers.Posts().Verify(config.SCRAPERS_POSTS_VERIFY_LIMIT)
   })
   c.AddFunc("30 23 * * * *", func() {
     scrapers.Replies().Init(1000)
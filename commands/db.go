@@ -51,7 +51,22 @@ func (h *DbHandler) migrate() error {
     &models.Task{},
     &models.Session{},
     &models.Admin{},
+    &models.SearchPost{},
+    &models.Follow{},
+    &models.Metric{},
+    &models.TweetEntity{},
+    &models.PostRevision{},
+    &models.Archive{},
+    &models.Credential{},
+    &models.SessionEvent{},
+    &models.Proxy{},
+    &models.ProxyScore{},
   )
+  if h.Db.Migrator().HasColumn("twitter_sessions", "slot") {
+    log.Println("process migrator: session slots to tor proxies")
+    h.Db.Exec("UPDATE twitter_sessions SET proxy = 'tor://' || slot WHERE slot > 0 AND proxy = ''")
+    h.Db.Migrator().DropColumn("twitter_sessions", "slot")
+  }
   models.NewMedia().AutoMigrate(h.Db)
   models.NewPlatform().AutoMigrate(h.Db)
   models.NewTor().AutoMigrate(h.TorDb)
@@ -0,0 +1,126 @@
+package commands
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "sort"
+  "strings"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/urfave/cli/v2"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type OperationsHandler struct {
+  Rdb        *redis.Client
+  Ctx        context.Context
+  Repository *repositories.OperationsRepository
+}
+
+func NewOperationsCommand() *cli.Command {
+  var h OperationsHandler
+  return &cli.Command{
+    Name:  "operations",
+    Usage: "graphql operations discovered in the client bundle",
+    Before: func(c *cli.Context) error {
+      h = OperationsHandler{
+        Rdb: common.NewRedis(),
+        Ctx: context.Background(),
+      }
+      h.Repository = &repositories.OperationsRepository{
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
+      }
+      return nil
+    },
+    Subcommands: []*cli.Command{
+      {
+        Name:  "list",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          h.List()
+          return nil
+        },
+      },
+      {
+        Name:  "diff",
+        Usage: "compare with the previous refresh",
+        Action: func(c *cli.Context) error {
+          h.Diff()
+          return nil
+        },
+      },
+    },
+  }
+}
+
+func (h *OperationsHandler) List() {
+  operations := h.Repository.Listings()
+  names := make([]string, 0, len(operations))
+  for name := range operations {
+    names = append(names, name)
+  }
+  sort.Strings(names)
+  for _, name := range names {
+    operation := operations[name]
+    log.Println(fmt.Sprintf(
+      "%v %v %v features[%v]",
+      operation.OperationType,
+      operation.OperationName,
+      operation.QueryID,
+      len(operation.FeatureSwitches),
+    ))
+  }
+  log.Println(fmt.Sprintf("operations total[%v]", len(operations)))
+}
+
+func (h *OperationsHandler) Diff() {
+  diffs := h.Repository.Diff()
+  for _, diff := range diffs {
+    switch diff.Action {
+    case "added":
+      log.Println(fmt.Sprintf("+ %v %v", diff.OperationName, diff.Current.QueryID))
+    case "removed":
+      log.Println(fmt.Sprintf("- %v %v", diff.OperationName, diff.Previous.QueryID))
+    case "changed":
+      log.Println(fmt.Sprintf("~ %v %v => %v", diff.OperationName, diff.Previous.QueryID, diff.Current.QueryID))
+      added, removed := h.diffNames(diff.Previous.FeatureSwitches, diff.Current.FeatureSwitches)
+      if len(added) > 0 {
+        log.Println(fmt.Sprintf("    features + %v", strings.Join(added, ", ")))
+      }
+      if len(removed) > 0 {
+        log.Println(fmt.Sprintf("    features - %v", strings.Join(removed, ", ")))
+      }
+      added, removed = h.diffNames(diff.Previous.FieldToggles, diff.Current.FieldToggles)
+      if len(added) > 0 {
+        log.Println(fmt.Sprintf("    toggles + %v", strings.Join(added, ", ")))
+      }
+      if len(removed) > 0 {
+        log.Println(fmt.Sprintf("    toggles - %v", strings.Join(removed, ", ")))
+      }
+    }
+  }
+  log.Println(fmt.Sprintf("operations changes[%v]", len(diffs)))
+}
+
+func (h *OperationsHandler) diffNames(previous []string, current []string) (added []string, removed []string) {
+  names := make(map[string]bool)
+  for _, name := range previous {
+    names[name] = true
+  }
+  for _, name := range current {
+    if !names[name] {
+      added = append(added, name)
+    }
+    delete(names, name)
+  }
+  for _, name := range previous {
+    if names[name] {
+      removed = append(removed, name)
+    }
+  }
+  return
+}
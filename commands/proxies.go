@@ -0,0 +1,301 @@
+package commands
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "io"
+  "log"
+  "net/http"
+  "os"
+  "time"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/urfave/cli/v2"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type ProxiesHandler struct {
+  Db         *gorm.DB
+  Rdb        *redis.Client
+  Ctx        context.Context
+  Repository *repositories.ProxiesRepository
+}
+
+func NewProxiesCommand() *cli.Command {
+  var h ProxiesHandler
+  return &cli.Command{
+    Name:  "proxies",
+    Usage: "proxy groups the sessions are routed through with group://<name>",
+    Before: func(c *cli.Context) error {
+      h = ProxiesHandler{
+        Db:  common.NewDB(),
+        Rdb: common.NewRedis(),
+        Ctx: context.Background(),
+      }
+      h.Repository = &repositories.ProxiesRepository{
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
+      }
+      return nil
+    },
+    Subcommands: []*cli.Command{
+      {
+        Name:  "list",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          h.List(c.Args().Get(0))
+          return nil
+        },
+      },
+      {
+        Name:  "add",
+        Usage: "add a proxy url or tor://<slot> to the group",
+        Action: func(c *cli.Context) error {
+          group := c.Args().Get(0)
+          if group == "" {
+            log.Fatal("proxy group can not be empty")
+            return nil
+          }
+          proxy := c.Args().Get(1)
+          if proxy == "" {
+            log.Fatal("proxy can not be empty")
+            return nil
+          }
+          if err := h.Add(group, proxy); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "import",
+        Usage: "import the proxy list of a file, stdin or --url into the group",
+        Flags: []cli.Flag{
+          &cli.StringFlag{
+            Name:  "url",
+            Usage: "api the proxy list is requested from",
+          },
+          &cli.StringFlag{
+            Name:  "scheme",
+            Value: "http",
+            Usage: "scheme of the proxies listed without one",
+          },
+        },
+        Action: func(c *cli.Context) error {
+          group := c.Args().Get(0)
+          if group == "" {
+            log.Fatal("proxy group can not be empty")
+            return nil
+          }
+          if err := h.Import(group, c.Args().Get(1), c.String("url"), c.String("scheme")); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "check",
+        Usage: "run the health check of the proxy, or of the whole pool",
+        Action: func(c *cli.Context) error {
+          if err := h.Check(c.Args().Get(0)); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "scores",
+        Usage: "show the score history of the proxy",
+        Action: func(c *cli.Context) error {
+          id := c.Args().Get(0)
+          if id == "" {
+            log.Fatal("proxy id can not be empty")
+            return nil
+          }
+          if err := h.Scores(id); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "release",
+        Usage: "take the proxy out of quarantine",
+        Action: func(c *cli.Context) error {
+          id := c.Args().Get(0)
+          if id == "" {
+            log.Fatal("proxy id can not be empty")
+            return nil
+          }
+          if err := h.Release(id); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "remove",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          id := c.Args().Get(0)
+          if id == "" {
+            log.Fatal("proxy id can not be empty")
+            return nil
+          }
+          if err := h.Remove(id); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "resolve",
+        Usage: "show the proxy a reference resolves to for the key",
+        Action: func(c *cli.Context) error {
+          if err := h.Resolve(c.Args().Get(0), c.Args().Get(1)); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+    },
+  }
+}
+
+func (h *ProxiesHandler) List(group string) {
+  for _, proxy := range h.Repository.Listings(group) {
+    fmt.Println(
+      proxy.ID,
+      proxy.GroupName,
+      proxy.Status,
+      common.ProxyName(string(proxy.Url)),
+      fmt.Sprintf("score:%.1f", proxy.Score),
+      fmt.Sprintf("latency:%dms", proxy.Latency),
+      fmt.Sprintf("exit:%v", proxy.ExitIp),
+      fmt.Sprintf("quarantined_until:%d", proxy.QuarantinedUntil),
+    )
+  }
+}
+
+func (h *ProxiesHandler) Add(group string, proxy string) error {
+  entity, err := h.Repository.Create(group, proxy)
+  if err != nil {
+    return err
+  }
+  log.Println("proxies added", entity.ID, entity.GroupName, common.ProxyName(string(entity.Url)))
+  return nil
+}
+
+// Import reads the proxy list of the api, the file or stdin with "-" and
+// adds the proxies the group does not have yet.
+func (h *ProxiesHandler) Import(group string, path string, api string, scheme string) error {
+  var content []byte
+  var err error
+  switch {
+  case api != "":
+    content, err = h.fetch(api)
+  case path == "" || path == "-":
+    content, err = io.ReadAll(os.Stdin)
+  default:
+    content, err = os.ReadFile(path)
+  }
+  if err != nil {
+    return err
+  }
+
+  proxies, skipped, err := clients.ParseProxies(content, scheme)
+  if err != nil {
+    return err
+  }
+  count, err := h.Repository.Import(group, proxies)
+  if err != nil {
+    return err
+  }
+  log.Println("proxies imported", group, count, "skipped", skipped)
+  return nil
+}
+
+func (h *ProxiesHandler) Check(id string) error {
+  proxies := h.Repository.Checkables()
+  if id != "" {
+    entity, err := h.Repository.Find(id)
+    if err != nil {
+      return err
+    }
+    proxies = []*models.Proxy{entity}
+  }
+  for _, proxy := range proxies {
+    if err := h.Repository.Check(h.Ctx, proxy); err != nil {
+      log.Println("proxies check failed", proxy.ID, proxy.GroupName, err)
+      continue
+    }
+    log.Println("proxies check passed", proxy.ID, proxy.GroupName, proxy.ExitIp)
+  }
+  return nil
+}
+
+func (h *ProxiesHandler) Scores(id string) error {
+  entity, err := h.Repository.Find(id)
+  if err != nil {
+    return err
+  }
+  for _, score := range h.Repository.Scores(entity.ID, config.PROXIES_SCORES_LIMIT) {
+    fmt.Println(
+      time.UnixMicro(score.Timestamp).Format(time.RFC3339),
+      score.Success,
+      fmt.Sprintf("%.1f", score.Score),
+      fmt.Sprintf("%dms", score.Latency),
+      score.ExitIp,
+      score.Reason,
+    )
+  }
+  return nil
+}
+
+func (h *ProxiesHandler) Release(id string) error {
+  entity, err := h.Repository.Find(id)
+  if err != nil {
+    return err
+  }
+  return h.Repository.Release(entity)
+}
+
+func (h *ProxiesHandler) fetch(api string) ([]byte, error) {
+  client := &http.Client{
+    Timeout: time.Duration(config.PROXIES_CHECK_TIMEOUT) * time.Second,
+  }
+  resp, err := client.Get(api)
+  if err != nil {
+    return nil, err
+  }
+  defer resp.Body.Close()
+  if resp.StatusCode != http.StatusOK {
+    return nil, errors.New(fmt.Sprintf("proxies request failed: %v", resp.Status))
+  }
+  return io.ReadAll(io.LimitReader(resp.Body, 1<<24))
+}
+
+func (h *ProxiesHandler) Remove(id string) error {
+  entity, err := h.Repository.Find(id)
+  if err != nil {
+    return err
+  }
+  return h.Repository.Delete(entity)
+}
+
+func (h *ProxiesHandler) Resolve(reference string, key string) error {
+  proxy, err := h.Repository.Proxy(reference, key)
+  if err != nil {
+    return err
+  }
+  fmt.Println(common.ProxyName(proxy))
+  return nil
+}
@@ -7,8 +7,10 @@ import (
 
 func NewQueueCommand() *cli.Command {
   return &cli.Command{
-    Name:  "queue",
-    Usage: "",
+    Name:   "queue",
+    Usage:  "",
+    Flags:  NewReplayFlags(),
+    Before: ReplayBefore,
     Subcommands: []*cli.Command{
       queue.NewAsynqCommand(),
       queue.NewNatsCommand(),
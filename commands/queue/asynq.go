@@ -57,6 +57,7 @@ func (h *AsynqHandler) run() error {
 
   workers.NewScrapers(ansqContext).Register()
   workers.NewSessions(ansqContext).Register()
+  workers.NewProxies(ansqContext).Register()
 
   if err := worker.Run(mux); err != nil {
     return err
@@ -0,0 +1,29 @@
+package commands
+
+import (
+  "github.com/urfave/cli/v2"
+
+  "scraper.local/twitter-scraper/clients"
+)
+
+// NewReplayFlags lets the scraper commands record graphql exchanges or run
+// against recorded ones, SCRAPER_RECORD_DIR and SCRAPER_REPLAY_DIR are the
+// fallbacks.
+func NewReplayFlags() []cli.Flag {
+  return []cli.Flag{
+    &cli.StringFlag{
+      Name:  "record-dir",
+      Usage: "save graphql request/response pairs into the dir",
+    },
+    &cli.StringFlag{
+      Name:  "replay-dir",
+      Usage: "serve graphql request/response pairs from the dir",
+    },
+  }
+}
+
+func ReplayBefore(c *cli.Context) error {
+  clients.Record(c.String("record-dir"))
+  clients.Replay(c.String("replay-dir"))
+  return nil
+}
@@ -7,13 +7,17 @@ import (
 
 func NewScrapersCommand() *cli.Command {
   return &cli.Command{
-    Name:  "scrapers",
-    Usage: "",
+    Name:   "scrapers",
+    Usage:  "",
+    Flags:  NewReplayFlags(),
+    Before: ReplayBefore,
     Subcommands: []*cli.Command{
       scrapers.NewPostsCommand(),
       scrapers.NewRepliesCommand(),
       scrapers.NewMediaCommand(),
       scrapers.NewUsersCommand(),
+      scrapers.NewReparseCommand(),
+      scrapers.NewDriftCommand(),
     },
   }
 }
@@ -0,0 +1,80 @@
+package scrapers
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "sort"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/urfave/cli/v2"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type DriftHandler struct {
+  Rdb        *redis.Client
+  Ctx        context.Context
+  Repository *repositories.DriftRepository
+}
+
+func NewDriftCommand() *cli.Command {
+  var h DriftHandler
+  return &cli.Command{
+    Name:  "drift",
+    Usage: "response drift metrics of the scrapers",
+    Before: func(c *cli.Context) error {
+      h = DriftHandler{
+        Rdb: common.NewRedis(),
+        Ctx: context.Background(),
+      }
+      h.Repository = &repositories.DriftRepository{
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
+      }
+      return nil
+    },
+    Action: func(c *cli.Context) error {
+      h.Stats()
+      return nil
+    },
+    Subcommands: []*cli.Command{
+      {
+        Name:  "reset",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          endpoint := c.Args().Get(0)
+          if endpoint == "" {
+            log.Fatal("endpoint can not be empty")
+            return nil
+          }
+          if err := h.Repository.Reset(endpoint); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+    },
+  }
+}
+
+func (h *DriftHandler) Stats() {
+  stats := h.Repository.Stats()
+  endpoints := make([]string, 0, len(stats))
+  for endpoint := range stats {
+    endpoints = append(endpoints, endpoint)
+  }
+  sort.Strings(endpoints)
+  for _, endpoint := range endpoints {
+    fields := make([]string, 0, len(stats[endpoint]))
+    for field := range stats[endpoint] {
+      fields = append(fields, field)
+    }
+    sort.Strings(fields)
+    log.Println(fmt.Sprintf("scrapers drift[%v]", endpoint))
+    for _, field := range fields {
+      log.Println(fmt.Sprintf("  %v: %v", field, stats[endpoint][field]))
+    }
+  }
+}
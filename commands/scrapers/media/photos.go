@@ -81,7 +81,7 @@ func (h *PhotosHandler) Download(url string) (err error) {
     log.Println(fmt.Sprintf("photo %v exists", url))
     return
   }
-  return h.Repository.Download(url, urlSha1)
+  return h.Repository.Download(h.Ctx, url, urlSha1)
 }
 
 func (h *PhotosHandler) Fix(limit int) (err error) {
@@ -92,7 +92,7 @@ func (h *PhotosHandler) Fix(limit int) (err error) {
   photos := h.PhotosRepository.Listings(conditions, 1, limit)
   log.Println("photos", len(photos))
   for _, photo := range photos {
-    config, err := h.Repository.Config(photo.Url)
+    config, err := h.Repository.Config(h.Ctx, photo.Url)
     if err == nil {
       h.PhotosRepository.Updates(photo, map[string]interface{}{
         "width":  config.Width,
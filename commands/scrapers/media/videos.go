@@ -65,5 +65,5 @@ func (h *VideosHandler) Download(url string) (err error) {
     log.Println(fmt.Sprintf("video %v exists", url))
     return
   }
-  return h.Repository.Download(url, urlSha1)
+  return h.Repository.Download(h.Ctx, url, urlSha1)
 }
@@ -11,6 +11,7 @@ import (
   "log"
 
   "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/repositories"
   scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
 )
@@ -23,6 +24,7 @@ type PostsHandler struct {
   Repository         *scrapersRepositories.PostsRepository
   SessionsRepository *repositories.SessionsRepository
   UsersRepository    *repositories.UsersRepository
+  TasksRepository    *repositories.TasksRepository
 }
 
 func NewPostsCommand() *cli.Command {
@@ -41,12 +43,26 @@ func NewPostsCommand() *cli.Command {
         Db: h.Db,
       }
       h.SessionsRepository = &repositories.SessionsRepository{
-        Db: h.Db,
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
       }
       h.UsersRepository = &repositories.UsersRepository{
         Db:   h.Db,
         Nats: h.Nats,
       }
+      h.TasksRepository = &repositories.TasksRepository{
+        Db: h.Db,
+      }
+      h.Repository.SessionsRepository = h.SessionsRepository
+      h.Repository.ArchivesRepository = &repositories.ArchivesRepository{
+        Db: h.Db,
+      }
+      h.Repository.DriftRepository = &repositories.DriftRepository{
+        Rdb:  h.Rdb,
+        Ctx:  h.Ctx,
+        Nats: h.Nats,
+      }
       h.Repository.UsersRepository = h.UsersRepository
       h.Repository.PostsRepository = &repositories.PostsRepository{
         Db:   h.Db,
@@ -54,6 +70,24 @@ func NewPostsCommand() *cli.Command {
       }
       return nil
     },
+    Subcommands: []*cli.Command{
+      {
+        Name:  "get",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          url := c.Args().Get(0)
+          if url == "" {
+            log.Fatal("status url is empty")
+            return nil
+          }
+          withReplies := c.Args().Get(1) == "replies"
+          if err := h.Get(url, withReplies); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+    },
     Action: func(c *cli.Context) error {
       account := c.Args().Get(0)
       if account == "" {
@@ -78,7 +112,7 @@ func (h *PostsHandler) Process(account string, cursor string) (err error) {
   params := map[string]interface{}{
     "user_id": user.ID,
   }
-  session := h.SessionsRepository.Special()
+  session := h.SessionsRepository.Special("UserTweets")
   if session == nil {
     return errors.New("special session is empty")
   }
@@ -87,7 +121,32 @@ func (h *PostsHandler) Process(account string, cursor string) (err error) {
       session.Account: cursor,
     }
   }
-  cursor, count, err := h.Repository.Process(session, user, params)
+  cursor, count, err := h.Repository.Process(h.Ctx, session, user, params)
   log.Println("posts scraper cursor", cursor, count)
   return
 }
+
+func (h *PostsHandler) Get(url string, withReplies bool) (err error) {
+  log.Println(fmt.Sprintf("post %v scrapping...", url))
+  twitterID, err := scrapersRepositories.ParseStatusUrl(url)
+  if err != nil {
+    return
+  }
+  session := h.SessionsRepository.Special("TweetResultByRestId")
+  if session == nil {
+    return errors.New("special session is empty")
+  }
+  post, err := h.Repository.Get(h.Ctx, session, twitterID)
+  if err != nil {
+    return
+  }
+  log.Println("post", post.ID, post.TwitterID, post.Status)
+  if withReplies {
+    name := fmt.Sprintf("%v@replies", post.ID)
+    params := map[string]interface{}{
+      "post_id": post.ID,
+    }
+    err = h.TasksRepository.Apply(name, config.TASK_ACTION_SCRAPERS_REPLIES, params)
+  }
+  return
+}
@@ -0,0 +1,121 @@
+package scrapers
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "log"
+  "time"
+
+  "github.com/nats-io/nats.go"
+  "github.com/urfave/cli/v2"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/repositories"
+  scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
+)
+
+type ReparseHandler struct {
+  Db                 *gorm.DB
+  Ctx                context.Context
+  Nats               *nats.Conn
+  Repository         *scrapersRepositories.ArchivesRepository
+  ArchivesRepository *repositories.ArchivesRepository
+}
+
+func NewReparseCommand() *cli.Command {
+  var h ReparseHandler
+  return &cli.Command{
+    Name:  "reparse",
+    Usage: "run the current parsers over archived pages",
+    Flags: []cli.Flag{
+      &cli.StringFlag{
+        Name:  "since",
+        Usage: "archived since date, 2006-01-02 or RFC3339",
+      },
+      &cli.StringFlag{
+        Name:     "endpoint",
+        Usage:    "UserTweets, UserMedia, TweetDetail, SearchTimeline, Followers or Following",
+        Required: true,
+      },
+    },
+    Before: func(c *cli.Context) error {
+      h = ReparseHandler{
+        Db:   common.NewDB(),
+        Ctx:  context.Background(),
+        Nats: common.NewNats(),
+      }
+      h.ArchivesRepository = &repositories.ArchivesRepository{
+        Db: h.Db,
+      }
+      h.Repository = &scrapersRepositories.ArchivesRepository{
+        Db:                 h.Db,
+        ArchivesRepository: h.ArchivesRepository,
+      }
+      h.Repository.TasksRepository = &repositories.TasksRepository{
+        Db: h.Db,
+      }
+      h.Repository.UsersRepository = &repositories.UsersRepository{
+        Db:   h.Db,
+        Nats: h.Nats,
+      }
+      h.Repository.PostsRepository = &repositories.PostsRepository{
+        Db:   h.Db,
+        Nats: h.Nats,
+      }
+      h.Repository.RepliesRepository = &repositories.RepliesRepository{
+        Db:   h.Db,
+        Nats: h.Nats,
+      }
+      h.Repository.SearchRepository = &repositories.SearchRepository{
+        Db: h.Db,
+      }
+      return nil
+    },
+    Action: func(c *cli.Context) error {
+      var since int64
+      if c.String("since") != "" {
+        t, err := time.Parse("2006-01-02", c.String("since"))
+        if err != nil {
+          t, err = time.Parse(time.RFC3339, c.String("since"))
+        }
+        if err != nil {
+          return cli.Exit(errors.New("since is not valid"), 1)
+        }
+        since = t.UnixMilli()
+      }
+      if err := h.Reparse(c.String("endpoint"), since); err != nil {
+        return cli.Exit(err.Error(), 1)
+      }
+      return nil
+    },
+  }
+}
+
+func (h *ReparseHandler) Reparse(endpoint string, since int64) error {
+  log.Println(fmt.Sprintf("scrapers %v reparse since %v...", endpoint, since))
+
+  var lastID string
+  var pages, count int
+  for {
+    archives := h.ArchivesRepository.Listings(endpoint, since, lastID, 100)
+    if len(archives) == 0 {
+      break
+    }
+    for _, archive := range archives {
+      n, err := h.Repository.Reparse(archive)
+      if err != nil {
+        log.Println("archive reparse error", err)
+        continue
+      }
+      pages++
+      count += n
+    }
+    lastID = archives[len(archives)-1].ID
+  }
+
+  log.Println("scrapers reparse result", endpoint, pages, count)
+
+  return nil
+}
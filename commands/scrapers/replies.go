@@ -45,8 +45,19 @@ func NewRepliesCommand() *cli.Command {
         Db: h.Db,
       }
       h.SessionsRepository = &repositories.SessionsRepository{
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
+      }
+      h.Repository.SessionsRepository = h.SessionsRepository
+      h.Repository.ArchivesRepository = &repositories.ArchivesRepository{
         Db: h.Db,
       }
+      h.Repository.DriftRepository = &repositories.DriftRepository{
+        Rdb:  h.Rdb,
+        Ctx:  h.Ctx,
+        Nats: h.Nats,
+      }
       h.Repository.UsersRepository = &repositories.UsersRepository{
         Db:   h.Db,
         Nats: h.Nats,
@@ -78,11 +89,11 @@ func (h *RepliesHandler) Process(postID string) (err error) {
     return err
   }
   params := map[string]interface{}{}
-  session := h.SessionsRepository.Current()
+  session := h.SessionsRepository.Current("TweetDetail")
   if session == nil {
     return errors.New("current session is empty")
   }
-  cursor, count, err := h.Repository.Process(session, post, params)
+  cursor, count, err := h.Repository.Process(h.Ctx, session, post, params)
   log.Println("replies scraper cursor", cursor, count)
   return
 }
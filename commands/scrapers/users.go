@@ -45,8 +45,11 @@ func NewUsersCommand() *cli.Command {
         Db: h.Db,
       }
       h.SessionsRepository = &repositories.SessionsRepository{
-        Db: h.Db,
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
       }
+      h.Repository.SessionsRepository = h.SessionsRepository
       h.Repository.UsersRepository = &repositories.UsersRepository{
         Db:   h.Db,
         Nats: h.Nats,
@@ -70,11 +73,11 @@ func NewUsersCommand() *cli.Command {
 func (h *UsersHandler) Process(account string) (err error) {
   log.Println(fmt.Sprintf("account[%v] users scraper processing...", account))
 
-  session := h.SessionsRepository.Special()
+  session := h.SessionsRepository.Special("UserByScreenName")
   if session == nil {
     return errors.New("current session is empty")
   }
-  user, err := h.Repository.Process(session, account)
+  user, err := h.Repository.Process(h.Ctx, session, account)
   log.Println("user", user)
   return
 }
@@ -1,6 +1,7 @@
 package commands
 
 import (
+  "bufio"
   "context"
   "errors"
   "fmt"
@@ -8,11 +9,17 @@ import (
   "github.com/nats-io/nats.go"
   "github.com/urfave/cli/v2"
   "gorm.io/gorm"
+  "io"
   "log"
-  "strconv"
+  "net/http"
+  "os"
+  "path/filepath"
+  "strings"
   "time"
 
+  "scraper.local/twitter-scraper/clients"
   "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
   "scraper.local/twitter-scraper/repositories"
 )
 
@@ -37,6 +44,7 @@ func NewSessionsCommand() *cli.Command {
       }
       h.Repository = &repositories.SessionsRepository{
         Db:  h.Db,
+        Rdb: h.Rdb,
         Ctx: h.Ctx,
       }
       return nil
@@ -56,8 +64,153 @@ func NewSessionsCommand() *cli.Command {
             log.Fatal("twitter cookie can not be empty")
             return nil
           }
-          slot, _ := strconv.Atoi(c.Args().Get(2))
-          if err := h.Apply(account, cookie, slot); err != nil {
+          if err := h.Apply(account, cookie, c.Args().Get(2)); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "import",
+        Usage: "import cookies.txt or json exports from a file, a directory or stdin",
+        Flags: []cli.Flag{
+          &cli.StringFlag{
+            Name:  "account",
+            Usage: "twitter account, resolved from the twid cookie by default",
+          },
+          &cli.StringFlag{
+            Name:  "proxy",
+            Usage: "proxy of the sessions, a proxy url, tor://<slot> or group://<name>",
+          },
+        },
+        Action: func(c *cli.Context) error {
+          if err := h.Import(c.Args().Get(0), c.String("account"), c.String("proxy")); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "login",
+        Usage: "store the credentials of the account and log in, the password is read from stdin",
+        Flags: []cli.Flag{
+          &cli.StringFlag{
+            Name:  "alternate",
+            Usage: "email or phone asked by the alternate identifier challenge",
+          },
+          &cli.StringFlag{
+            Name:  "totp-secret",
+            Usage: "base32 secret of the two factor authentication",
+          },
+          &cli.StringFlag{
+            Name:  "proxy",
+            Usage: "proxy of the session, a proxy url, tor://<slot> or group://<name>",
+          },
+        },
+        Action: func(c *cli.Context) error {
+          account := c.Args().Get(0)
+          if account == "" {
+            log.Fatal("twitter account can not be empty")
+            return nil
+          }
+          if err := h.Login(account, c.String("alternate"), c.String("totp-secret"), c.String("proxy")); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "proxy",
+        Usage: "route the session through the proxy, an empty proxy dials directly",
+        Action: func(c *cli.Context) error {
+          account := c.Args().Get(0)
+          if account == "" {
+            log.Fatal("twitter account can not be empty")
+            return nil
+          }
+          if err := h.Proxy(account, c.Args().Get(1)); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "relogin",
+        Usage: "log the dead sessions in again with their credentials",
+        Action: func(c *cli.Context) error {
+          if err := h.Relogin(); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "login-stub",
+        Usage: "serve recorded login steps for SCRAPER_LOGIN_URL",
+        Flags: []cli.Flag{
+          &cli.IntFlag{
+            Name:  "port",
+            Value: 8091,
+          },
+        },
+        Action: func(c *cli.Context) error {
+          dir := c.Args().Get(0)
+          if dir == "" {
+            log.Fatal("login steps dir can not be empty")
+            return nil
+          }
+          if err := h.LoginStub(dir, c.Int("port")); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "rotate-key",
+        Usage: "seal cookies and secrets again with SCRAPER_SECRET_KEY",
+        Action: func(c *cli.Context) error {
+          if err := h.RotateKey(); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "probe",
+        Usage: "run the health probe with the session of the account, or every logged-in session",
+        Action: func(c *cli.Context) error {
+          if err := h.Probe(c.Args().Get(0)); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "health",
+        Usage: "show the success rate and the last failure of the sessions",
+        Action: func(c *cli.Context) error {
+          if err := h.Health(c.Args().Get(0)); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "events",
+        Usage: "show the recent events of the session",
+        Flags: []cli.Flag{
+          &cli.IntFlag{
+            Name:  "limit",
+            Value: 20,
+          },
+        },
+        Action: func(c *cli.Context) error {
+          account := c.Args().Get(0)
+          if account == "" {
+            log.Fatal("twitter account can not be empty")
+            return nil
+          }
+          if err := h.Events(account, c.Int("limit")); err != nil {
             return cli.Exit(err.Error(), 1)
           }
           return nil
@@ -92,19 +245,159 @@ func NewSessionsCommand() *cli.Command {
   }
 }
 
-func (h *SessionsHandler) Apply(account string, cookie string, slot int) (err error) {
+func (h *SessionsHandler) Apply(account string, cookie string, proxy string) (err error) {
   log.Println(fmt.Sprintf("twitters sessions apply..."))
-  session, err := h.Repository.Apply(account, cookie, slot)
+  session, err := h.Repository.Apply(account, cookie, proxy)
   if err == nil {
-    h.Repository.Flush(session)
+    h.Repository.Flush(h.Ctx, session)
   }
   return
 }
 
+func (h *SessionsHandler) Import(path string, account string, proxy string) (err error) {
+  log.Println(fmt.Sprintf("twitters sessions import..."))
+  if path == "" || path == "-" {
+    content, err := io.ReadAll(os.Stdin)
+    if err != nil {
+      return err
+    }
+    return h.ImportContent(content, account, proxy)
+  }
+
+  info, err := os.Stat(path)
+  if err != nil {
+    return
+  }
+  if !info.IsDir() {
+    content, err := os.ReadFile(path)
+    if err != nil {
+      return err
+    }
+    return h.ImportContent(content, account, proxy)
+  }
+
+  if account != "" {
+    return errors.New("account can not be set for a directory")
+  }
+  entries, err := os.ReadDir(path)
+  if err != nil {
+    return
+  }
+  for _, entry := range entries {
+    if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+      continue
+    }
+    content, err := os.ReadFile(filepath.Join(path, entry.Name()))
+    if err == nil {
+      err = h.ImportContent(content, "", proxy)
+    }
+    if err != nil {
+      log.Println("sessions import failed", entry.Name(), err)
+    }
+  }
+  return nil
+}
+
+func (h *SessionsHandler) ImportContent(content []byte, account string, proxy string) error {
+  cookies, err := clients.ParseCookies(content)
+  if err != nil {
+    return err
+  }
+  session, err := h.Repository.Import(h.Ctx, account, cookies, proxy)
+  if err != nil {
+    return err
+  }
+  log.Println("sessions imported", session.ID, session.Account)
+  return h.Repository.Flush(h.Ctx, session)
+}
+
+func (h *SessionsHandler) Login(account string, alternate string, totpSecret string, proxy string) (err error) {
+  log.Println(fmt.Sprintf("twitters sessions login..."))
+  password := common.GetEnvString("SCRAPER_LOGIN_PASSWORD")
+  if password == "" {
+    line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+    password = strings.TrimRight(line, "\r\n")
+  }
+  if password == "" {
+    return errors.New("twitter password can not be empty")
+  }
+  credential, err := h.Repository.Credentials().Apply(account, password, alternate, totpSecret)
+  if err != nil {
+    return
+  }
+  session, err := h.Repository.Login(h.Ctx, credential, proxy)
+  if err != nil {
+    return
+  }
+  log.Println("sessions logged in", session.ID, session.Account)
+  return
+}
+
+func (h *SessionsHandler) Proxy(account string, proxy string) error {
+  session, err := h.Repository.Get(account)
+  if err != nil {
+    return err
+  }
+  u, err := common.ParseProxy(proxy)
+  if err != nil {
+    return err
+  }
+  proxy = ""
+  if u != nil {
+    proxy = u.String()
+  }
+  if _, err := h.Repository.Proxies().Proxy(proxy, session.Account); err != nil {
+    return err
+  }
+  log.Println("sessions proxy", session.Account, common.ProxyName(proxy))
+  return h.Repository.Update(session, "proxy", common.Secret(proxy))
+}
+
+func (h *SessionsHandler) Relogin() error {
+  log.Println(fmt.Sprintf("twitters sessions relogin..."))
+  for _, session := range h.Repository.Deads() {
+    if err := h.Repository.Relogin(h.Ctx, session); err != nil {
+      log.Println("sessions relogin failed", session.Account, err)
+    } else {
+      log.Println("sessions relogin success", session.Account)
+    }
+  }
+  return nil
+}
+
+func (h *SessionsHandler) LoginStub(dir string, port int) error {
+  stub, err := clients.NewLoginStub(dir)
+  if err != nil {
+    return err
+  }
+  log.Println(fmt.Sprintf("login stub listening on 127.0.0.1:%v with %v steps", port, len(stub.Steps)))
+  return http.ListenAndServe(fmt.Sprintf("127.0.0.1:%v", port), stub)
+}
+
+func (h *SessionsHandler) RotateKey() error {
+  log.Println(fmt.Sprintf("twitters sessions rotating key..."))
+  count, err := h.Repository.Reseal()
+  if err != nil {
+    return err
+  }
+  log.Println("sessions resealed", count)
+  count, err = h.Repository.Credentials().Reseal()
+  if err != nil {
+    return err
+  }
+  log.Println("credentials resealed", count)
+  count, err = h.Repository.Proxies().Reseal()
+  if err != nil {
+    return err
+  }
+  log.Println("proxies resealed", count)
+  return nil
+}
+
 func (h *SessionsHandler) Current() error {
   log.Println(fmt.Sprintf("twitters sessions current..."))
   timestamp := time.Now().UnixMicro()
-  session := h.Repository.Current()
+  session := h.Repository.Current("")
   if session == nil {
     return errors.New("current session is empty")
   }
@@ -112,6 +405,9 @@ func (h *SessionsHandler) Current() error {
     return errors.New("current session has been blocked")
   }
   log.Println("current session", session)
+  for operation, limits := range h.Repository.RateLimits(session) {
+    log.Println("rate limit", operation, limits["remaining"], limits["limit"], limits["reset"])
+  }
   return nil
 }
 
@@ -121,5 +417,80 @@ func (h *SessionsHandler) Flush(id string) error {
   if err != nil {
     return err
   }
-  return h.Repository.Flush(session)
+  return h.Repository.Flush(h.Ctx, session)
+}
+
+func (h *SessionsHandler) Probe(account string) error {
+  log.Println(fmt.Sprintf("twitters sessions probe..."))
+  sessions, err := h.sessions(account)
+  if err != nil {
+    return err
+  }
+  for _, session := range sessions {
+    if err := h.Repository.Probe(h.Ctx, session); err != nil {
+      log.Println("sessions probe failed", session.Account, err)
+    } else {
+      log.Println("sessions probe success", session.Account)
+    }
+  }
+  return nil
+}
+
+func (h *SessionsHandler) Health(account string) error {
+  sessions, err := h.sessions(account)
+  if err != nil {
+    return err
+  }
+  for _, session := range sessions {
+    health := h.Repository.Health(session)
+    line := fmt.Sprintf(
+      "%-20s status=%d requests=%d failures=%d success=%.1f%%",
+      session.Account,
+      session.Status,
+      health.Requests,
+      health.Failures,
+      health.SuccessRate*100,
+    )
+    if failure := health.LastFailure; failure != nil {
+      line += fmt.Sprintf(
+        " last_failure=[%v %v %v %v]",
+        time.UnixMicro(failure.Timestamp).Format(time.RFC3339),
+        failure.Kind,
+        failure.Operation,
+        failure.Reason,
+      )
+    }
+    fmt.Println(line)
+  }
+  return nil
+}
+
+func (h *SessionsHandler) Events(account string, limit int) error {
+  session, err := h.Repository.Get(account)
+  if err != nil {
+    return err
+  }
+  for _, event := range h.Repository.Events().Listings(session.ID, 1, limit) {
+    fmt.Println(
+      time.UnixMicro(event.Timestamp).Format(time.RFC3339),
+      event.Kind,
+      event.Operation,
+      event.Code,
+      event.Status,
+      event.Success,
+      event.Reason,
+    )
+  }
+  return nil
+}
+
+func (h *SessionsHandler) sessions(account string) ([]*models.Session, error) {
+  if account == "" {
+    return h.Repository.Probes(), nil
+  }
+  session, err := h.Repository.Get(account)
+  if err != nil {
+    return nil, err
+  }
+  return []*models.Session{session}, nil
 }
@@ -7,8 +7,10 @@ import (
 
 func NewTasksCommand() *cli.Command {
   return &cli.Command{
-    Name:  "tasks",
-    Usage: "",
+    Name:   "tasks",
+    Usage:  "",
+    Flags:  NewReplayFlags(),
+    Before: ReplayBefore,
     Subcommands: []*cli.Command{
       tasks.NewCloudsCommand(),
       tasks.NewScrapersCommand(),
@@ -14,6 +14,8 @@ func NewScrapersCommand() *cli.Command {
       scrapers.NewRepliesCommand(),
       scrapers.NewMediaCommand(),
       scrapers.NewUsersCommand(),
+      scrapers.NewSearchCommand(),
+      scrapers.NewFollowsCommand(),
     },
   }
 }
@@ -0,0 +1,339 @@
+package scrapers
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "log"
+  "strconv"
+  "time"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/nats-io/nats.go"
+  "github.com/urfave/cli/v2"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+  scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
+)
+
+type FollowsHandler struct {
+  Db                 *gorm.DB
+  Rdb                *redis.Client
+  Ctx                context.Context
+  Nats               *nats.Conn
+  Repository         *repositories.TasksRepository
+  SessionsRepository *repositories.SessionsRepository
+  UsersRepository    *repositories.UsersRepository
+  FollowsRepository  *repositories.FollowsRepository
+  ScrapersRepository *scrapersRepositories.FollowsRepository
+}
+
+func NewFollowsCommand() *cli.Command {
+  var h FollowsHandler
+  return &cli.Command{
+    Name:  "follows",
+    Usage: "",
+    Before: func(c *cli.Context) error {
+      h = FollowsHandler{
+        Db:   common.NewDB(),
+        Rdb:  common.NewRedis(),
+        Ctx:  context.Background(),
+        Nats: common.NewNats(),
+      }
+      h.Repository = &repositories.TasksRepository{
+        Db: h.Db,
+      }
+      h.SessionsRepository = &repositories.SessionsRepository{
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
+      }
+      h.UsersRepository = &repositories.UsersRepository{
+        Db:   h.Db,
+        Nats: h.Nats,
+      }
+      h.FollowsRepository = &repositories.FollowsRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository = &scrapersRepositories.FollowsRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository.SessionsRepository = h.SessionsRepository
+      h.ScrapersRepository.ArchivesRepository = &repositories.ArchivesRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository.DriftRepository = &repositories.DriftRepository{
+        Rdb:  h.Rdb,
+        Ctx:  h.Ctx,
+        Nats: h.Nats,
+      }
+      h.ScrapersRepository.UsersRepository = h.UsersRepository
+      h.ScrapersRepository.FollowsRepository = h.FollowsRepository
+      return nil
+    },
+    Subcommands: []*cli.Command{
+      {
+        Name:  "apply",
+        Usage: "",
+        Action: func(c *cli.Context) (err error) {
+          account := c.Args().Get(0)
+          if account == "" {
+            log.Fatal("twitter account can not be empty")
+            return nil
+          }
+          userID, err := strconv.ParseInt(c.Args().Get(1), 10, 64)
+          if err != nil {
+            log.Fatal("twitter user_id not valid")
+            return nil
+          }
+          followType := c.Args().Get(2)
+          if followType != "followers" && followType != "following" {
+            log.Fatal("follows type must be followers or following")
+            return nil
+          }
+          if err = h.Apply(account, userID, followType); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return
+        },
+      },
+      {
+        Name:  "flush",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          limit, _ := strconv.Atoi(c.Args().Get(0))
+          if limit < 20 {
+            limit = 20
+          }
+          if err := h.Flush(limit); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "process",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          limit, _ := strconv.Atoi(c.Args().Get(0))
+          if limit < 20 {
+            limit = 20
+          }
+          if err := h.Process(limit); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+    },
+  }
+}
+
+func (h *FollowsHandler) Apply(account string, userID int64, followType string) error {
+  log.Println(fmt.Sprintf("tasks follows apply..."))
+  user, err := h.UsersRepository.GetByUserID(userID)
+  if errors.Is(err, gorm.ErrRecordNotFound) {
+    user.ID, _ = h.UsersRepository.Create(
+      account,
+      userID,
+      "",
+      "",
+      "",
+      0,
+      0,
+      0,
+      0,
+      0,
+      0,
+    )
+  }
+  name := fmt.Sprintf("%v@%v", user.ID, followType)
+  action := config.TASK_ACTION_SCRAPERS_FOLLOWS
+  params := map[string]interface{}{
+    "user_id": user.ID,
+    "type":    followType,
+  }
+  return h.Repository.Apply(name, action, params)
+}
+
+func (h *FollowsHandler) Flush(limit int) error {
+  log.Println(fmt.Sprintf("tasks follows flushing..."))
+  tasks := h.Repository.Ranking(
+    []string{"id", "params", "timestamp"},
+    map[string]interface{}{
+      "action": config.TASK_ACTION_SCRAPERS_FOLLOWS,
+      "status": 2,
+    },
+    "timestamp",
+    1,
+    limit,
+  )
+  for _, task := range tasks {
+    mutex := common.NewMutex(
+      h.Rdb,
+      h.Ctx,
+      fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_FOLLOWS_FLUSH, task.ID),
+    )
+    if !mutex.Lock(30 * time.Second) {
+      continue
+    }
+
+    timestamp := time.Now().UnixMicro()
+    if timestamp-task.Timestamp < config.SCRAPERS_FOLLOWS_FLUSH_INTERVAL {
+      log.Println("waiting for next flush")
+      mutex.Unlock()
+      continue
+    }
+    h.Repository.Updates(task, map[string]interface{}{
+      "timestamp": timestamp,
+      "status":    1,
+    })
+
+    mutex.Unlock()
+  }
+  return nil
+}
+
+func (h *FollowsHandler) Process(limit int) error {
+  log.Println(fmt.Sprintf("tasks follows processing..."))
+  count, _ := h.Rdb.ZCard(h.Ctx, config.REDIS_KEY_TASKS_FOLLOWS_TARGET).Result()
+  conditions := make(map[string]interface{})
+  if count < config.SCRAPERS_FOLLOWS_TARGET_LIMIT {
+    conditions["action"] = config.TASK_ACTION_SCRAPERS_FOLLOWS
+  } else {
+    conditions["ids"], _ = h.Rdb.ZRange(h.Ctx, config.REDIS_KEY_TASKS_FOLLOWS_TARGET, 0, -1).Result()
+  }
+  tasks := h.Repository.Ranking(
+    []string{"id", "params", "timestamp"},
+    conditions,
+    "timestamp",
+    1,
+    limit,
+  )
+  for _, task := range tasks {
+    mutex := common.NewMutex(
+      h.Rdb,
+      h.Ctx,
+      fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_FOLLOWS_PROCESS, task.ID),
+    )
+    if !mutex.Lock(30 * time.Second) {
+      continue
+    }
+
+    timestamp := time.Now().UnixMicro()
+    score, _ := h.Rdb.ZScore(h.Ctx, config.REDIS_KEY_TASKS_FOLLOWS_TARGET, task.ID).Result()
+    if score == 0 && count < config.SCRAPERS_FOLLOWS_TARGET_LIMIT {
+      h.Rdb.ZAdd(
+        h.Ctx,
+        config.REDIS_KEY_TASKS_FOLLOWS_TARGET,
+        &redis.Z{
+          Score:  float64(timestamp),
+          Member: task.ID,
+        },
+      )
+      count++
+    }
+
+    if timestamp-task.Timestamp < 30000000 {
+      log.Println("waiting for next process")
+      mutex.Unlock()
+      continue
+    }
+
+    if _, ok := task.Params["user_id"]; !ok {
+      mutex.Unlock()
+      return errors.New("follows user_id is empty")
+    }
+    user, err := h.UsersRepository.Find(task.Params["user_id"].(string))
+    if err != nil {
+      mutex.Unlock()
+      return err
+    }
+
+    h.Repository.Update(task, "timestamp", timestamp)
+    session := h.SessionsRepository.Resume(task.Params, scrapersRepositories.FollowsOperation(task.Params["type"]), 1)
+    if session == nil {
+      mutex.Unlock()
+      return errors.New("current session is empty")
+    }
+    if _, ok := task.Params["started_at"]; !ok {
+      task.Params["started_at"] = timestamp
+    }
+    h.SessionsRepository.Update(session, "timestamp", timestamp)
+    if cursor, count, err := h.ScrapersRepository.Process(repositories.WithTask(h.Ctx, task.ID), session, user, task.Params); err == nil {
+      if cursor == "" {
+        if scrapersRepositories.FollowsCompleted(task.Params) {
+          conditions := map[string]interface{}{}
+          if task.Params["type"] == "followers" {
+            conditions["user_id"] = user.ID
+          } else {
+            conditions["follower_id"] = user.ID
+          }
+          var startedAt int64
+          switch value := task.Params["started_at"].(type) {
+          case float64:
+            startedAt = int64(value)
+          case int64:
+            startedAt = value
+          }
+          unfollowed := h.FollowsRepository.Unfollowed(conditions, startedAt, timestamp)
+          log.Println("scrapers follows unfollowed", task.Params["type"], unfollowed)
+        }
+        delete(task.Params, "cursors")
+        delete(task.Params, "started_at")
+        delete(task.Params, "applied")
+        delete(task.Params, "completed")
+        h.Rdb.ZRem(h.Ctx, config.REDIS_KEY_TASKS_FOLLOWS_TARGET, task.ID)
+        h.Repository.Updates(task, map[string]interface{}{
+          "params": task.Params,
+          "status": 2,
+        })
+        mutex.Unlock()
+        continue
+      }
+
+      if count < 20 {
+        if score == 0 || timestamp-int64(score) < config.SCRAPERS_CURSOR_WAITING_TIMEOUT {
+          log.Println("waiting for cursor change", timestamp-int64(score), config.SCRAPERS_CURSOR_WAITING_TIMEOUT)
+          mutex.Unlock()
+          continue
+        }
+      }
+
+      if score > 0 {
+        h.Rdb.ZAdd(
+          h.Ctx,
+          config.REDIS_KEY_TASKS_FOLLOWS_TARGET,
+          &redis.Z{
+            Score:  float64(timestamp),
+            Member: task.ID,
+          },
+        )
+      }
+
+      cursors := make(map[string]interface{})
+      if _, ok := task.Params["cursors"]; ok {
+        cursors = task.Params["cursors"].(map[string]interface{})
+      }
+      cursors[session.Account] = cursor
+      task.Params["cursors"] = cursors
+      h.Repository.Updates(task, map[string]interface{}{
+        "params": task.Params,
+        "status": 1,
+      })
+    } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
+      log.Println("error", err)
+    }
+
+    mutex.Unlock()
+  }
+
+  return nil
+}
@@ -210,7 +210,7 @@ func (h *RepliesHandler) Process(limit int) error {
         hash := sha1.Sum([]byte(url))
         urlSha1 := hex.EncodeToString(hash[:])
         if !h.PhotosRepository.IsExists(url, urlSha1) {
-          if err := h.ScrapersPhotosRepository.Download(url, urlSha1); err != nil {
+          if err := h.ScrapersPhotosRepository.Download(h.Ctx, url, urlSha1); err != nil {
             log.Println("error", err)
             h.Repository.Update(task, "status", 3)
             continue
@@ -232,7 +232,7 @@ func (h *RepliesHandler) Process(limit int) error {
           hash := sha1.Sum([]byte(url))
           urlSha1 := hex.EncodeToString(hash[:])
           if !h.PhotosRepository.IsExists(url, urlSha1) {
-            if err := h.ScrapersPhotosRepository.Download(url, urlSha1); err != nil {
+            if err := h.ScrapersPhotosRepository.Download(h.Ctx, url, urlSha1); err != nil {
               log.Println("error", err)
               h.Repository.Update(task, "status", 3)
               continue
@@ -243,7 +243,7 @@ func (h *RepliesHandler) Process(limit int) error {
         hash := sha1.Sum([]byte(url))
         urlSha1 := hex.EncodeToString(hash[:])
         if !h.VideosRepository.IsExists(url, urlSha1) {
-          if err := h.ScrapersVideosRepository.Download(url, urlSha1); err != nil {
+          if err := h.ScrapersVideosRepository.Download(h.Ctx, url, urlSha1); err != nil {
             log.Println("error", err)
             h.Repository.Update(task, "status", 3)
             continue
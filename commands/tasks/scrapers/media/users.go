@@ -198,7 +198,7 @@ func (h *UsersHandler) Process(limit int) error {
     hash := sha1.Sum([]byte(url))
     urlSha1 := hex.EncodeToString(hash[:])
     if !h.PhotosRepository.IsExists(url, urlSha1) && freeGB > common.GetEnvInt("SCRAPER_DISK_MIN_PHOTOS_GB") {
-      if err := h.ScrapersPhotosRepository.Download(url, urlSha1); err != nil {
+      if err := h.ScrapersPhotosRepository.Download(h.Ctx, url, urlSha1); err != nil {
         log.Println("error", err)
         h.Repository.Update(task, "status", 3)
         continue
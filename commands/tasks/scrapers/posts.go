@@ -15,7 +15,7 @@ import (
 
   "scraper.local/twitter-scraper/common"
   "scraper.local/twitter-scraper/config"
-  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
   "scraper.local/twitter-scraper/repositories"
   scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
 )
@@ -47,7 +47,9 @@ func NewPostsCommand() *cli.Command {
         Db: h.Db,
       }
       h.SessionsRepository = &repositories.SessionsRepository{
-        Db: h.Db,
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
       }
       h.UsersRepository = &repositories.UsersRepository{
         Db:   h.Db,
@@ -57,6 +59,14 @@ func NewPostsCommand() *cli.Command {
         Db: h.Db,
       }
       h.ScrapersRepository.SessionsRepository = h.SessionsRepository
+      h.ScrapersRepository.ArchivesRepository = &repositories.ArchivesRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository.DriftRepository = &repositories.DriftRepository{
+        Rdb:  h.Rdb,
+        Ctx:  h.Ctx,
+        Nats: h.Nats,
+      }
       h.ScrapersRepository.UsersRepository = h.UsersRepository
       h.ScrapersRepository.PostsRepository = &repositories.PostsRepository{
         Db:   h.Db,
@@ -68,6 +78,12 @@ func NewPostsCommand() *cli.Command {
       {
         Name:  "apply",
         Usage: "",
+        Flags: []cli.Flag{
+          &cli.BoolFlag{
+            Name:  "media-only",
+            Usage: "scrape the media timeline of the user only",
+          },
+        },
         Action: func(c *cli.Context) (err error) {
           account := c.Args().Get(0)
           if account == "" {
@@ -79,7 +95,7 @@ func NewPostsCommand() *cli.Command {
             log.Fatal("twitter user_id not valid")
             return nil
           }
-          if err = h.Apply(account, userID); err != nil {
+          if err = h.Apply(account, userID, c.Bool("media-only")); err != nil {
             return cli.Exit(err.Error(), 1)
           }
           return
@@ -113,11 +129,25 @@ func NewPostsCommand() *cli.Command {
           return nil
         },
       },
+      {
+        Name:  "verify",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          budget, _ := strconv.Atoi(c.Args().Get(0))
+          if budget < 1 {
+            budget = config.SCRAPERS_POSTS_VERIFY_LIMIT
+          }
+          if err := h.Verify(budget); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
     },
   }
 }
 
-func (h *PostsHandler) Apply(account string, userID int64) error {
+func (h *PostsHandler) Apply(account string, userID int64, mediaOnly bool) error {
   log.Println(fmt.Sprintf("tasks posts apply..."))
   user, err := h.UsersRepository.GetByUserID(userID)
   if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -138,7 +168,8 @@ func (h *PostsHandler) Apply(account string, userID int64) error {
   name := fmt.Sprintf("%v@posts", user.ID)
   action := config.TASK_ACTION_SCRAPERS_POSTS
   params := map[string]interface{}{
-    "user_id": user.ID,
+    "user_id":    user.ID,
+    "media_only": mediaOnly,
   }
   return h.Repository.Apply(name, action, params)
 }
@@ -184,15 +215,18 @@ func (h *PostsHandler) Flush(limit int) error {
       mutex.Unlock()
       return err
     }
-    session := h.SessionsRepository.Current()
-    if session.ID == "" {
+    session := h.SessionsRepository.Current("UserTweets")
+    if session == nil {
       mutex.Unlock()
       return errors.New("current session is empty")
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.ScrapersRepository.Process(session, user, task.Params); err == nil {
+    if cursor, count, err := h.ScrapersRepository.Process(repositories.WithTask(h.Ctx, task.ID), session, user, task.Params); err == nil {
       log.Println("scrapers posts flush result", cursor, count)
     } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
       log.Println("error", err)
     }
 
@@ -257,26 +291,12 @@ func (h *PostsHandler) Process(limit int) error {
       log.Println("user not found", task.Params["user_id"])
       continue
     }
-    var session *models.Session
-    if _, ok := task.Params["cursors"]; ok {
-      cursors := task.Params["cursors"].(map[string]interface{})
-      for account, _ := range cursors {
-        session, _ = h.SessionsRepository.Get(account)
-        if session != nil && session.Status == 8 {
-          break
-        }
-      }
-      if session == nil {
-        session = h.SessionsRepository.Special()
-      }
-    } else {
-      session = h.SessionsRepository.Special()
-    }
+    session := h.SessionsRepository.Resume(task.Params, "UserTweets", 8)
     if session == nil {
       return errors.New("special session is empty")
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.ScrapersRepository.Process(session, user, task.Params); err == nil {
+    if cursor, count, err := h.ScrapersRepository.Process(repositories.WithTask(h.Ctx, task.ID), session, user, task.Params); err == nil {
       if cursor == "" {
         delete(task.Params, "cursors")
         h.Rdb.ZRem(h.Ctx, config.REDIS_KEY_TASKS_POSTS_TARGET, task.ID)
@@ -316,9 +336,32 @@ func (h *PostsHandler) Process(limit int) error {
         "status": 1,
       })
     } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
       log.Println("error", err)
     }
   }
 
   return nil
 }
+
+func (h *PostsHandler) Verify(budget int) error {
+  log.Println(fmt.Sprintf("tasks posts verifying..."))
+  session := h.SessionsRepository.Current("TweetResultByRestId")
+  if session == nil {
+    return errors.New("current session is empty")
+  }
+  timestamp := time.Now().UnixMilli()
+  posts := h.ScrapersRepository.PostsRepository.Verifying(timestamp-config.SCRAPERS_POSTS_VERIFY_INTERVAL, budget)
+  for _, post := range posts {
+    reason, err := h.ScrapersRepository.Verify(h.Ctx, session, post)
+    if err != nil {
+      return err
+    }
+    log.Println("scrapers posts verify result", post.TwitterID, reason)
+    h.ScrapersRepository.PostsRepository.Verified(post, reason)
+  }
+  h.SessionsRepository.Update(session, "timestamp", time.Now().UnixMicro())
+  return nil
+}
@@ -4,6 +4,7 @@ import (
   "context"
   "errors"
   "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
   "fmt"
   "log"
   "strconv"
@@ -57,7 +58,9 @@ func NewRepliesCommand() *cli.Command {
         Db: h.Db,
       }
       h.SessionsRepository = &repositories.SessionsRepository{
-        Db: h.Db,
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
       }
       h.PostsRepository = &repositories.PostsRepository{
         Db: h.Db,
@@ -65,10 +68,19 @@ func NewRepliesCommand() *cli.Command {
       h.PostsScrapersRepository = &scrapersRepositories.PostsRepository{
         Db: h.Db,
       }
+      h.PostsScrapersRepository.SessionsRepository = h.SessionsRepository
       h.ScrapersRepository = &scrapersRepositories.RepliesRepository{
         Db: h.Db,
       }
       h.ScrapersRepository.SessionsRepository = h.SessionsRepository
+      h.ScrapersRepository.ArchivesRepository = &repositories.ArchivesRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository.DriftRepository = &repositories.DriftRepository{
+        Rdb:  h.Rdb,
+        Ctx:  h.Ctx,
+        Nats: h.Nats,
+      }
       h.ScrapersRepository.UsersRepository = &repositories.UsersRepository{
         Db:   h.Db,
         Nats: h.Nats,
@@ -227,15 +239,18 @@ func (h *RepliesHandler) Flush(limit int) error {
       mutex.Unlock()
       continue
     }
-    session := h.SessionsRepository.Current()
+    session := h.SessionsRepository.Current("TweetDetail")
     if session == nil {
       mutex.Unlock()
       return errors.New("current session is empty")
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.ScrapersRepository.Process(session, post, task.Params); err == nil {
+    if cursor, count, err := h.ScrapersRepository.Process(repositories.WithTask(h.Ctx, task.ID), session, post, task.Params); err == nil {
       log.Println("scrapers replies flush result", cursor, count)
     } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
       log.Println("error", err)
     }
 
@@ -300,27 +315,13 @@ func (h *RepliesHandler) Process(limit int) error {
       mutex.Unlock()
       continue
     }
-    var session *models.Session
-    if _, ok := task.Params["cursors"]; ok {
-      cursors := task.Params["cursors"].(map[string]interface{})
-      for account, _ := range cursors {
-        session, _ = h.SessionsRepository.Get(account)
-        if session.Status == 1 {
-          break
-        }
-      }
-      if session.ID == "" {
-        session = h.SessionsRepository.Current()
-      }
-    } else {
-      session = h.SessionsRepository.Current()
-    }
-    if session.ID == "" {
+    session := h.SessionsRepository.Resume(task.Params, "TweetDetail", 1)
+    if session == nil {
       mutex.Unlock()
       return errors.New("current session is empty")
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.ScrapersRepository.Process(session, post, task.Params); err == nil {
+    if cursor, count, err := h.ScrapersRepository.Process(repositories.WithTask(h.Ctx, task.ID), session, post, task.Params); err == nil {
       if cursor == "" {
         delete(task.Params, "cursors")
         h.Rdb.ZRem(h.Ctx, config.REDIS_KEY_TASKS_REPLIES_TARGET, task.ID)
@@ -359,6 +360,9 @@ func (h *RepliesHandler) Process(limit int) error {
       task.Params["cursors"] = cursors
       h.Repository.Update(task, "params", task.Params)
     } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
       log.Println("error", err)
     }
 
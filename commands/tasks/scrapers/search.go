@@ -0,0 +1,294 @@
+package scrapers
+
+import (
+  "context"
+  "crypto/md5"
+  "encoding/hex"
+  "errors"
+  "fmt"
+  "log"
+  "strconv"
+  "strings"
+  "time"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/nats-io/nats.go"
+  "github.com/urfave/cli/v2"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+  scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
+)
+
+type SearchHandler struct {
+  Db                 *gorm.DB
+  Rdb                *redis.Client
+  Ctx                context.Context
+  Nats               *nats.Conn
+  Repository         *repositories.TasksRepository
+  SessionsRepository *repositories.SessionsRepository
+  ScrapersRepository *scrapersRepositories.SearchRepository
+}
+
+func NewSearchCommand() *cli.Command {
+  var h SearchHandler
+  return &cli.Command{
+    Name:  "search",
+    Usage: "",
+    Before: func(c *cli.Context) error {
+      h = SearchHandler{
+        Db:   common.NewDB(),
+        Rdb:  common.NewRedis(),
+        Ctx:  context.Background(),
+        Nats: common.NewNats(),
+      }
+      h.Repository = &repositories.TasksRepository{
+        Db: h.Db,
+      }
+      h.SessionsRepository = &repositories.SessionsRepository{
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
+      }
+      h.ScrapersRepository = &scrapersRepositories.SearchRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository.SessionsRepository = h.SessionsRepository
+      h.ScrapersRepository.ArchivesRepository = &repositories.ArchivesRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository.DriftRepository = &repositories.DriftRepository{
+        Rdb:  h.Rdb,
+        Ctx:  h.Ctx,
+        Nats: h.Nats,
+      }
+      h.ScrapersRepository.UsersRepository = &repositories.UsersRepository{
+        Db:   h.Db,
+        Nats: h.Nats,
+      }
+      h.ScrapersRepository.PostsRepository = &repositories.PostsRepository{
+        Db:   h.Db,
+        Nats: h.Nats,
+      }
+      h.ScrapersRepository.SearchRepository = &repositories.SearchRepository{
+        Db: h.Db,
+      }
+      return nil
+    },
+    Subcommands: []*cli.Command{
+      {
+        Name:  "apply",
+        Usage: "",
+        Action: func(c *cli.Context) (err error) {
+          query := strings.TrimSpace(c.Args().Get(0))
+          if query == "" {
+            log.Fatal("search query can not be empty")
+            return nil
+          }
+          if err = h.Apply(query); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return
+        },
+      },
+      {
+        Name:  "flush",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          limit, _ := strconv.Atoi(c.Args().Get(0))
+          if limit < 20 {
+            limit = 20
+          }
+          if err := h.Flush(limit); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+      {
+        Name:  "process",
+        Usage: "",
+        Action: func(c *cli.Context) error {
+          limit, _ := strconv.Atoi(c.Args().Get(0))
+          if limit < 20 {
+            limit = 20
+          }
+          if err := h.Process(limit); err != nil {
+            return cli.Exit(err.Error(), 1)
+          }
+          return nil
+        },
+      },
+    },
+  }
+}
+
+func (h *SearchHandler) Apply(query string) error {
+  log.Println(fmt.Sprintf("tasks search apply..."))
+  hash := md5.Sum([]byte(query))
+  name := fmt.Sprintf("%v@search", hex.EncodeToString(hash[:]))
+  action := config.TASK_ACTION_SCRAPERS_SEARCH
+  params := map[string]interface{}{
+    "query": query,
+  }
+  return h.Repository.Apply(name, action, params)
+}
+
+func (h *SearchHandler) Flush(limit int) error {
+  log.Println(fmt.Sprintf("tasks search flushing..."))
+  tasks := h.Repository.Ranking(
+    []string{"id", "params", "timestamp"},
+    map[string]interface{}{
+      "action": config.TASK_ACTION_SCRAPERS_SEARCH,
+      "status": 2,
+    },
+    "timestamp",
+    1,
+    limit,
+  )
+  for _, task := range tasks {
+    mutex := common.NewMutex(
+      h.Rdb,
+      h.Ctx,
+      fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_SEARCH_FLUSH, task.ID),
+    )
+    if !mutex.Lock(30 * time.Second) {
+      continue
+    }
+
+    timestamp := time.Now().UnixMicro()
+    if timestamp-task.Timestamp < 30000000 {
+      log.Println("waiting for next process")
+      mutex.Unlock()
+      continue
+    }
+    h.Repository.Update(task, "timestamp", timestamp)
+    session := h.SessionsRepository.Current("SearchTimeline")
+    if session == nil {
+      mutex.Unlock()
+      return errors.New("current session is empty")
+    }
+    h.SessionsRepository.Update(session, "timestamp", timestamp)
+    if cursor, count, err := h.ScrapersRepository.Process(h.Ctx, session, task); err == nil {
+      log.Println("scrapers search flush result", cursor, count)
+    } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
+      log.Println("error", err)
+    }
+
+    mutex.Unlock()
+  }
+  return nil
+}
+
+func (h *SearchHandler) Process(limit int) error {
+  log.Println(fmt.Sprintf("tasks search processing..."))
+  count, _ := h.Rdb.ZCard(h.Ctx, config.REDIS_KEY_TASKS_SEARCH_TARGET).Result()
+  conditions := make(map[string]interface{})
+  if count < config.SCRAPERS_SEARCH_TARGET_LIMIT {
+    conditions["action"] = config.TASK_ACTION_SCRAPERS_SEARCH
+  } else {
+    conditions["ids"], _ = h.Rdb.ZRange(h.Ctx, config.REDIS_KEY_TASKS_SEARCH_TARGET, 0, -1).Result()
+  }
+  tasks := h.Repository.Ranking(
+    []string{"id", "params", "timestamp"},
+    conditions,
+    "timestamp",
+    1,
+    limit,
+  )
+  for _, task := range tasks {
+    mutex := common.NewMutex(
+      h.Rdb,
+      h.Ctx,
+      fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_SEARCH_PROCESS, task.ID),
+    )
+    if !mutex.Lock(30 * time.Second) {
+      continue
+    }
+
+    timestamp := time.Now().UnixMicro()
+    score, _ := h.Rdb.ZScore(h.Ctx, config.REDIS_KEY_TASKS_SEARCH_TARGET, task.ID).Result()
+    if score == 0 && count < config.SCRAPERS_SEARCH_TARGET_LIMIT {
+      h.Rdb.ZAdd(
+        h.Ctx,
+        config.REDIS_KEY_TASKS_SEARCH_TARGET,
+        &redis.Z{
+          Score:  float64(timestamp),
+          Member: task.ID,
+        },
+      )
+      count++
+    }
+
+    if timestamp-task.Timestamp < 30000000 {
+      log.Println("waiting for next process")
+      mutex.Unlock()
+      continue
+    }
+
+    h.Repository.Update(task, "timestamp", timestamp)
+    session := h.SessionsRepository.Resume(task.Params, "SearchTimeline", 1)
+    if session == nil {
+      mutex.Unlock()
+      return errors.New("current session is empty")
+    }
+    h.SessionsRepository.Update(session, "timestamp", timestamp)
+    if cursor, count, err := h.ScrapersRepository.Process(h.Ctx, session, task); err == nil {
+      if cursor == "" {
+        delete(task.Params, "cursors")
+        h.Rdb.ZRem(h.Ctx, config.REDIS_KEY_TASKS_SEARCH_TARGET, task.ID)
+        h.Repository.Updates(task, map[string]interface{}{
+          "params": task.Params,
+          "status": 2,
+        })
+        mutex.Unlock()
+        continue
+      }
+
+      if count < 20 {
+        if score == 0 || timestamp-int64(score) < config.SCRAPERS_CURSOR_WAITING_TIMEOUT {
+          log.Println("waiting for cursor change", timestamp-int64(score), config.SCRAPERS_CURSOR_WAITING_TIMEOUT)
+          mutex.Unlock()
+          continue
+        }
+      }
+
+      if score > 0 {
+        h.Rdb.ZAdd(
+          h.Ctx,
+          config.REDIS_KEY_TASKS_SEARCH_TARGET,
+          &redis.Z{
+            Score:  float64(timestamp),
+            Member: task.ID,
+          },
+        )
+      }
+
+      cursors := make(map[string]interface{})
+      if _, ok := task.Params["cursors"]; ok {
+        cursors = task.Params["cursors"].(map[string]interface{})
+      }
+      cursors[session.Account] = cursor
+      task.Params["cursors"] = cursors
+      h.Repository.Updates(task, map[string]interface{}{
+        "params": task.Params,
+        "status": 1,
+      })
+    } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
+      log.Println("error", err)
+    }
+
+    mutex.Unlock()
+  }
+
+  return nil
+}
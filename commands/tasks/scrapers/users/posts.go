@@ -3,7 +3,6 @@ package users
 import (
   "context"
   "errors"
-  "scraper.local/twitter-scraper/models"
   "fmt"
   "log"
   "strconv"
@@ -16,6 +15,7 @@ import (
 
   "scraper.local/twitter-scraper/common"
   "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
   "scraper.local/twitter-scraper/repositories"
   scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
 )
@@ -48,7 +48,9 @@ func NewPostsCommand() *cli.Command {
         Db: h.Db,
       }
       h.SessionsRepository = &repositories.SessionsRepository{
-        Db: h.Db,
+        Db:  h.Db,
+        Rdb: h.Rdb,
+        Ctx: h.Ctx,
       }
       h.UsersRepository = &repositories.UsersRepository{
         Db: h.Db,
@@ -57,6 +59,14 @@ func NewPostsCommand() *cli.Command {
         Db: h.Db,
       }
       h.ScrapersRepository.SessionsRepository = h.SessionsRepository
+      h.ScrapersRepository.ArchivesRepository = &repositories.ArchivesRepository{
+        Db: h.Db,
+      }
+      h.ScrapersRepository.DriftRepository = &repositories.DriftRepository{
+        Rdb:  h.Rdb,
+        Ctx:  h.Ctx,
+        Nats: h.Nats,
+      }
       h.ScrapersRepository.UsersRepository = h.UsersRepository
       h.ScrapersRepository.PostsRepository = &repositories.PostsRepository{
         Db:   h.Db,
@@ -172,15 +182,18 @@ func (h *PostsHandler) Flush(limit int) error {
       mutex.Unlock()
       continue
     }
-    session := h.SessionsRepository.Current()
+    session := h.SessionsRepository.Current("UserTweets")
     if session == nil {
       mutex.Unlock()
       return errors.New("current session is empty")
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.ScrapersRepository.Process(session, user, task.Params); err == nil {
+    if cursor, count, err := h.ScrapersRepository.Process(repositories.WithTask(h.Ctx, task.ID), session, user, task.Params); err == nil {
       log.Println("scrapers posts flush result", cursor, count)
     } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
       log.Println("error", err)
     }
 
@@ -249,27 +262,13 @@ func (h *PostsHandler) Process(limit int) error {
       mutex.Unlock()
       continue
     }
-    var session *models.Session
-    if _, ok := task.Params["cursors"]; ok {
-      cursors := task.Params["cursors"].(map[string]interface{})
-      for account, _ := range cursors {
-        session, _ = h.SessionsRepository.Get(account)
-        if session != nil && session.Status == 1 {
-          break
-        }
-      }
-      if session == nil {
-        session = h.SessionsRepository.Current()
-      }
-    } else {
-      session = h.SessionsRepository.Current()
-    }
+    session := h.SessionsRepository.Resume(task.Params, "UserTweets", 1)
     if session == nil {
       mutex.Unlock()
       return errors.New("current session is empty")
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.ScrapersRepository.Process(session, user, task.Params); err == nil {
+    if cursor, count, err := h.ScrapersRepository.Process(repositories.WithTask(h.Ctx, task.ID), session, user, task.Params); err == nil {
       if cursor == "" {
         delete(task.Params, "cursors")
         h.Rdb.ZRem(h.Ctx, config.REDIS_KEY_TASKS_USERS_POSTS_TARGET, task.ID)
@@ -311,6 +310,9 @@ func (h *PostsHandler) Process(limit int) error {
         "status": 1,
       })
     } else {
+      if parsers.IsDrift(err) {
+        h.Repository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
       log.Println("error", err)
     }
 
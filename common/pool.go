@@ -25,10 +25,11 @@ var (
 )
 
 type ApiContext struct {
-  Db  *gorm.DB
-  Rdb *redis.Client
-  Ctx context.Context
-  Mux sync.Mutex
+  Db   *gorm.DB
+  Rdb  *redis.Client
+  Ctx  context.Context
+  Nats *nats.Conn
+  Mux  sync.Mutex
 }
 
 type NatsContext struct {
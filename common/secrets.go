@@ -0,0 +1,270 @@
+package common
+
+import (
+  "crypto/aes"
+  "crypto/cipher"
+  "crypto/rand"
+  "crypto/sha256"
+  "database/sql/driver"
+  "encoding/base64"
+  "encoding/hex"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "strings"
+
+  "gorm.io/gorm"
+  "gorm.io/gorm/schema"
+)
+
+const secretPrefix = "enc:v1:"
+
+// Secret is a string column sealed with envelope encryption, every value is
+// encrypted by its own data key which is wrapped by the master key.
+// Plaintext rows written before the key was set are still readable.
+type Secret string
+
+// SecretMap is a json column sealed as a Secret, the sealed value is stored
+// as a json string so the column keeps its json type.
+type SecretMap map[string]interface{}
+
+// SecretKey is the master key of SCRAPER_SECRET_KEY, a base64 or hex
+// encoded 32 bytes key. Keys listed in SCRAPER_SECRET_KEY_PREVIOUS_1..n only
+// open values sealed before a rotation.
+type SecretKey struct {
+  ID  string
+  Key []byte
+}
+
+func SecretKeys() (current *SecretKey, keys map[string]*SecretKey, err error) {
+  keys = make(map[string]*SecretKey)
+  values := append([]string{GetEnvString("SCRAPER_SECRET_KEY")}, GetEnvArray("SCRAPER_SECRET_KEY_PREVIOUS")...)
+  for i, value := range values {
+    if value == "" {
+      continue
+    }
+    key, err := NewSecretKey(value)
+    if err != nil {
+      return nil, nil, err
+    }
+    if i == 0 {
+      current = key
+    }
+    keys[key.ID] = key
+  }
+  return
+}
+
+func NewSecretKey(value string) (*SecretKey, error) {
+  key, err := base64.StdEncoding.DecodeString(value)
+  if err != nil || len(key) != 32 {
+    key, err = hex.DecodeString(value)
+  }
+  if err != nil || len(key) != 32 {
+    return nil, errors.New("secret key must be 32 bytes in base64 or hex")
+  }
+  hash := sha256.Sum256(key)
+  return &SecretKey{
+    ID:  hex.EncodeToString(hash[:4]),
+    Key: key,
+  }, nil
+}
+
+// Seal encrypts the plaintext with a fresh data key, an unset master key
+// keeps the plaintext as is.
+func Seal(plaintext string) (string, error) {
+  current, _, err := SecretKeys()
+  if err != nil {
+    return "", err
+  }
+  if current == nil || plaintext == "" {
+    return plaintext, nil
+  }
+
+  dataKey := make([]byte, 32)
+  if _, err := rand.Read(dataKey); err != nil {
+    return "", err
+  }
+  wrapped, err := sealBytes(current.Key, dataKey)
+  if err != nil {
+    return "", err
+  }
+  sealed, err := sealBytes(dataKey, []byte(plaintext))
+  if err != nil {
+    return "", err
+  }
+  return secretPrefix + strings.Join([]string{
+    current.ID,
+    base64.RawStdEncoding.EncodeToString(wrapped),
+    base64.RawStdEncoding.EncodeToString(sealed),
+  }, ":"), nil
+}
+
+func Open(value string) (string, error) {
+  if !IsSealed(value) {
+    return value, nil
+  }
+  parts := strings.Split(strings.TrimPrefix(value, secretPrefix), ":")
+  if len(parts) != 3 {
+    return "", errors.New("sealed secret malformed")
+  }
+  _, keys, err := SecretKeys()
+  if err != nil {
+    return "", err
+  }
+  key, ok := keys[parts[0]]
+  if !ok {
+    return "", errors.New(fmt.Sprintf("secret key %v not found", parts[0]))
+  }
+  wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+  if err != nil {
+    return "", err
+  }
+  sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+  if err != nil {
+    return "", err
+  }
+  dataKey, err := openBytes(key.Key, wrapped)
+  if err != nil {
+    return "", err
+  }
+  plaintext, err := openBytes(dataKey, sealed)
+  if err != nil {
+    return "", err
+  }
+  return string(plaintext), nil
+}
+
+func IsSealed(value string) bool {
+  return strings.HasPrefix(value, secretPrefix)
+}
+
+func sealBytes(key []byte, plaintext []byte) ([]byte, error) {
+  block, err := aes.NewCipher(key)
+  if err != nil {
+    return nil, err
+  }
+  gcm, err := cipher.NewGCM(block)
+  if err != nil {
+    return nil, err
+  }
+  nonce := make([]byte, gcm.NonceSize())
+  if _, err := rand.Read(nonce); err != nil {
+    return nil, err
+  }
+  return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func openBytes(key []byte, sealed []byte) ([]byte, error) {
+  block, err := aes.NewCipher(key)
+  if err != nil {
+    return nil, err
+  }
+  gcm, err := cipher.NewGCM(block)
+  if err != nil {
+    return nil, err
+  }
+  if len(sealed) < gcm.NonceSize() {
+    return nil, errors.New("sealed secret too short")
+  }
+  return gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+}
+
+// String keeps the secret out of logs and error messages.
+func (s Secret) String() string {
+  if s == "" {
+    return ""
+  }
+  return "[redacted]"
+}
+
+func (s Secret) Value() (driver.Value, error) {
+  return Seal(string(s))
+}
+
+func (s *Secret) Scan(value interface{}) error {
+  var data string
+  switch v := value.(type) {
+  case []byte:
+    data = string(v)
+  case string:
+    data = v
+  case nil:
+    data = ""
+  default:
+    return errors.New(fmt.Sprintf("secret can not scan %T", value))
+  }
+  plaintext, err := Open(data)
+  if err != nil {
+    return err
+  }
+  *s = Secret(plaintext)
+  return nil
+}
+
+func SecretJSONMap(in interface{}) SecretMap {
+  return SecretMap(JSONMap(in))
+}
+
+func (m SecretMap) MarshalJSON() ([]byte, error) {
+  if m == nil {
+    return []byte("null"), nil
+  }
+  return json.Marshal(map[string]interface{}(m))
+}
+
+func (m SecretMap) String() string {
+  return "[redacted]"
+}
+
+func (m SecretMap) Value() (driver.Value, error) {
+  buf, err := m.MarshalJSON()
+  if err != nil {
+    return nil, err
+  }
+  sealed, err := Seal(string(buf))
+  if err != nil || !IsSealed(sealed) {
+    return string(buf), err
+  }
+  buf, err = json.Marshal(sealed)
+  return string(buf), err
+}
+
+func (m *SecretMap) Scan(value interface{}) error {
+  var data []byte
+  switch v := value.(type) {
+  case []byte:
+    data = v
+  case string:
+    data = []byte(v)
+  case nil:
+    *m = nil
+    return nil
+  default:
+    return errors.New(fmt.Sprintf("secret map can not scan %T", value))
+  }
+
+  var sealed string
+  if err := json.Unmarshal(data, &sealed); err == nil {
+    plaintext, err := Open(sealed)
+    if err != nil {
+      return err
+    }
+    data = []byte(plaintext)
+  }
+  return json.Unmarshal(data, (*map[string]interface{})(m))
+}
+
+func (SecretMap) GormDataType() string {
+  return "json"
+}
+
+func (SecretMap) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+  switch db.Dialector.Name() {
+  case "postgres":
+    return "JSONB"
+  case "mysql":
+    return "JSON"
+  }
+  return ""
+}
@@ -1,16 +1,232 @@
 package common
 
 import (
+  "bufio"
   "context"
-  "h12.io/socks"
+  "crypto/tls"
+  "encoding/base64"
+  "errors"
+  "fmt"
   "net"
+  "net/http"
+  "net/url"
+  "strconv"
+  "strings"
+  "time"
+
+  "h12.io/socks"
 )
 
+// ProxyProvider resolves the reference of a provider, such as tor://1, to
+// the proxy url it stands for. The key keeps sticky assignments stable.
+type ProxyProvider interface {
+  Resolve(ref *url.URL, key string) (*url.URL, error)
+}
+
+// TorProvider maps the tor slots to the local tor socks ports.
+type TorProvider struct{}
+
+func (p *TorProvider) Resolve(ref *url.URL, key string) (*url.URL, error) {
+  slot, err := strconv.Atoi(ref.Host)
+  if err != nil || slot < 1 {
+    return nil, errors.New(fmt.Sprintf("tor slot %v not valid", ref.Host))
+  }
+  return &url.URL{
+    Scheme: "socks5",
+    Host:   fmt.Sprintf("127.0.0.1:%d", 2080+slot),
+  }, nil
+}
+
+var proxyProviders = map[string]ProxyProvider{
+  "tor": &TorProvider{},
+}
+
+// ParseProxy parses a proxy reference, an empty reference dials directly
+// and a bare number is the tor slot of the old slot column.
+func ParseProxy(reference string) (*url.URL, error) {
+  if reference == "" {
+    return nil, nil
+  }
+  if slot, err := strconv.Atoi(reference); err == nil {
+    if slot == 0 {
+      return nil, nil
+    }
+    reference = fmt.Sprintf("tor://%d", slot)
+  }
+  u, err := url.Parse(reference)
+  if err != nil {
+    return nil, errors.New("proxy reference not valid")
+  }
+  switch u.Scheme {
+  case "http", "https", "socks5", "socks5h", "tor", "group":
+  default:
+    return nil, errors.New(fmt.Sprintf("proxy scheme %v not supported", u.Scheme))
+  }
+  if u.Host == "" {
+    return nil, errors.New("proxy host can not be empty")
+  }
+  return u, nil
+}
+
+// ResolveProxy turns a provider reference into the proxy url it stands for,
+// proxy urls are kept as is.
+func ResolveProxy(reference string, key string) (*url.URL, error) {
+  u, err := ParseProxy(reference)
+  if err != nil || u == nil {
+    return nil, err
+  }
+  if provider, ok := proxyProviders[u.Scheme]; ok {
+    return provider.Resolve(u, key)
+  }
+  if u.Scheme == "group" {
+    return nil, errors.New(fmt.Sprintf("proxy group %v must be resolved", u.Host))
+  }
+  return u, nil
+}
+
+// ProxyName keeps the password of the proxy out of logs.
+func ProxyName(reference string) string {
+  u, err := ParseProxy(reference)
+  if err != nil {
+    return "invalid"
+  }
+  if u == nil {
+    return "direct"
+  }
+  return u.Redacted()
+}
+
+// ProxySession builds the dialer of a proxy reference, socks5 proxies are
+// dialed with their auth and http or https proxies are tunneled by CONNECT.
 type ProxySession struct {
-  Proxy string
+  Proxy   string
+  Timeout time.Duration
+}
+
+func NewProxySession(proxy string) *ProxySession {
+  return &ProxySession{
+    Proxy:   proxy,
+    Timeout: 30 * time.Second,
+  }
+}
+
+func (session *ProxySession) Dialer() (func(ctx context.Context, network, addr string) (net.Conn, error), error) {
+  dialer := &net.Dialer{
+    Timeout:   session.Timeout,
+    KeepAlive: 30 * time.Second,
+  }
+
+  u, err := ResolveProxy(session.Proxy, "")
+  if err != nil {
+    return nil, err
+  }
+  if u == nil {
+    return dialer.DialContext, nil
+  }
+
+  switch u.Scheme {
+  case "socks5", "socks5h":
+    proxy := *u
+    proxy.Scheme = "socks5"
+    q := proxy.Query()
+    if !q.Has("timeout") {
+      q.Set("timeout", session.Timeout.String())
+    }
+    proxy.RawQuery = q.Encode()
+    dial := socks.Dial(proxy.String())
+    return func(ctx context.Context, network, addr string) (net.Conn, error) {
+      return dial(network, addr)
+    }, nil
+  case "http", "https":
+    return func(ctx context.Context, network, addr string) (net.Conn, error) {
+      return session.connect(ctx, dialer, u, addr)
+    }, nil
+  }
+  return nil, errors.New(fmt.Sprintf("proxy scheme %v not supported", u.Scheme))
 }
 
-func (session *ProxySession) DialContext(ctx context.Context, net, addr string) (net.Conn, error) {
-  dialer := socks.Dial(session.Proxy)
-  return dialer(net, addr)
+func (session *ProxySession) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+  dial, err := session.Dialer()
+  if err != nil {
+    return nil, err
+  }
+  return dial(ctx, network, addr)
+}
+
+func (session *ProxySession) connect(
+  ctx context.Context,
+  dialer *net.Dialer,
+  proxy *url.URL,
+  addr string,
+) (conn net.Conn, err error) {
+  host := proxy.Host
+  if proxy.Port() == "" {
+    if proxy.Scheme == "https" {
+      host = net.JoinHostPort(proxy.Hostname(), "443")
+    } else {
+      host = net.JoinHostPort(proxy.Hostname(), "80")
+    }
+  }
+
+  raw, err := dialer.DialContext(ctx, "tcp", host)
+  if err != nil {
+    return
+  }
+  defer func() {
+    if err != nil {
+      raw.Close()
+    }
+  }()
+  conn = raw
+
+  if deadline, ok := ctx.Deadline(); ok {
+    conn.SetDeadline(deadline)
+  } else {
+    conn.SetDeadline(time.Now().Add(session.Timeout))
+  }
+
+  if proxy.Scheme == "https" {
+    tlsConn := tls.Client(conn, &tls.Config{
+      ServerName: proxy.Hostname(),
+    })
+    if err = tlsConn.HandshakeContext(ctx); err != nil {
+      return
+    }
+    conn = tlsConn
+  }
+
+  req := &http.Request{
+    Method: "CONNECT",
+    URL:    &url.URL{Opaque: addr},
+    Host:   addr,
+    Header: make(http.Header),
+  }
+  if proxy.User != nil {
+    password, _ := proxy.User.Password()
+    auth := base64.StdEncoding.EncodeToString([]byte(proxy.User.Username() + ":" + password))
+    req.Header.Set("Proxy-Authorization", "Basic "+auth)
+  }
+  if err = req.Write(conn); err != nil {
+    return
+  }
+
+  br := bufio.NewReader(conn)
+  resp, err := http.ReadResponse(br, req)
+  if err != nil {
+    return
+  }
+  resp.Body.Close()
+  if resp.StatusCode != http.StatusOK {
+    err = errors.New(
+      fmt.Sprintf("proxy %v connect failed: %v", proxy.Host, strings.TrimSpace(resp.Status)),
+    )
+    return nil, err
+  }
+  if br.Buffered() > 0 {
+    err = errors.New(fmt.Sprintf("proxy %v sent data after connect", proxy.Host))
+    return nil, err
+  }
+
+  conn.SetDeadline(time.Time{})
+  return
 }
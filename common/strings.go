@@ -0,0 +1,14 @@
+package common
+
+import (
+  "unicode/utf8"
+)
+
+// Truncate cuts the string to the size in characters, so that a multibyte
+// character is never split on the way to a varchar column.
+func Truncate(s string, size int) string {
+  if utf8.RuneCountInString(s) <= size {
+    return s
+  }
+  return string([]rune(s)[:size])
+}
@@ -0,0 +1,26 @@
+package common
+
+import (
+  "testing"
+  "unicode/utf8"
+)
+
+func TestTruncate(t *testing.T) {
+  tests := []struct {
+    s    string
+    size int
+    want string
+  }{
+    {"", 3, ""},
+    {"abc", 3, "abc"},
+    {"abcd", 3, "abc"},
+    {"レート制限", 3, "レート"},
+    {"aé", 1, "a"},
+  }
+  for _, tt := range tests {
+    got := Truncate(tt.s, tt.size)
+    if got != tt.want || !utf8.ValidString(got) {
+      t.Errorf("Truncate(%q, %v) = %q, want %q", tt.s, tt.size, got, tt.want)
+    }
+  }
+}
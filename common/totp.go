@@ -0,0 +1,30 @@
+package common
+
+import (
+  "crypto/hmac"
+  "crypto/sha1"
+  "encoding/base32"
+  "encoding/binary"
+  "fmt"
+  "strings"
+)
+
+// GenerateTotp returns the six digits code of the base32 secret for the
+// unix timestamp, as authenticator apps do with 30 seconds steps.
+func GenerateTotp(secret string, timestamp int64) (string, error) {
+  secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+  key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.TrimRight(secret, "="))
+  if err != nil {
+    return "", err
+  }
+
+  counter := make([]byte, 8)
+  binary.BigEndian.PutUint64(counter, uint64(timestamp/30))
+  mac := hmac.New(sha1.New, key)
+  mac.Write(counter)
+  sum := mac.Sum(nil)
+
+  offset := sum[len(sum)-1] & 0x0f
+  code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+  return fmt.Sprintf("%06d", code%1000000), nil
+}
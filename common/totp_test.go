@@ -0,0 +1,41 @@
+package common
+
+import (
+  "testing"
+)
+
+// The SHA1 vectors of RFC 6238 appendix B, truncated to the 6 digits the
+// login flow sends.
+func TestGenerateTotp(t *testing.T) {
+  secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+  tests := []struct {
+    timestamp int64
+    code      string
+  }{
+    {59, "287082"},
+    {1111111109, "081804"},
+    {1111111111, "050471"},
+    {1234567890, "005924"},
+    {2000000000, "279037"},
+    {20000000000, "353130"},
+  }
+  for _, tt := range tests {
+    code, err := GenerateTotp(secret, tt.timestamp)
+    if err != nil {
+      t.Fatal(err)
+    }
+    if code != tt.code {
+      t.Errorf("GenerateTotp(%v) = %v, want %v", tt.timestamp, code, tt.code)
+    }
+  }
+}
+
+func TestGenerateTotpSecret(t *testing.T) {
+  code, err := GenerateTotp("gezd gnbv gy3t qojq gezd gnbv gy3t qojq", 59)
+  if err != nil || code != "287082" {
+    t.Errorf("GenerateTotp = %v %v, want 287082", code, err)
+  }
+  if _, err := GenerateTotp("not base32!", 59); err == nil {
+    t.Error("GenerateTotp accepted a secret which is not base32")
+  }
+}
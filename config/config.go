@@ -5,16 +5,64 @@ const (
   REDIS_KEY_TASKS_POSTS_TARGET               = "twitter:scraper:tasks:posts:target"
   REDIS_KEY_TASKS_REPLIES_TARGET             = "twitter:scraper:tasks:replies:target"
   REDIS_KEY_TASKS_USERS_POSTS_TARGET         = "twitter:scraper:tasks:users:posts:target"
+  REDIS_KEY_TASKS_SEARCH_TARGET              = "twitter:scraper:tasks:search:target"
+  REDIS_KEY_TASKS_FOLLOWS_TARGET             = "twitter:scraper:tasks:follows:target"
   REDIS_KEY_CLOUDS_SYNCING_MEDIA_PHOTOS      = "twitter:scraper:clouds:syncing:media:photos"
   REDIS_KEY_CLOUDS_SYNCING_MEDIA_VIDEOS      = "twitter:scraper:clouds:syncing:media:videos"
   REDIS_KEY_POSTS_COUNT                      = "twitter:scraper:posts:count:%s"
   REDIS_KEY_REPLIES_COUNT                    = "twitter:scraper:replies:count:%s"
   REDIS_KEY_MEDIA_VIDEOS                     = "twitter:scraper:media:videos:%s:%s"
   REDIS_KEY_MEDIA_PHOTOS                     = "twitter:scraper:media:photos:%s:%s"
+  REDIS_KEY_SESSIONS_RATE_LIMITS             = "twitter:scraper:sessions:%v:limits:%v"
+  REDIS_KEY_SESSIONS_HEALTH                  = "twitter:scraper:sessions:%v:health:%v"
+  REDIS_KEY_PROXIES_ROTATION                 = "twitter:scraper:proxies:%v:rotation"
+  REDIS_KEY_SCRAPERS_DRIFT                   = "twitter:scraper:drift:%v"
+  REDIS_KEY_SCRAPER_OPERATIONS               = "twitter:scraper:operations"
+  REDIS_KEY_SCRAPER_OPERATIONS_PREVIOUS      = "twitter:scraper:operations:previous"
+  REDIS_KEY_SCRAPER_FEATURES                 = "twitter:scraper:features"
   SCRAPERS_POSTS_TARGET_LIMIT                = 20
   SCRAPERS_REPLIES_TARGET_LIMIT              = 50
   SCRAPERS_USERS_POSTS_TARGET_LIMIT          = 50
+  SCRAPERS_SEARCH_TARGET_LIMIT               = 20
+  SCRAPERS_FOLLOWS_TARGET_LIMIT              = 20
+  SCRAPERS_REPLIES_MODULES_LIMIT             = 5
+  SCRAPERS_REPLIES_THREAD_LIMIT              = 2000
+  SCRAPERS_METRICS_CURVE_LIMIT               = 1000
+  SCRAPERS_REVISIONS_LIMIT                   = 100
+  SCRAPERS_POSTS_VERIFY_INTERVAL             = 604800000
+  SCRAPERS_POSTS_VERIFY_LIMIT                = 20
+  SESSIONS_SCHEDULE_LIMIT                    = 50
+  SESSIONS_RATE_LIMIT_PENALTY                = 900
+  SESSIONS_TYPE_USER                         = 0
+  SESSIONS_TYPE_GUEST                        = 1
+  SESSIONS_GUESTS_LIMIT                      = 3
+  SESSIONS_GUEST_TOKEN_TTL                   = 7200000000
+  SESSIONS_RELOGIN_INTERVAL                  = 3600000000
+  SESSIONS_RELOGIN_FAILURES_LIMIT            = 5
+  SESSIONS_HEALTH_DAYS                       = 7
+  SESSIONS_EVENT_STATUS                      = "status"
+  SESSIONS_EVENT_HTTP                        = "http"
+  SESSIONS_EVENT_NETWORK                     = "network"
+  SESSIONS_EVENT_FLUSH                       = "flush"
+  SESSIONS_EVENT_PROBE                       = "probe"
+  PROXIES_PROBE_URL                          = "https://api.ipify.org?format=json"
+  PROXIES_CHECK_TIMEOUT                      = 15
+  PROXIES_CHECK_CONCURRENCY                  = 10
+  PROXIES_SCORE_WEIGHT                       = 0.3
+  PROXIES_LATENCY_TARGET                     = 1000
+  PROXIES_LATENCY_LIMIT                      = 10000
+  PROXIES_QUARANTINE_FAILURES                = 3
+  PROXIES_QUARANTINE_SCORE                   = 30
+  PROXIES_QUARANTINE_INTERVAL                = 3600000000
+  PROXIES_SCORES_LIMIT                       = 100
+  LOGIN_FLOW_STEPS_LIMIT                     = 20
+  HTTP_RETRIES_LIMIT                         = 3
+  HTTP_RETRY_BACKOFF                         = 500
+  HTTP_RETRY_BACKOFF_LIMIT                   = 8000
+  TWITTER_LOGIN_URL                          = "https://api.twitter.com"
+  TWITTER_BEARER_TOKEN                       = "AAAAAAAAAAAAAAAAAAAAANRILgAAAAAAnNwIzUejRCOuH5E6I8xnZz4puTs%3D1Zv7ttfk8LF81IUq16cHjhLTvJu4FA33AGWWjCpTnA"
   SCRAPERS_CURSOR_WAITING_TIMEOUT            = 300000
+  SCRAPERS_FOLLOWS_FLUSH_INTERVAL            = 86400000000
   CLOUDS_SYNCING_MEDIA_PHOTOS_LIMIT          = 200
   CLOUDS_SYNCING_MEDIA_VIDEOS_LIMIT          = 50
   TASK_ACTION_SCRAPERS_POSTS                 = 1
@@ -23,21 +71,49 @@ const (
   TASK_ACTION_SCRAPERS_MEDIA_POSTS           = 4
   TASK_ACTION_SCRAPERS_MEDIA_REPLIES         = 5
   TASK_ACTION_SCRAPERS_USERS_POSTS           = 6
+  TASK_ACTION_SCRAPERS_SEARCH                = 7
+  TASK_ACTION_SCRAPERS_FOLLOWS               = 8
+  TASK_STATUS_PARSE_ERROR                    = 5
+  TWEET_ENTITY_HASHTAG                       = 1
+  TWEET_ENTITY_MENTION                       = 2
+  TWEET_ENTITY_URL                           = 3
+  TWEET_ENTITY_CASHTAG                       = 4
+  TWEET_ENTITY_MEDIA                         = 5
+  POST_KIND_ORIGINAL                         = 1
+  POST_KIND_RETWEET                          = 2
+  POST_KIND_QUOTE                            = 3
+  POST_KIND_REPLY                            = 4
+  POST_REMOVED_DELETED                       = 1
+  POST_REMOVED_UNAVAILABLE                   = 2
+  POST_REMOVED_WITHHELD                      = 3
+  POST_STATUS_REFERENCED                     = 6
   NATS_POSTS_CREATE                          = "twitter:posts:create"
+  NATS_POSTS_REMOVE                          = "twitter:posts:remove"
   NATS_REPLIES_CREATE                        = "twitter:replies:create"
   NATS_USERS_CREATE                          = "twitter:users:create"
+  NATS_SCRAPERS_DRIFT                        = "twitter:scrapers:drift"
   ASYNQ_QUEUE_SESSIONS                       = "twitter:sessions"
   ASYNQ_QUEUE_SCRAPERS_POSTS                 = "twitter:scrapers:posts"
   ASYNQ_QUEUE_SCRAPERS_REPLIES               = "twitter:scrapers:replies"
   ASYNQ_QUEUE_SCRAPERS_USERS_POSTS           = "twitter:scrapers:users:posts"
+  ASYNQ_QUEUE_SCRAPERS_SEARCH                = "twitter:scrapers:search"
+  ASYNQ_QUEUE_SCRAPERS_FOLLOWS               = "twitter:scrapers:follows"
   ASYNQ_JOBS_SESSIONS_FLUSH                  = "twitter:sessions:flush"
+  ASYNQ_JOBS_SESSIONS_RELOGIN                = "twitter:sessions:relogin"
+  ASYNQ_JOBS_SESSIONS_PROBE                  = "twitter:sessions:probe"
+  ASYNQ_JOBS_PROXIES_CHECK                   = "twitter:proxies:check"
   ASYNQ_JOBS_SCRAPERS_POSTS_FLUSH            = "twitter:scrapers:posts:flush"
   ASYNQ_JOBS_SCRAPERS_POSTS_PROCESS          = "twitter:scrapers:posts:process"
+  ASYNQ_JOBS_SCRAPERS_POSTS_VERIFY           = "twitter:scrapers:posts:verify"
   ASYNQ_JOBS_SCRAPERS_REPLIES_INIT           = "twitter:scrapers:replies:init"
   ASYNQ_JOBS_SCRAPERS_REPLIES_FLUSH          = "twitter:scrapers:replies:flush"
   ASYNQ_JOBS_SCRAPERS_REPLIES_PROCESS        = "twitter:scrapers:replies:process"
   ASYNQ_JOBS_SCRAPERS_USERS_POSTS_FLUSH      = "twitter:scrapers:users:posts:flush"
   ASYNQ_JOBS_SCRAPERS_USERS_POSTS_PROCESS    = "twitter:scrapers:users:posts:process"
+  ASYNQ_JOBS_SCRAPERS_SEARCH_FLUSH           = "twitter:scrapers:search:flush"
+  ASYNQ_JOBS_SCRAPERS_SEARCH_PROCESS         = "twitter:scrapers:search:process"
+  ASYNQ_JOBS_SCRAPERS_FOLLOWS_FLUSH          = "twitter:scrapers:follows:flush"
+  ASYNQ_JOBS_SCRAPERS_FOLLOWS_PROCESS        = "twitter:scrapers:follows:process"
   LOCKS_TASKS_POSTS_FLUSH                    = "locks:twitter:tasks:posts:flush:%v"
   LOCKS_TASKS_REPLIES_FLUSH                  = "locks:twitter:tasks:replies:flush:%v"
   LOCKS_TASKS_CLOUDS_MEDIA_PHOTOS_SYNC       = "locks:twitter:tasks:clouds:media:photos:sync:%v"
@@ -50,12 +126,19 @@ const (
   LOCKS_TASKS_SCRAPERS_MEDIA_REPLIES_APPLY   = "locks:twitter:tasks:scrapers:media:replies:apply:%v"
   LOCKS_TASKS_SCRAPERS_POSTS_FLUSH           = "locks:twitter:tasks:scrapers:posts:flush:%v"
   LOCKS_TASKS_SCRAPERS_POSTS_PROCESS         = "locks:twitter:tasks:scrapers:posts:process:%v"
+  LOCKS_TASKS_SCRAPERS_POSTS_VERIFY          = "locks:twitter:tasks:scrapers:posts:verify:%v"
   LOCKS_TASKS_SCRAPERS_USERS_POSTS_FLUSH     = "locks:twitter:tasks:scrapers:users:posts:flush:%v"
   LOCKS_TASKS_SCRAPERS_USERS_POSTS_PROCESS   = "locks:twitter:tasks:scrapers:users:posts:process:%v"
+  LOCKS_TASKS_SCRAPERS_SEARCH_FLUSH          = "locks:twitter:tasks:scrapers:search:flush:%v"
+  LOCKS_TASKS_SCRAPERS_SEARCH_PROCESS        = "locks:twitter:tasks:scrapers:search:process:%v"
+  LOCKS_TASKS_SCRAPERS_FOLLOWS_FLUSH         = "locks:twitter:tasks:scrapers:follows:flush:%v"
+  LOCKS_TASKS_SCRAPERS_FOLLOWS_PROCESS       = "locks:twitter:tasks:scrapers:follows:process:%v"
   LOCKS_TASKS_SCRAPERS_REPLIES_INIT          = "locks:twitter:tasks:scrapers:replies:init:%v"
   LOCKS_TASKS_SCRAPERS_REPLIES_FLUSH         = "locks:twitter:tasks:scrapers:replies:flush:%v"
   LOCKS_TASKS_SCRAPERS_REPLIES_PROCESS       = "locks:twitter:tasks:scrapers:replies:process:%v"
   LOCKS_TASKS_SCRAPERS_MEDIA_USERS_PROCESS   = "locks:twitter:tasks:scrapers:media:users:process:%v"
   LOCKS_TASKS_SCRAPERS_MEDIA_POSTS_PROCESS   = "locks:twitter:tasks:scrapers:media:posts:process:%v"
   LOCKS_TASKS_SCRAPERS_MEDIA_REPLIES_PROCESS = "locks:twitter:tasks:scrapers:media:replies:process:%v"
+  LOCKS_SESSIONS_RELOGIN                     = "locks:twitter:sessions:relogin:%v"
+  LOCKS_PROXIES_CHECK                        = "locks:twitter:proxies:check:%v"
 )
@@ -33,7 +33,9 @@ func main() {
     Commands: []*cli.Command{
       commands.NewDbCommand(),
       commands.NewSessionsCommand(),
+      commands.NewProxiesCommand(),
       commands.NewTokenCommand(),
+      commands.NewOperationsCommand(),
       commands.NewCloudsCommand(),
       commands.NewScrapersCommand(),
       commands.NewMediaCommand(),
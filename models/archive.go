@@ -0,0 +1,19 @@
+package models
+
+import (
+  "time"
+)
+
+type Archive struct {
+  ID        string    `gorm:"size:20;primaryKey"`
+  TaskID    string    `gorm:"size:20;not null;index"`
+  SessionID string    `gorm:"size:20;not null"`
+  Endpoint  string    `gorm:"size:50;not null;index:idx_twitter_archives,priority:1"`
+  Content   []byte    `gorm:"not null"`
+  Timestamp int64     `gorm:"not null;index:idx_twitter_archives,priority:2"`
+  CreatedAt time.Time `gorm:"not null"`
+}
+
+func (m *Archive) TableName() string {
+  return "twitter_archives"
+}
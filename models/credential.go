@@ -0,0 +1,25 @@
+package models
+
+import (
+  "time"
+
+  "scraper.local/twitter-scraper/common"
+)
+
+type Credential struct {
+  ID                  string        `gorm:"size:20;primaryKey"`
+  Account             string        `gorm:"size:50;not null;uniqueIndex"`
+  Password            common.Secret `gorm:"size:1000;not null"`
+  AlternateIdentifier string        `gorm:"size:155;not null"`
+  TotpSecret          common.Secret `gorm:"size:1000;not null"`
+  LoggedInAt          int64         `gorm:"not null"`
+  FailedAt            int64         `gorm:"not null"`
+  Failures            int           `gorm:"not null"`
+  Message             string        `gorm:"size:500;not null"`
+  CreatedAt           time.Time     `gorm:"not null"`
+  UpdatedAt           time.Time     `gorm:"not null"`
+}
+
+func (m *Credential) TableName() string {
+  return "twitter_credentials"
+}
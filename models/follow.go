@@ -0,0 +1,21 @@
+package models
+
+import (
+  "time"
+)
+
+type Follow struct {
+  ID           string    `gorm:"size:20;primaryKey"`
+  UserID       string    `gorm:"size:20;not null;uniqueIndex:unq_twitter_follows,priority:1;index:idx_twitter_follows_users,priority:1"`
+  FollowerID   string    `gorm:"size:20;not null;uniqueIndex:unq_twitter_follows,priority:2;index:idx_twitter_follows_followers,priority:1"`
+  FirstSeenAt  int64     `gorm:"not null"`
+  LastSeenAt   int64     `gorm:"not null"`
+  UnfollowedAt int64     `gorm:"not null"`
+  Status       int       `gorm:"not null;index:idx_twitter_follows_users,priority:2;index:idx_twitter_follows_followers,priority:2"`
+  CreatedAt    time.Time `gorm:"not null"`
+  UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (m *Follow) TableName() string {
+  return "twitter_follows"
+}
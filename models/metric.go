@@ -0,0 +1,22 @@
+package models
+
+import (
+  "time"
+)
+
+type Metric struct {
+  ID            string    `gorm:"size:20;primaryKey"`
+  TwitterID     int64     `gorm:"not null;index:idx_twitter_metrics,priority:1"`
+  FavoriteCount int       `gorm:"not null"`
+  RetweetCount  int       `gorm:"not null"`
+  ReplyCount    int       `gorm:"not null"`
+  QuoteCount    int       `gorm:"not null"`
+  BookmarkCount int       `gorm:"not null"`
+  ViewCount     int64     `gorm:"not null"`
+  Timestamp     int64     `gorm:"not null;index:idx_twitter_metrics,priority:2"`
+  CreatedAt     time.Time `gorm:"not null"`
+}
+
+func (m *Metric) TableName() string {
+  return "twitter_metrics"
+}
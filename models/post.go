@@ -6,16 +6,30 @@ import (
 )
 
 type Post struct {
-  ID        string            `gorm:"size:20;primaryKey"`
-  UserID    string            `gorm:"size:20;not null;index:idx_twitter_users_posts,priority:1"`
-  TwitterID int64             `gorm:"not null;uniqueIndex"`
-  StatusID  int64             `gorm:"not null"`
-  Content   string            `gorm:"size:5000;not null"`
-  Media     datatypes.JSONMap `gorm:"not null"`
-  Timestamp int64             `gorm:"not null;index:idx_twitter_posts,priority:1"`
-  Status    int               `gorm:"not null;index:idx_twitter_posts,priority:2;index:idx_twitter_users_posts,priority:2"`
-  CreatedAt time.Time         `gorm:"not null;index:idx_twitter_users_posts,priority:3"`
-  UpdatedAt time.Time         `gorm:"not null"`
+  ID            string            `gorm:"size:20;primaryKey"`
+  UserID        string            `gorm:"size:20;not null;index:idx_twitter_users_posts,priority:1"`
+  TwitterID     int64             `gorm:"not null;uniqueIndex"`
+  StatusID      int64             `gorm:"not null"`
+  Kind          int               `gorm:"not null"`
+  ReferenceID   string            `gorm:"size:20;not null;index"`
+  Content       string            `gorm:"size:25000;not null"`
+  Media         datatypes.JSONMap `gorm:"not null"`
+  InitialID     int64             `gorm:"not null;index"`
+  EditTweetIDs  string            `gorm:"size:500;not null"`
+  EditableUntil int64             `gorm:"not null"`
+  FavoriteCount int               `gorm:"not null"`
+  RetweetCount  int               `gorm:"not null"`
+  ReplyCount    int               `gorm:"not null"`
+  QuoteCount    int               `gorm:"not null"`
+  BookmarkCount int               `gorm:"not null"`
+  ViewCount     int64             `gorm:"not null"`
+  VerifiedAt    int64             `gorm:"not null;index"`
+  RemovedReason int               `gorm:"not null"`
+  RemovedAt     int64             `gorm:"not null"`
+  Timestamp     int64             `gorm:"not null;index:idx_twitter_posts,priority:1"`
+  Status        int               `gorm:"not null;index:idx_twitter_posts,priority:2;index:idx_twitter_users_posts,priority:2"`
+  CreatedAt     time.Time         `gorm:"not null;index:idx_twitter_users_posts,priority:3"`
+  UpdatedAt     time.Time         `gorm:"not null"`
 }
 
 func (m *Post) TableName() string {
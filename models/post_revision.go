@@ -0,0 +1,20 @@
+package models
+
+import (
+  "gorm.io/datatypes"
+  "time"
+)
+
+type PostRevision struct {
+  ID        string            `gorm:"size:20;primaryKey"`
+  PostID    string            `gorm:"size:20;not null;index:idx_twitter_post_revisions,priority:1"`
+  TwitterID int64             `gorm:"not null"`
+  Content   string            `gorm:"size:25000;not null"`
+  Media     datatypes.JSONMap `gorm:"not null"`
+  Timestamp int64             `gorm:"not null;index:idx_twitter_post_revisions,priority:2"`
+  CreatedAt time.Time         `gorm:"not null"`
+}
+
+func (m *PostRevision) TableName() string {
+  return "twitter_post_revisions"
+}
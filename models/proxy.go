@@ -0,0 +1,28 @@
+package models
+
+import (
+  "time"
+
+  "scraper.local/twitter-scraper/common"
+)
+
+type Proxy struct {
+  ID               string        `gorm:"size:20;primaryKey"`
+  GroupName        string        `gorm:"size:50;not null;index"`
+  Url              common.Secret `gorm:"size:1000;not null"`
+  Latency          int64         `gorm:"not null;default:0"`
+  ExitIp           string        `gorm:"size:45;not null;default:''"`
+  Score            float64       `gorm:"not null;default:0"`
+  Checks           int           `gorm:"not null;default:0"`
+  Failures         int           `gorm:"not null;default:0"`
+  Message          string        `gorm:"size:255;not null;default:''"`
+  CheckedAt        int64         `gorm:"not null;default:0"`
+  QuarantinedUntil int64         `gorm:"not null;default:0"`
+  Status           int           `gorm:"not null"`
+  CreatedAt        time.Time     `gorm:"not null"`
+  UpdatedAt        time.Time     `gorm:"not null"`
+}
+
+func (m *Proxy) TableName() string {
+  return "twitter_proxies"
+}
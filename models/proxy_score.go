@@ -0,0 +1,21 @@
+package models
+
+import (
+  "time"
+)
+
+type ProxyScore struct {
+  ID        string    `gorm:"size:20;primaryKey"`
+  ProxyID   string    `gorm:"size:20;not null;index:idx_twitter_proxy_scores,priority:1"`
+  Latency   int64     `gorm:"not null"`
+  ExitIp    string    `gorm:"size:45;not null"`
+  Success   bool      `gorm:"not null"`
+  Score     float64   `gorm:"not null"`
+  Reason    string    `gorm:"size:255;not null"`
+  Timestamp int64     `gorm:"not null;index:idx_twitter_proxy_scores,priority:2"`
+  CreatedAt time.Time `gorm:"not null"`
+}
+
+func (m *ProxyScore) TableName() string {
+  return "twitter_proxy_scores"
+}
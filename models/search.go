@@ -0,0 +1,17 @@
+package models
+
+import (
+  "time"
+)
+
+type SearchPost struct {
+  ID        string    `gorm:"size:20;primaryKey"`
+  TaskID    string    `gorm:"size:20;not null;uniqueIndex:unq_twitter_search_posts,priority:1"`
+  PostID    string    `gorm:"size:20;not null;uniqueIndex:unq_twitter_search_posts,priority:2;index"`
+  Timestamp int64     `gorm:"not null"`
+  CreatedAt time.Time `gorm:"not null"`
+}
+
+func (m *SearchPost) TableName() string {
+  return "twitter_search_posts"
+}
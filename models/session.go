@@ -1,25 +1,29 @@
 package models
 
 import (
-  "gorm.io/datatypes"
   "time"
+
+  "scraper.local/twitter-scraper/common"
 )
 
 type Session struct {
-  ID          string            `gorm:"size:20;primaryKey"`
-  Account     string            `gorm:"size:50;not null;uniqueIndex"`
-  TwitterID   int64             `gorm:"not null"`
-  Node        int               `gorm:"not null"`
-  Agent       string            `gorm:"size:155;not null"`
-  Cookie      string            `gorm:"size:2000;not null"`
-  Slot        int               `gorm:"not null"`
-  Data        datatypes.JSONMap `gorm:"not null"`
-  FlushedAt   int64             `gorm:"not null"`
-  UnblockedAt int64             `gorm:"not null"`
-  Timestamp   int64             `gorm:"not null"`
-  Status      int               `gorm:"not null"`
-  CreatedAt   time.Time         `gorm:"not null"`
-  UpdatedAt   time.Time         `gorm:"not null"`
+  ID          string           `gorm:"size:20;primaryKey"`
+  Account     string           `gorm:"size:50;not null;uniqueIndex"`
+  TwitterID   int64            `gorm:"not null"`
+  Node        int              `gorm:"not null"`
+  Agent       string           `gorm:"size:155;not null"`
+  Cookie      common.Secret    `gorm:"size:4000;not null"`
+  Proxy       common.Secret    `gorm:"size:1000;not null;default:''"`
+  Type        int              `gorm:"not null;default:0"`
+  Data        common.SecretMap `gorm:"not null"`
+  FlushedAt   int64            `gorm:"not null"`
+  UnblockedAt int64            `gorm:"not null"`
+  ProbedAt    int64            `gorm:"not null;default:0"`
+  Timestamp   int64            `gorm:"not null"`
+  Status      int              `gorm:"not null"`
+  LastError   string           `gorm:"size:255;not null;default:''"`
+  CreatedAt   time.Time        `gorm:"not null"`
+  UpdatedAt   time.Time        `gorm:"not null"`
 }
 
 func (m *Session) TableName() string {
@@ -0,0 +1,22 @@
+package models
+
+import (
+  "time"
+)
+
+type SessionEvent struct {
+  ID        string    `gorm:"size:20;primaryKey"`
+  SessionID string    `gorm:"size:20;not null;index:idx_twitter_session_events,priority:1"`
+  Kind      string    `gorm:"size:20;not null"`
+  Operation string    `gorm:"size:100;not null"`
+  Code      int       `gorm:"not null"`
+  Status    int       `gorm:"not null"`
+  Success   bool      `gorm:"not null"`
+  Reason    string    `gorm:"size:255;not null"`
+  Timestamp int64     `gorm:"not null;index:idx_twitter_session_events,priority:2;index"`
+  CreatedAt time.Time `gorm:"not null"`
+}
+
+func (m *SessionEvent) TableName() string {
+  return "twitter_session_events"
+}
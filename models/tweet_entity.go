@@ -0,0 +1,20 @@
+package models
+
+import (
+  "time"
+)
+
+type TweetEntity struct {
+  ID        string    `gorm:"size:20;primaryKey"`
+  TwitterID int64     `gorm:"not null;uniqueIndex:unq_twitter_tweet_entities,priority:1"`
+  Type      int       `gorm:"not null;uniqueIndex:unq_twitter_tweet_entities,priority:2;index:idx_twitter_tweet_entities,priority:1"`
+  Start     int       `gorm:"not null;uniqueIndex:unq_twitter_tweet_entities,priority:3"`
+  End       int       `gorm:"not null"`
+  Value     string    `gorm:"size:1000;not null;index:idx_twitter_tweet_entities,priority:2"`
+  Url       string    `gorm:"size:100;not null"`
+  CreatedAt time.Time `gorm:"not null"`
+}
+
+func (m *TweetEntity) TableName() string {
+  return "twitter_tweet_entities"
+}
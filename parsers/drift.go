@@ -0,0 +1,70 @@
+package parsers
+
+import (
+  "errors"
+  "fmt"
+)
+
+// DriftError tells an unrecognized response apart from an empty page, the
+// scrapers keep the task cursor instead of finishing the task.
+type DriftError struct {
+  Reason  string
+  Unknown map[string]int
+}
+
+func (e *DriftError) Error() string {
+  return fmt.Sprintf("unrecognized response: %v %v", e.Reason, e.Unknown)
+}
+
+func IsDrift(err error) bool {
+  var driftErr *DriftError
+  return errors.As(err, &driftErr)
+}
+
+func IsKnownTweet(typename string) bool {
+  switch typename {
+  case "Tweet", "TweetTombstone", "TweetUnavailable":
+    return true
+  }
+  return false
+}
+
+func IsKnownUser(typename string) bool {
+  switch typename {
+  case "User", "UserUnavailable":
+    return true
+  }
+  return false
+}
+
+// IsEmpty reports a page without any recognized tweet or user, cursors
+// alone are what the last page of a timeline looks like.
+func (t *Timeline) IsEmpty() bool {
+  for _, entry := range t.Entries {
+    if entry.Tweet != nil && IsKnownTweet(entry.Tweet.Typename) {
+      return false
+    }
+    if entry.User != nil && IsKnownUser(entry.User.Typename) {
+      return false
+    }
+  }
+  return true
+}
+
+// Validate checks the timeline was found at the expected path and that its
+// content was recognized, the timeline of an unavailable user is expected.
+func (t *Timeline) Validate() error {
+  if t.Unavailable != "" {
+    return nil
+  }
+  if !t.Exists {
+    return &DriftError{Reason: "timeline not found", Unknown: t.Unknown}
+  }
+  if t.Instructions == 0 {
+    return &DriftError{Reason: "instructions not found", Unknown: t.Unknown}
+  }
+  if t.IsEmpty() && len(t.Unknown) > 0 {
+    return &DriftError{Reason: "entries not recognized", Unknown: t.Unknown}
+  }
+  return nil
+}
@@ -0,0 +1,104 @@
+package parsers
+
+type Timeline struct {
+  Exists       bool
+  Instructions int
+  Entries      []*Entry
+  Unknown      map[string]int
+  Unavailable  string
+}
+
+type Entry struct {
+  EntryID  string
+  ModuleID string
+  Pinned   bool
+  Tweet    *Tweet
+  User     *User
+  Cursor   *Cursor
+}
+
+type Cursor struct {
+  Type  string
+  Value string
+}
+
+type Tweet struct {
+  Typename            string
+  Reason              string
+  TwitterID           int64
+  User                *User
+  Content             string
+  QuotedStatusID      int64
+  InReplyToStatusID   int64
+  ConversationID      int64
+  Retweeted           *Tweet
+  Quoted              *Tweet
+  Promoted            bool
+  Media               *Media
+  Metrics             *Metrics
+  Entities            []*Entity
+  EditControl         *EditControl
+  WithheldInCountries []string
+  Timestamp           int64
+}
+
+type User struct {
+  Typename        string
+  Reason          string
+  UserID          int64
+  Account         string
+  Name            string
+  Description     string
+  Avatar          string
+  FavouritesCount int
+  FollowersCount  int
+  FriendsCount    int
+  ListedCount     int
+  MediaCount      int
+  Timestamp       int64
+}
+
+type Media struct {
+  Photos []*Photo `json:"photos"`
+  Videos []*Video `json:"videos"`
+}
+
+type Photo struct {
+  Url string `json:"url"`
+}
+
+type Video struct {
+  Cover          string          `json:"cover"`
+  AspectRatio    []int           `json:"aspect_ratio"`
+  DurationMillis int             `json:"duration_millis"`
+  Variants       []*VideoVariant `json:"variants"`
+}
+
+type VideoVariant struct {
+  Bitrate     int    `json:"bitrate"`
+  ContentType string `json:"content_type"`
+  Url         string `json:"url"`
+}
+
+type Metrics struct {
+  FavoriteCount int
+  RetweetCount  int
+  ReplyCount    int
+  QuoteCount    int
+  BookmarkCount int
+  ViewCount     int64
+}
+
+type Entity struct {
+  Type  int
+  Start int
+  End   int
+  Value string
+  Url   string
+}
+
+type EditControl struct {
+  InitialID     int64
+  EditTweetIDs  []int64
+  EditableUntil int64
+}
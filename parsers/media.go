@@ -0,0 +1,48 @@
+package parsers
+
+import (
+  "github.com/tidwall/gjson"
+)
+
+// ParseMedia reads extended_entities, entities.media only keeps the first
+// photo of a tweet.
+func ParseMedia(s gjson.Result) *Media {
+  entities := s.Get("legacy.extended_entities.media")
+  if !entities.Exists() {
+    entities = s.Get("legacy.entities.media")
+  }
+
+  media := &Media{}
+  entities.ForEach(func(_, s gjson.Result) bool {
+    switch s.Get("type").Str {
+    case "photo":
+      media.Photos = append(media.Photos, &Photo{
+        Url: s.Get("media_url_https").Str,
+      })
+    case "video", "animated_gif":
+      video := &Video{
+        Cover:          s.Get("media_url_https").Str,
+        DurationMillis: int(s.Get("video_info.duration_millis").Int()),
+      }
+      s.Get("video_info.aspect_ratio").ForEach(func(_, s gjson.Result) bool {
+        video.AspectRatio = append(video.AspectRatio, int(s.Int()))
+        return true
+      })
+      s.Get("video_info.variants").ForEach(func(_, s gjson.Result) bool {
+        video.Variants = append(video.Variants, &VideoVariant{
+          Bitrate:     int(s.Get("bitrate").Int()),
+          ContentType: s.Get("content_type").Str,
+          Url:         s.Get("url").Str,
+        })
+        return true
+      })
+      media.Videos = append(media.Videos, video)
+    }
+    return true
+  })
+  return media
+}
+
+func (m *Media) IsEmpty() bool {
+  return m.Photos == nil && m.Videos == nil
+}
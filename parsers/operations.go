@@ -0,0 +1,57 @@
+package parsers
+
+import (
+  "regexp"
+  "strings"
+)
+
+type Operation struct {
+  QueryID         string   `json:"query_id"`
+  OperationName   string   `json:"operation_name"`
+  OperationType   string   `json:"operation_type"`
+  FeatureSwitches []string `json:"feature_switches"`
+  FieldToggles    []string `json:"field_toggles"`
+}
+
+var (
+  operationPattern = regexp.MustCompile(
+    `queryId:"([a-zA-Z0-9-_]*)",operationName:"([a-zA-Z0-9_]*)",operationType:"([a-zA-Z]*)",metadata:\{featureSwitches:\[([^\]]*)\](?:,fieldToggles:\[([^\]]*)\])?`,
+  )
+  switchPattern = regexp.MustCompile(`"([a-z0-9_]+)":\{"value":(true|false)\}`)
+)
+
+// ParseOperations reads every graphql operation exported by the client
+// bundle, later definitions of the same operation win.
+func ParseOperations(content string) map[string]*Operation {
+  operations := make(map[string]*Operation)
+  for _, matches := range operationPattern.FindAllStringSubmatch(content, -1) {
+    operations[matches[2]] = &Operation{
+      QueryID:         matches[1],
+      OperationName:   matches[2],
+      OperationType:   matches[3],
+      FeatureSwitches: parseNames(matches[4]),
+      FieldToggles:    parseNames(matches[5]),
+    }
+  }
+  return operations
+}
+
+// ParseFeatureSwitches reads the boolean feature switches of the initial state
+// embedded in the web page.
+func ParseFeatureSwitches(content string) map[string]bool {
+  switches := make(map[string]bool)
+  for _, matches := range switchPattern.FindAllStringSubmatch(content, -1) {
+    switches[matches[1]] = matches[2] == "true"
+  }
+  return switches
+}
+
+func parseNames(content string) (names []string) {
+  for _, name := range strings.Split(content, ",") {
+    name = strings.Trim(name, `" `)
+    if name != "" {
+      names = append(names, name)
+    }
+  }
+  return
+}
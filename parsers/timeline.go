@@ -0,0 +1,166 @@
+package parsers
+
+import (
+  "github.com/tidwall/gjson"
+)
+
+// ParseTimeline walks the instructions of any GraphQL timeline, entries of
+// modules keep the module entry id so conversations can be grouped.
+func ParseTimeline(container gjson.Result) *Timeline {
+  timeline := &Timeline{
+    Exists:  container.Exists(),
+    Unknown: make(map[string]int),
+  }
+  container.Get("instructions").ForEach(func(_, s gjson.Result) bool {
+    timeline.Instructions++
+    switch s.Get("type").Str {
+    case "TimelineAddEntries":
+      s.Get("entries").ForEach(func(_, s gjson.Result) bool {
+        timeline.parseEntry(s, false)
+        return true
+      })
+    case "TimelinePinEntry":
+      timeline.parseEntry(s.Get("entry"), true)
+    case "TimelineReplaceEntry":
+      timeline.parseEntry(s.Get("entry"), false)
+    case "TimelineAddToModule":
+      moduleID := s.Get("moduleEntryId").Str
+      s.Get("moduleItems").ForEach(func(_, s gjson.Result) bool {
+        timeline.parseItem(s.Get("entryId").Str, moduleID, false, s.Get("item.itemContent"))
+        return true
+      })
+    case "TimelineClearCache", "TimelineTerminateTimeline", "TimelineShowAlert", "TimelineShowCover", "TimelineClearEntriesUnreadState", "TimelineMarkEntriesUnreadGreaterThanSortIndex":
+    default:
+      timeline.Unknown["instruction:"+s.Get("type").Str]++
+    }
+    return true
+  })
+  return timeline
+}
+
+// ParseUserTimeline parses the timeline at the path of the user result, a
+// suspended or protected user comes back as UserUnavailable without any
+// timeline and is kept as an unavailable empty timeline.
+func ParseUserTimeline(user gjson.Result, path string) *Timeline {
+  if user.Get("__typename").Str == "UserUnavailable" {
+    reason := user.Get("reason").Str
+    if reason == "" {
+      reason = "UserUnavailable"
+    }
+    return &Timeline{
+      Unknown:     make(map[string]int),
+      Unavailable: reason,
+    }
+  }
+  return ParseTimeline(user.Get(path))
+}
+
+func (t *Timeline) parseEntry(s gjson.Result, pinned bool) {
+  entryID := s.Get("entryId").Str
+  switch s.Get("content.entryType").Str {
+  case "TimelineTimelineItem":
+    t.parseItem(entryID, "", pinned, s.Get("content.itemContent"))
+  case "TimelineTimelineModule":
+    s.Get("content.items").ForEach(func(_, s gjson.Result) bool {
+      t.parseItem(s.Get("entryId").Str, entryID, pinned, s.Get("item.itemContent"))
+      return true
+    })
+  case "TimelineTimelineCursor":
+    t.Entries = append(t.Entries, &Entry{
+      EntryID: entryID,
+      Cursor: &Cursor{
+        Type:  s.Get("content.cursorType").Str,
+        Value: s.Get("content.value").Str,
+      },
+    })
+  default:
+    // older responses replace cursors without an entryType
+    if s.Get("content.cursorType").Exists() {
+      t.Entries = append(t.Entries, &Entry{
+        EntryID: entryID,
+        Cursor: &Cursor{
+          Type:  s.Get("content.cursorType").Str,
+          Value: s.Get("content.value").Str,
+        },
+      })
+      return
+    }
+    t.Unknown["entry:"+s.Get("content.entryType").Str]++
+  }
+}
+
+func (t *Timeline) parseItem(entryID string, moduleID string, pinned bool, s gjson.Result) {
+  entry := &Entry{
+    EntryID:  entryID,
+    ModuleID: moduleID,
+    Pinned:   pinned,
+  }
+  switch s.Get("itemType").Str {
+  case "TimelineTweet":
+    entry.Tweet = ParseTweet(s.Get("tweet_results.result"))
+    entry.Tweet.Promoted = s.Get("promotedMetadata").Exists()
+    if !IsKnownTweet(entry.Tweet.Typename) {
+      t.Unknown["tweet:"+entry.Tweet.Typename]++
+    }
+  case "TimelineUser":
+    entry.User = ParseUser(s.Get("user_results.result"))
+    if !IsKnownUser(entry.User.Typename) {
+      t.Unknown["user:"+entry.User.Typename]++
+    }
+  case "TimelineTimelineCursor":
+    entry.Cursor = &Cursor{
+      Type:  s.Get("cursorType").Str,
+      Value: s.Get("value").Str,
+    }
+  case "TimelineLabel", "TimelineMessagePrompt", "TimelineTombstone", "TimelinePrompt", "TimelineSpelling":
+    return
+  default:
+    t.Unknown["item:"+s.Get("itemType").Str]++
+    return
+  }
+  t.Entries = append(t.Entries, entry)
+}
+
+func (t *Timeline) Tweets() (tweets []*Tweet) {
+  for _, entry := range t.Entries {
+    if entry.Tweet != nil {
+      tweets = append(tweets, entry.Tweet)
+    }
+  }
+  return
+}
+
+func (t *Timeline) Users() (users []*User) {
+  for _, entry := range t.Entries {
+    if entry.User != nil {
+      users = append(users, entry.User)
+    }
+  }
+  return
+}
+
+// Cursors lists the cursor values of the given types in timeline order.
+func (t *Timeline) Cursors(types ...string) (cursors []string) {
+  for _, entry := range t.Entries {
+    if entry.Cursor == nil {
+      continue
+    }
+    for _, cursorType := range types {
+      if entry.Cursor.Type == cursorType {
+        cursors = append(cursors, entry.Cursor.Value)
+        break
+      }
+    }
+  }
+  return
+}
+
+// Cursor returns the last cursor of the given type, replaced entries come
+// after the ones they replace.
+func (t *Timeline) Cursor(cursorType string) (cursor string) {
+  cursors := t.Cursors(cursorType)
+  if len(cursors) > 0 {
+    cursor = cursors[len(cursors)-1]
+  }
+  return
+}
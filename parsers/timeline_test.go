@@ -0,0 +1,150 @@
+package parsers
+
+import (
+  "os"
+  "reflect"
+  "testing"
+
+  "github.com/tidwall/gjson"
+)
+
+func loadFixture(t *testing.T, name string) []byte {
+  body, err := os.ReadFile("testdata/" + name)
+  if err != nil {
+    t.Fatal(err)
+  }
+  return body
+}
+
+func TestParseTimelineEntries(t *testing.T) {
+  body := loadFixture(t, "user_tweets.json")
+  timeline := ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline_v2.timeline")
+
+  if !timeline.Exists || timeline.Instructions != 4 {
+    t.Fatalf("exists = %v instructions = %v", timeline.Exists, timeline.Instructions)
+  }
+  if len(timeline.Unknown) != 0 {
+    t.Errorf("unknown = %v", timeline.Unknown)
+  }
+
+  tests := []struct {
+    entryID   string
+    moduleID  string
+    pinned    bool
+    typename  string
+    twitterID int64
+  }{
+    {"tweet-11", "", true, "Tweet", 11},
+    {"tweet-12", "", false, "Tweet", 12},
+    {"tweet-13", "", false, "Tweet", 13},
+    {"tweet-14", "", false, "Tweet", 14},
+    {"tweet-15", "", false, "TweetTombstone", 0},
+    {"profile-conversation-16-tweet-16", "profile-conversation-16", false, "Tweet", 16},
+    {"profile-conversation-16-tweet-17", "profile-conversation-16", false, "Tweet", 17},
+  }
+  tweets := timeline.Tweets()
+  if len(tweets) != len(tests) {
+    t.Fatalf("tweets = %v, want %v", len(tweets), len(tests))
+  }
+  for i, tt := range tests {
+    entry := timeline.Entries[i]
+    if entry.EntryID != tt.entryID || entry.ModuleID != tt.moduleID || entry.Pinned != tt.pinned {
+      t.Errorf("entry %v = %v %v %v, want %v %v %v", i, entry.EntryID, entry.ModuleID, entry.Pinned, tt.entryID, tt.moduleID, tt.pinned)
+    }
+    if entry.Tweet.Typename != tt.typename || entry.Tweet.TwitterID != tt.twitterID {
+      t.Errorf("tweet %v = %v %v, want %v %v", tt.entryID, entry.Tweet.Typename, entry.Tweet.TwitterID, tt.typename, tt.twitterID)
+    }
+  }
+}
+
+func TestTimelineCursors(t *testing.T) {
+  body := loadFixture(t, "user_tweets.json")
+  timeline := ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline_v2.timeline")
+
+  tests := []struct {
+    types   []string
+    cursors []string
+  }{
+    {[]string{"Top"}, []string{"top-1"}},
+    {[]string{"Bottom"}, []string{"bottom-1", "bottom-2"}},
+    {[]string{"Top", "Bottom"}, []string{"top-1", "bottom-1", "bottom-2"}},
+    {[]string{"ShowMore"}, nil},
+  }
+  for _, tt := range tests {
+    if cursors := timeline.Cursors(tt.types...); !reflect.DeepEqual(cursors, tt.cursors) {
+      t.Errorf("Cursors(%v) = %v, want %v", tt.types, cursors, tt.cursors)
+    }
+  }
+  if cursor := timeline.Cursor("Bottom"); cursor != "bottom-2" {
+    t.Errorf("Cursor(Bottom) = %v, want the replaced bottom-2", cursor)
+  }
+  if cursor := timeline.Cursor("ShowMore"); cursor != "" {
+    t.Errorf("Cursor(ShowMore) = %v, want empty", cursor)
+  }
+}
+
+func TestTimelineUsers(t *testing.T) {
+  body := loadFixture(t, "followers.json")
+  timeline := ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline.timeline")
+
+  users := timeline.Users()
+  if len(users) != 3 {
+    t.Fatalf("users = %v, want 3", len(users))
+  }
+  if users[0].UserID != 1001 || users[0].Account != "alice" || users[0].Avatar != "https://pbs.twimg.com/profile_images/1/a.jpg" {
+    t.Errorf("user = %+v", users[0])
+  }
+  if users[2].Typename != "UserUnavailable" || users[2].Reason != "Suspended" {
+    t.Errorf("unavailable user = %+v", users[2])
+  }
+  if cursor := timeline.Cursor("Bottom"); cursor != "0|1700000000000000000" {
+    t.Errorf("Cursor(Bottom) = %v", cursor)
+  }
+}
+
+func TestTimelineValidate(t *testing.T) {
+  tests := []struct {
+    name        string
+    fixture     string
+    path        string
+    drift       bool
+    empty       bool
+    unavailable string
+  }{
+    {"timeline", "user_tweets.json", "timeline_v2.timeline", false, false, ""},
+    {"users", "followers.json", "timeline.timeline", false, false, ""},
+    {"wrong path", "followers.json", "timeline_v2.timeline", true, true, ""},
+    {"unavailable user", "user_unavailable.json", "timeline_v2.timeline", false, true, "Suspended"},
+    {"unknown content", "drift.json", "timeline_v2.timeline", true, true, ""},
+  }
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      body := loadFixture(t, tt.fixture)
+      timeline := ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), tt.path)
+      err := timeline.Validate()
+      if IsDrift(err) != tt.drift {
+        t.Errorf("Validate() = %v, want drift %v", err, tt.drift)
+      }
+      if timeline.IsEmpty() != tt.empty {
+        t.Errorf("IsEmpty() = %v, want %v", timeline.IsEmpty(), tt.empty)
+      }
+      if timeline.Unavailable != tt.unavailable {
+        t.Errorf("Unavailable = %q, want %q", timeline.Unavailable, tt.unavailable)
+      }
+    })
+  }
+}
+
+func TestTimelineUnknown(t *testing.T) {
+  body := loadFixture(t, "drift.json")
+  timeline := ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline_v2.timeline")
+
+  want := map[string]int{
+    "instruction:TimelineAddSomethingNew": 1,
+    "tweet:TweetFromTheFuture":            1,
+    "entry:TimelineTimelineWidget":        1,
+  }
+  if !reflect.DeepEqual(timeline.Unknown, want) {
+    t.Errorf("unknown = %v, want %v", timeline.Unknown, want)
+  }
+}
@@ -0,0 +1,150 @@
+package parsers
+
+import (
+  "strconv"
+  "strings"
+  "time"
+
+  "github.com/tidwall/gjson"
+
+  "scraper.local/twitter-scraper/config"
+)
+
+// ParseTweet handles every tweet result variant, TweetWithVisibilityResults
+// is unwrapped, tombstones and unavailable tweets only keep their reason.
+func ParseTweet(s gjson.Result) *Tweet {
+  if s.Get("__typename").Str == "TweetWithVisibilityResults" {
+    s = s.Get("tweet")
+  }
+  tweet := &Tweet{
+    Typename: s.Get("__typename").Str,
+  }
+  if tweet.Typename == "" && s.Get("rest_id").Exists() {
+    tweet.Typename = "Tweet"
+  }
+  switch tweet.Typename {
+  case "Tweet":
+  case "TweetTombstone":
+    tweet.Reason = s.Get("tombstone.text.text").Str
+    return tweet
+  case "TweetUnavailable":
+    tweet.Reason = s.Get("reason").Str
+    return tweet
+  default:
+    return tweet
+  }
+
+  tweet.TwitterID, _ = strconv.ParseInt(s.Get("rest_id").Str, 10, 64)
+  if s.Get("core.user_results.result").Exists() {
+    tweet.User = ParseUser(s.Get("core.user_results.result"))
+  }
+  tweet.Content = s.Get("legacy.full_text").Str
+  if s.Get("note_tweet.note_tweet_results.result.text").Exists() {
+    tweet.Content = s.Get("note_tweet.note_tweet_results.result.text").Str
+  }
+  tweet.QuotedStatusID, _ = strconv.ParseInt(s.Get("legacy.quoted_status_id_str").Str, 10, 64)
+  tweet.InReplyToStatusID, _ = strconv.ParseInt(s.Get("legacy.in_reply_to_status_id_str").Str, 10, 64)
+  tweet.ConversationID, _ = strconv.ParseInt(s.Get("legacy.conversation_id_str").Str, 10, 64)
+  if s.Get("legacy.retweeted_status_result.result").Exists() {
+    tweet.Retweeted = ParseTweet(s.Get("legacy.retweeted_status_result.result"))
+  }
+  if s.Get("quoted_status_result.result").Exists() {
+    tweet.Quoted = ParseTweet(s.Get("quoted_status_result.result"))
+  }
+  tweet.Media = ParseMedia(s)
+  tweet.Metrics = ParseMetrics(s)
+  tweet.Entities = ParseEntities(s)
+  tweet.EditControl = ParseEditControl(s)
+  s.Get("legacy.withheld_in_countries").ForEach(func(_, s gjson.Result) bool {
+    tweet.WithheldInCountries = append(tweet.WithheldInCountries, s.Str)
+    return true
+  })
+  if createdAt, err := time.Parse(time.RubyDate, s.Get("legacy.created_at").Str); err == nil {
+    tweet.Timestamp = createdAt.UnixMilli()
+  }
+  return tweet
+}
+
+func (t *Tweet) IsAvailable() bool {
+  return t.Typename == "Tweet" && t.TwitterID > 0
+}
+
+func ParseMetrics(s gjson.Result) *Metrics {
+  viewCount, _ := strconv.ParseInt(s.Get("views.count").Str, 10, 64)
+  return &Metrics{
+    FavoriteCount: int(s.Get("legacy.favorite_count").Int()),
+    RetweetCount:  int(s.Get("legacy.retweet_count").Int()),
+    ReplyCount:    int(s.Get("legacy.reply_count").Int()),
+    QuoteCount:    int(s.Get("legacy.quote_count").Int()),
+    BookmarkCount: int(s.Get("legacy.bookmark_count").Int()),
+    ViewCount:     viewCount,
+  }
+}
+
+func ParseEntities(s gjson.Result) []*Entity {
+  var entities []*Entity
+  add := func(entityType int, s gjson.Result, value string, url string) {
+    entities = append(entities, &Entity{
+      Type:  entityType,
+      Start: int(s.Get("indices.0").Int()),
+      End:   int(s.Get("indices.1").Int()),
+      Value: value,
+      Url:   url,
+    })
+  }
+  // long note tweets carry their own entity set matching the full text
+  text := s.Get("legacy.entities")
+  if s.Get("note_tweet.note_tweet_results.result.entity_set").Exists() {
+    text = s.Get("note_tweet.note_tweet_results.result.entity_set")
+  }
+  text.Get("hashtags").ForEach(func(_, s gjson.Result) bool {
+    add(config.TWEET_ENTITY_HASHTAG, s, strings.ToLower(s.Get("text").Str), "")
+    return true
+  })
+  text.Get("user_mentions").ForEach(func(_, s gjson.Result) bool {
+    add(config.TWEET_ENTITY_MENTION, s, strings.ToLower(s.Get("screen_name").Str), "")
+    return true
+  })
+  text.Get("urls").ForEach(func(_, s gjson.Result) bool {
+    add(config.TWEET_ENTITY_URL, s, s.Get("expanded_url").Str, s.Get("url").Str)
+    return true
+  })
+  text.Get("symbols").ForEach(func(_, s gjson.Result) bool {
+    add(config.TWEET_ENTITY_CASHTAG, s, strings.ToLower(s.Get("text").Str), "")
+    return true
+  })
+  // every photo of a tweet shares the same t.co link
+  media := map[string]bool{}
+  s.Get("legacy.entities.media").ForEach(func(_, s gjson.Result) bool {
+    if media[s.Get("url").Str] {
+      return true
+    }
+    media[s.Get("url").Str] = true
+    add(config.TWEET_ENTITY_MEDIA, s, s.Get("expanded_url").Str, s.Get("url").Str)
+    return true
+  })
+  return entities
+}
+
+// ParseEditControl reads the edit chain, earlier versions of an edited
+// tweet keep it under edit_control_initial.
+func ParseEditControl(s gjson.Result) *EditControl {
+  editControl := &EditControl{}
+  container := s.Get("edit_control")
+  if container.Get("edit_control_initial").Exists() {
+    container = container.Get("edit_control_initial")
+  }
+  container.Get("edit_tweet_ids").ForEach(func(_, s gjson.Result) bool {
+    twitterID, _ := strconv.ParseInt(s.Str, 10, 64)
+    if twitterID > 0 {
+      editControl.EditTweetIDs = append(editControl.EditTweetIDs, twitterID)
+    }
+    return true
+  })
+  editControl.InitialID, _ = strconv.ParseInt(s.Get("edit_control.initial_tweet_id").Str, 10, 64)
+  if editControl.InitialID == 0 && len(editControl.EditTweetIDs) > 0 {
+    editControl.InitialID = editControl.EditTweetIDs[0]
+  }
+  editControl.EditableUntil, _ = strconv.ParseInt(container.Get("editable_until_msecs").Str, 10, 64)
+  return editControl
+}
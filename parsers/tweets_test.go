@@ -0,0 +1,215 @@
+package parsers
+
+import (
+  "reflect"
+  "testing"
+
+  "github.com/tidwall/gjson"
+
+  "scraper.local/twitter-scraper/config"
+)
+
+func TestParseTweet(t *testing.T) {
+  tests := []struct {
+    name      string
+    result    string
+    typename  string
+    reason    string
+    twitterID int64
+    content   string
+    available bool
+  }{
+    {
+      name:      "tweet",
+      result:    `{"__typename":"Tweet","rest_id":"1","legacy":{"full_text":"hello"}}`,
+      typename:  "Tweet",
+      twitterID: 1,
+      content:   "hello",
+      available: true,
+    },
+    {
+      name:      "tweet without typename",
+      result:    `{"rest_id":"2","legacy":{"full_text":"old"}}`,
+      typename:  "Tweet",
+      twitterID: 2,
+      content:   "old",
+      available: true,
+    },
+    {
+      name:      "visibility results",
+      result:    `{"__typename":"TweetWithVisibilityResults","tweet":{"rest_id":"3","legacy":{"full_text":"limited"}}}`,
+      typename:  "Tweet",
+      twitterID: 3,
+      content:   "limited",
+      available: true,
+    },
+    {
+      name:      "note tweet",
+      result:    `{"__typename":"Tweet","rest_id":"4","legacy":{"full_text":"trunc…"},"note_tweet":{"note_tweet_results":{"result":{"text":"the whole text"}}}}`,
+      typename:  "Tweet",
+      twitterID: 4,
+      content:   "the whole text",
+      available: true,
+    },
+    {
+      name:     "tombstone",
+      result:   `{"__typename":"TweetTombstone","tombstone":{"text":{"text":"This Post was deleted"}}}`,
+      typename: "TweetTombstone",
+      reason:   "This Post was deleted",
+    },
+    {
+      name:     "unavailable",
+      result:   `{"__typename":"TweetUnavailable","reason":"Protected"}`,
+      typename: "TweetUnavailable",
+      reason:   "Protected",
+    },
+    {
+      name:   "empty",
+      result: `{}`,
+    },
+    {
+      name:     "unknown",
+      result:   `{"__typename":"TweetFromTheFuture","rest_id":"5"}`,
+      typename: "TweetFromTheFuture",
+    },
+  }
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      tweet := ParseTweet(gjson.Parse(tt.result))
+      if tweet.Typename != tt.typename || tweet.Reason != tt.reason {
+        t.Errorf("typename = %q reason = %q, want %q %q", tweet.Typename, tweet.Reason, tt.typename, tt.reason)
+      }
+      if tweet.TwitterID != tt.twitterID || tweet.Content != tt.content {
+        t.Errorf("tweet = %v %q, want %v %q", tweet.TwitterID, tweet.Content, tt.twitterID, tt.content)
+      }
+      if tweet.IsAvailable() != tt.available {
+        t.Errorf("IsAvailable() = %v, want %v", tweet.IsAvailable(), tt.available)
+      }
+      if IsKnownTweet(tweet.Typename) != (tt.typename != "" && tt.typename != "TweetFromTheFuture") {
+        t.Errorf("IsKnownTweet(%q) = %v", tweet.Typename, IsKnownTweet(tweet.Typename))
+      }
+    })
+  }
+}
+
+func TestParseTweetReferences(t *testing.T) {
+  body := loadFixture(t, "user_tweets.json")
+  timeline := ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline_v2.timeline")
+  tweets := timeline.Tweets()
+
+  retweet := tweets[2]
+  if retweet.Retweeted == nil || retweet.Retweeted.TwitterID != 21 || retweet.Retweeted.User.Account != "bob" {
+    t.Errorf("retweeted = %+v", retweet.Retweeted)
+  }
+  quote := tweets[3]
+  if quote.Quoted == nil || quote.Quoted.TwitterID != 22 || quote.QuotedStatusID != 22 {
+    t.Errorf("quoted = %+v %v", quote.Quoted, quote.QuotedStatusID)
+  }
+  reply := tweets[6]
+  if reply.InReplyToStatusID != 16 || reply.ConversationID != 17 {
+    t.Errorf("reply = %v %v", reply.InReplyToStatusID, reply.ConversationID)
+  }
+  if tweets[0].User.Account != "alice" || tweets[0].Timestamp != 1136214245000 {
+    t.Errorf("tweet = %+v", tweets[0])
+  }
+  want := &Metrics{FavoriteCount: 3, RetweetCount: 2, ReplyCount: 1, ViewCount: 42}
+  if !reflect.DeepEqual(tweets[0].Metrics, want) {
+    t.Errorf("metrics = %+v, want %+v", tweets[0].Metrics, want)
+  }
+}
+
+func TestParseMedia(t *testing.T) {
+  tests := []struct {
+    name   string
+    result string
+    photos int
+    videos int
+  }{
+    {
+      name:   "extended entities",
+      result: `{"legacy":{"extended_entities":{"media":[{"type":"photo","media_url_https":"a.jpg"},{"type":"photo","media_url_https":"b.jpg"}]},"entities":{"media":[{"type":"photo","media_url_https":"a.jpg"}]}}}`,
+      photos: 2,
+    },
+    {
+      name:   "entities only",
+      result: `{"legacy":{"entities":{"media":[{"type":"photo","media_url_https":"a.jpg"}]}}}`,
+      photos: 1,
+    },
+    {
+      name:   "video and gif",
+      result: `{"legacy":{"extended_entities":{"media":[{"type":"video","media_url_https":"v.jpg","video_info":{"aspect_ratio":[16,9],"duration_millis":1000,"variants":[{"bitrate":832000,"content_type":"video/mp4","url":"v.mp4"}]}},{"type":"animated_gif","media_url_https":"g.jpg"}]}}}`,
+      videos: 2,
+    },
+    {
+      name:   "none",
+      result: `{"legacy":{}}`,
+    },
+  }
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      media := ParseMedia(gjson.Parse(tt.result))
+      if len(media.Photos) != tt.photos || len(media.Videos) != tt.videos {
+        t.Errorf("media = %v photos %v videos, want %v %v", len(media.Photos), len(media.Videos), tt.photos, tt.videos)
+      }
+      if media.IsEmpty() != (tt.photos == 0 && tt.videos == 0) {
+        t.Errorf("IsEmpty() = %v", media.IsEmpty())
+      }
+    })
+  }
+
+  video := ParseMedia(gjson.Parse(tests[2].result)).Videos[0]
+  if !reflect.DeepEqual(video.AspectRatio, []int{16, 9}) || video.DurationMillis != 1000 || video.Variants[0].Bitrate != 832000 {
+    t.Errorf("video = %+v", video)
+  }
+}
+
+func TestParseEntities(t *testing.T) {
+  result := `{"legacy":{"entities":{
+    "hashtags":[{"text":"GoLang","indices":[0,7]}],
+    "user_mentions":[{"screen_name":"Bob","indices":[8,12]}],
+    "urls":[{"url":"https://t.co/x","expanded_url":"https://example.com","indices":[13,36]}],
+    "symbols":[{"text":"TSLA","indices":[37,42]}],
+    "media":[{"url":"https://t.co/m","expanded_url":"https://x.com/a/photo/1","indices":[43,66]},{"url":"https://t.co/m","expanded_url":"https://x.com/a/photo/2","indices":[43,66]}]
+  }}}`
+  want := []*Entity{
+    {Type: config.TWEET_ENTITY_HASHTAG, Start: 0, End: 7, Value: "golang"},
+    {Type: config.TWEET_ENTITY_MENTION, Start: 8, End: 12, Value: "bob"},
+    {Type: config.TWEET_ENTITY_URL, Start: 13, End: 36, Value: "https://example.com", Url: "https://t.co/x"},
+    {Type: config.TWEET_ENTITY_CASHTAG, Start: 37, End: 42, Value: "tsla"},
+    {Type: config.TWEET_ENTITY_MEDIA, Start: 43, End: 66, Value: "https://x.com/a/photo/1", Url: "https://t.co/m"},
+  }
+  if entities := ParseEntities(gjson.Parse(result)); !reflect.DeepEqual(entities, want) {
+    t.Errorf("entities = %v, want %v", entities, want)
+  }
+}
+
+func TestParseEditControl(t *testing.T) {
+  tests := []struct {
+    name   string
+    result string
+    want   *EditControl
+  }{
+    {
+      name:   "latest version",
+      result: `{"edit_control":{"edit_tweet_ids":["1","2"],"editable_until_msecs":"1700000000000"}}`,
+      want:   &EditControl{InitialID: 1, EditTweetIDs: []int64{1, 2}, EditableUntil: 1700000000000},
+    },
+    {
+      name:   "earlier version",
+      result: `{"edit_control":{"initial_tweet_id":"1","edit_control_initial":{"edit_tweet_ids":["1","2","3"],"editable_until_msecs":"1700000000000"}}}`,
+      want:   &EditControl{InitialID: 1, EditTweetIDs: []int64{1, 2, 3}, EditableUntil: 1700000000000},
+    },
+    {
+      name:   "not editable",
+      result: `{}`,
+      want:   &EditControl{},
+    },
+  }
+  for _, tt := range tests {
+    t.Run(tt.name, func(t *testing.T) {
+      if editControl := ParseEditControl(gjson.Parse(tt.result)); !reflect.DeepEqual(editControl, tt.want) {
+        t.Errorf("edit control = %+v, want %+v", editControl, tt.want)
+      }
+    })
+  }
+}
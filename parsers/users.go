@@ -0,0 +1,33 @@
+package parsers
+
+import (
+  "strconv"
+  "strings"
+  "time"
+
+  "github.com/tidwall/gjson"
+)
+
+func ParseUser(s gjson.Result) *User {
+  user := &User{
+    Typename: s.Get("__typename").Str,
+  }
+  if user.Typename == "UserUnavailable" {
+    user.Reason = s.Get("reason").Str
+    return user
+  }
+  user.UserID, _ = strconv.ParseInt(s.Get("rest_id").Str, 10, 64)
+  user.Account = s.Get("legacy.screen_name").Str
+  user.Name = s.Get("legacy.name").Str
+  user.Description = s.Get("legacy.description").Str
+  user.Avatar = strings.Replace(s.Get("legacy.profile_image_url_https").Str, "_normal.", ".", 1)
+  user.FavouritesCount = int(s.Get("legacy.favourites_count").Int())
+  user.FollowersCount = int(s.Get("legacy.followers_count").Int())
+  user.FriendsCount = int(s.Get("legacy.friends_count").Int())
+  user.ListedCount = int(s.Get("legacy.listed_count").Int())
+  user.MediaCount = int(s.Get("legacy.media_count").Int())
+  if createdAt, err := time.Parse(time.RubyDate, s.Get("legacy.created_at").Str); err == nil {
+    user.Timestamp = createdAt.UnixMilli()
+  }
+  return user
+}
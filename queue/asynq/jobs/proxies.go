@@ -0,0 +1,12 @@
+package jobs
+
+import (
+  "scraper.local/twitter-scraper/config"
+  "github.com/hibiken/asynq"
+)
+
+type Proxies struct{}
+
+func (h *Proxies) Check() (*asynq.Task, error) {
+  return asynq.NewTask(config.ASYNQ_JOBS_PROXIES_CHECK, nil), nil
+}
@@ -23,3 +23,11 @@ func (h *Posts) Process(taskID string) (*asynq.Task, error) {
   }
   return asynq.NewTask(config.ASYNQ_JOBS_SCRAPERS_POSTS_PROCESS, payload), nil
 }
+
+func (h *Posts) Verify(account string, postIDs []string) (*asynq.Task, error) {
+  payload, err := json.Marshal(VerifyPayload{account, postIDs})
+  if err != nil {
+    return nil, err
+  }
+  return asynq.NewTask(config.ASYNQ_JOBS_SCRAPERS_POSTS_VERIFY, payload), nil
+}
@@ -0,0 +1,25 @@
+package scrapers
+
+import (
+  "encoding/json"
+  "github.com/hibiken/asynq"
+  "scraper.local/twitter-scraper/config"
+)
+
+type Search struct{}
+
+func (h *Search) Flush(taskID string) (*asynq.Task, error) {
+  payload, err := json.Marshal(ProcessPayload{taskID})
+  if err != nil {
+    return nil, err
+  }
+  return asynq.NewTask(config.ASYNQ_JOBS_SCRAPERS_SEARCH_FLUSH, payload), nil
+}
+
+func (h *Search) Process(taskID string) (*asynq.Task, error) {
+  payload, err := json.Marshal(ProcessPayload{taskID})
+  if err != nil {
+    return nil, err
+  }
+  return asynq.NewTask(config.ASYNQ_JOBS_SCRAPERS_SEARCH_PROCESS, payload), nil
+}
@@ -10,3 +10,11 @@ type Sessions struct{}
 func (h *Sessions) Flush() (*asynq.Task, error) {
   return asynq.NewTask(config.ASYNQ_JOBS_SESSIONS_FLUSH, nil), nil
 }
+
+func (h *Sessions) Relogin() (*asynq.Task, error) {
+  return asynq.NewTask(config.ASYNQ_JOBS_SESSIONS_RELOGIN, nil), nil
+}
+
+func (h *Sessions) Probe() (*asynq.Task, error) {
+  return asynq.NewTask(config.ASYNQ_JOBS_SESSIONS_PROBE, nil), nil
+}
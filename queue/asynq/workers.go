@@ -18,5 +18,6 @@ func NewWorkers(ansqContext *common.AnsqServerContext) *Workers {
 func (h *Workers) Register() error {
   workers.NewScrapers(h.AnsqContext).Register()
   workers.NewSessions(h.AnsqContext).Register()
+  workers.NewProxies(h.AnsqContext).Register()
   return nil
 }
@@ -0,0 +1,67 @@
+package workers
+
+import (
+  "context"
+  "fmt"
+  "log"
+  "sync"
+  "time"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/repositories"
+  "github.com/hibiken/asynq"
+)
+
+type Proxies struct {
+  AnsqContext *common.AnsqServerContext
+  Repository  *repositories.ProxiesRepository
+}
+
+func NewProxies(ansqContext *common.AnsqServerContext) *Proxies {
+  h := &Proxies{
+    AnsqContext: ansqContext,
+  }
+  h.Repository = &repositories.ProxiesRepository{
+    Db:  h.AnsqContext.Db,
+    Rdb: h.AnsqContext.Rdb,
+    Ctx: h.AnsqContext.Ctx,
+  }
+  return h
+}
+
+// Check runs the health check of the proxy pool a few proxies at a time.
+func (h *Proxies) Check(ctx context.Context, t *asynq.Task) error {
+  wg := &sync.WaitGroup{}
+  sem := make(chan struct{}, config.PROXIES_CHECK_CONCURRENCY)
+  for _, proxy := range h.Repository.Checkables() {
+    wg.Add(1)
+    sem <- struct{}{}
+    go func(proxy *models.Proxy) {
+      defer func() {
+        <-sem
+        wg.Done()
+      }()
+      mutex := common.NewMutex(
+        h.AnsqContext.Rdb,
+        h.AnsqContext.Ctx,
+        fmt.Sprintf(config.LOCKS_PROXIES_CHECK, proxy.ID),
+      )
+      if !mutex.Lock(time.Minute) {
+        return
+      }
+      defer mutex.Unlock()
+      if err := h.Repository.Check(ctx, proxy); err != nil {
+        log.Println("proxies check failed", proxy.ID, proxy.GroupName, err)
+      }
+    }(proxy)
+  }
+  wg.Wait()
+  return nil
+}
+
+func (h *Proxies) Register() error {
+  h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_PROXIES_CHECK, h.Check)
+  return nil
+}
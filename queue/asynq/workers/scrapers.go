@@ -19,5 +19,7 @@ func (h *Scrapers) Register() error {
   workers.NewPosts(h.AnsqContext).Register()
   workers.NewReplies(h.AnsqContext).Register()
   workers.NewUsers(h.AnsqContext).Register()
+  workers.NewSearch(h.AnsqContext).Register()
+  workers.NewFollows(h.AnsqContext).Register()
   return nil
 }
@@ -0,0 +1,190 @@
+package scrapers
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "log"
+  "time"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/hibiken/asynq"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+  scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
+)
+
+type Follows struct {
+  AnsqContext        *common.AnsqServerContext
+  Repository         *scrapersRepositories.FollowsRepository
+  SessionsRepository *repositories.SessionsRepository
+  TasksRepository    *repositories.TasksRepository
+  UsersRepository    *repositories.UsersRepository
+  FollowsRepository  *repositories.FollowsRepository
+}
+
+func NewFollows(ansqContext *common.AnsqServerContext) *Follows {
+  h := &Follows{
+    AnsqContext: ansqContext,
+  }
+  h.Repository = &scrapersRepositories.FollowsRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.SessionsRepository = &repositories.SessionsRepository{
+    Db:  h.AnsqContext.Db,
+    Rdb: h.AnsqContext.Rdb,
+    Ctx: h.AnsqContext.Ctx,
+  }
+  h.UsersRepository = &repositories.UsersRepository{
+    Db:   h.AnsqContext.Db,
+    Nats: h.AnsqContext.Nats,
+  }
+  h.FollowsRepository = &repositories.FollowsRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.Repository.SessionsRepository = h.SessionsRepository
+  h.Repository.ArchivesRepository = &repositories.ArchivesRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.Repository.DriftRepository = &repositories.DriftRepository{
+    Rdb:  h.AnsqContext.Rdb,
+    Ctx:  h.AnsqContext.Ctx,
+    Nats: h.AnsqContext.Nats,
+  }
+  h.Repository.UsersRepository = h.UsersRepository
+  h.Repository.FollowsRepository = h.FollowsRepository
+  h.TasksRepository = &repositories.TasksRepository{
+    Db: h.AnsqContext.Db,
+  }
+  return h
+}
+
+func (h *Follows) Flush(ctx context.Context, t *asynq.Task) error {
+  var payload ProcessPayload
+  json.Unmarshal(t.Payload(), &payload)
+
+  mutex := common.NewMutex(
+    h.AnsqContext.Rdb,
+    h.AnsqContext.Ctx,
+    fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_FOLLOWS_FLUSH, payload.TaskID),
+  )
+  if !mutex.Lock(30 * time.Second) {
+    return nil
+  }
+  defer mutex.Unlock()
+
+  if task, err := h.TasksRepository.Find(payload.TaskID); err == nil {
+    if task.Status != 2 {
+      return nil
+    }
+    h.TasksRepository.Update(task, "status", 1)
+  }
+  return nil
+}
+
+func (h *Follows) Process(ctx context.Context, t *asynq.Task) error {
+  var payload ProcessPayload
+  json.Unmarshal(t.Payload(), &payload)
+
+  mutex := common.NewMutex(
+    h.AnsqContext.Rdb,
+    h.AnsqContext.Ctx,
+    fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_FOLLOWS_PROCESS, payload.TaskID),
+  )
+  if !mutex.Lock(30 * time.Second) {
+    return nil
+  }
+  defer mutex.Unlock()
+
+  if task, err := h.TasksRepository.Find(payload.TaskID); err == nil {
+    user, err := h.UsersRepository.Find(task.Params["user_id"].(string))
+    if err != nil {
+      log.Println("user can not be found", err)
+      return nil
+    }
+
+    timestamp := time.Now().UnixMicro()
+
+    session := h.SessionsRepository.Resume(task.Params, scrapersRepositories.FollowsOperation(task.Params["type"]), 1)
+    if session == nil {
+      log.Println("current session is empty")
+      return nil
+    }
+    if _, ok := task.Params["started_at"]; !ok {
+      task.Params["started_at"] = timestamp
+    }
+    h.SessionsRepository.Update(session, "timestamp", timestamp)
+    if cursor, count, err := h.Repository.Process(repositories.WithTask(ctx, task.ID), session, user, task.Params); err == nil {
+      if cursor == "" {
+        if scrapersRepositories.FollowsCompleted(task.Params) {
+          conditions := map[string]interface{}{}
+          if task.Params["type"] == "followers" {
+            conditions["user_id"] = user.ID
+          } else {
+            conditions["follower_id"] = user.ID
+          }
+          var startedAt int64
+          switch value := task.Params["started_at"].(type) {
+          case float64:
+            startedAt = int64(value)
+          case int64:
+            startedAt = value
+          }
+          h.FollowsRepository.Unfollowed(conditions, startedAt, timestamp)
+        }
+        delete(task.Params, "cursors")
+        delete(task.Params, "started_at")
+        delete(task.Params, "applied")
+        delete(task.Params, "completed")
+        h.AnsqContext.Rdb.ZRem(h.AnsqContext.Ctx, config.REDIS_KEY_TASKS_FOLLOWS_TARGET, task.ID)
+        h.TasksRepository.Updates(task, map[string]interface{}{
+          "params": task.Params,
+          "status": 2,
+        })
+        return nil
+      }
+
+      score, _ := h.AnsqContext.Rdb.ZScore(h.AnsqContext.Ctx, config.REDIS_KEY_TASKS_FOLLOWS_TARGET, task.ID).Result()
+      if count < 20 {
+        if score == 0 || timestamp-int64(score) < config.SCRAPERS_CURSOR_WAITING_TIMEOUT {
+          log.Println("waiting for cursor change", timestamp-int64(score))
+          return nil
+        }
+      }
+
+      if score > 0 {
+        h.AnsqContext.Rdb.ZAdd(
+          h.AnsqContext.Ctx,
+          config.REDIS_KEY_TASKS_FOLLOWS_TARGET,
+          &redis.Z{
+            Score:  float64(timestamp),
+            Member: task.ID,
+          },
+        )
+      }
+
+      cursors := make(map[string]interface{})
+      if _, ok := task.Params["cursors"]; ok {
+        cursors = task.Params["cursors"].(map[string]interface{})
+      }
+      cursors[session.Account] = cursor
+      task.Params["cursors"] = cursors
+      h.TasksRepository.Update(task, "params", task.Params)
+    } else {
+      if parsers.IsDrift(err) {
+        h.TasksRepository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
+      log.Println("error", err)
+    }
+  }
+  return nil
+}
+
+func (h *Follows) Register() error {
+  h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_SCRAPERS_FOLLOWS_FLUSH, h.Flush)
+  h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_SCRAPERS_FOLLOWS_PROCESS, h.Process)
+  return nil
+}
@@ -7,3 +7,8 @@ type InitPayload struct {
 type ProcessPayload struct {
   TaskID string `json:"task_id"`
 }
+
+type VerifyPayload struct {
+  Account string   `json:"account"`
+  PostIDs []string `json:"post_ids"`
+}
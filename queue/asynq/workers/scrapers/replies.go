@@ -15,7 +15,7 @@ import (
 
   "scraper.local/twitter-scraper/common"
   "scraper.local/twitter-scraper/config"
-  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
   "scraper.local/twitter-scraper/repositories"
   scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
 )
@@ -40,12 +40,22 @@ func NewReplies(ansqContext *common.AnsqServerContext) *Replies {
     Db: h.AnsqContext.Db,
   }
   h.SessionsRepository = &repositories.SessionsRepository{
-    Db: h.AnsqContext.Db,
+    Db:  h.AnsqContext.Db,
+    Rdb: h.AnsqContext.Rdb,
+    Ctx: h.AnsqContext.Ctx,
   }
   h.PostsRepository = &repositories.PostsRepository{
     Db: h.AnsqContext.Db,
   }
   h.Repository.SessionsRepository = h.SessionsRepository
+  h.Repository.ArchivesRepository = &repositories.ArchivesRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.Repository.DriftRepository = &repositories.DriftRepository{
+    Rdb:  h.AnsqContext.Rdb,
+    Ctx:  h.AnsqContext.Ctx,
+    Nats: h.AnsqContext.Nats,
+  }
   h.Repository.UsersRepository = &repositories.UsersRepository{
     Db:   h.AnsqContext.Db,
     Nats: h.AnsqContext.Nats,
@@ -127,12 +137,12 @@ func (h *Replies) Flush(ctx context.Context, t *asynq.Task) error {
     if err != nil {
       return err
     }
-    session := h.SessionsRepository.Current()
+    session := h.SessionsRepository.Current("TweetDetail")
     if session == nil {
       log.Println("current session is empty")
       return nil
     }
-    h.Repository.Process(session, post, task.Params)
+    h.Repository.Process(repositories.WithTask(ctx, task.ID), session, post, task.Params)
   }
   return nil
 }
@@ -163,27 +173,13 @@ func (h *Replies) Process(ctx context.Context, t *asynq.Task) error {
     if err != nil {
       return err
     }
-    var session *models.Session
-    if _, ok := task.Params["cursors"]; ok {
-      cursors := task.Params["cursors"].(map[string]interface{})
-      for account, _ := range cursors {
-        session, _ = h.SessionsRepository.Get(account)
-        if session != nil && session.Status == 1 {
-          break
-        }
-      }
-      if session == nil {
-        session = h.SessionsRepository.Current()
-      }
-    } else {
-      session = h.SessionsRepository.Current()
-    }
+    session := h.SessionsRepository.Resume(task.Params, "TweetDetail", 1)
     if session == nil {
       log.Println("current session is empty")
       return nil
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.Repository.Process(session, post, task.Params); err == nil {
+    if cursor, count, err := h.Repository.Process(repositories.WithTask(ctx, task.ID), session, post, task.Params); err == nil {
       if cursor == "" {
         delete(task.Params, "cursors")
         h.AnsqContext.Rdb.ZRem(h.AnsqContext.Ctx, config.REDIS_KEY_TASKS_REPLIES_TARGET, task.ID)
@@ -220,6 +216,9 @@ func (h *Replies) Process(ctx context.Context, t *asynq.Task) error {
       cursors[session.Account] = cursor
       task.Params["cursors"] = cursors
       h.TasksRepository.Update(task, "params", task.Params)
+    } else if parsers.IsDrift(err) {
+      h.TasksRepository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      log.Println("error", err)
     }
   }
   return nil
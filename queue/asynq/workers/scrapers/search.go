@@ -0,0 +1,164 @@
+package scrapers
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "log"
+  "time"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/hibiken/asynq"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+  scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
+)
+
+type Search struct {
+  AnsqContext        *common.AnsqServerContext
+  Repository         *scrapersRepositories.SearchRepository
+  SessionsRepository *repositories.SessionsRepository
+  TasksRepository    *repositories.TasksRepository
+}
+
+func NewSearch(ansqContext *common.AnsqServerContext) *Search {
+  h := &Search{
+    AnsqContext: ansqContext,
+  }
+  h.Repository = &scrapersRepositories.SearchRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.SessionsRepository = &repositories.SessionsRepository{
+    Db:  h.AnsqContext.Db,
+    Rdb: h.AnsqContext.Rdb,
+    Ctx: h.AnsqContext.Ctx,
+  }
+  h.Repository.SessionsRepository = h.SessionsRepository
+  h.Repository.ArchivesRepository = &repositories.ArchivesRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.Repository.DriftRepository = &repositories.DriftRepository{
+    Rdb:  h.AnsqContext.Rdb,
+    Ctx:  h.AnsqContext.Ctx,
+    Nats: h.AnsqContext.Nats,
+  }
+  h.Repository.UsersRepository = &repositories.UsersRepository{
+    Db:   h.AnsqContext.Db,
+    Nats: h.AnsqContext.Nats,
+  }
+  h.Repository.PostsRepository = &repositories.PostsRepository{
+    Db:   h.AnsqContext.Db,
+    Nats: h.AnsqContext.Nats,
+  }
+  h.Repository.SearchRepository = &repositories.SearchRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.TasksRepository = &repositories.TasksRepository{
+    Db: h.AnsqContext.Db,
+  }
+  return h
+}
+
+func (h *Search) Flush(ctx context.Context, t *asynq.Task) error {
+  var payload ProcessPayload
+  json.Unmarshal(t.Payload(), &payload)
+
+  mutex := common.NewMutex(
+    h.AnsqContext.Rdb,
+    h.AnsqContext.Ctx,
+    fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_SEARCH_FLUSH, payload.TaskID),
+  )
+  if !mutex.Lock(30 * time.Second) {
+    return nil
+  }
+  defer mutex.Unlock()
+
+  if task, err := h.TasksRepository.Find(payload.TaskID); err == nil {
+    session := h.SessionsRepository.Current("SearchTimeline")
+    if session == nil {
+      log.Println("current session is empty")
+      return nil
+    }
+    h.Repository.Process(ctx, session, task)
+  }
+  return nil
+}
+
+func (h *Search) Process(ctx context.Context, t *asynq.Task) error {
+  var payload ProcessPayload
+  json.Unmarshal(t.Payload(), &payload)
+
+  mutex := common.NewMutex(
+    h.AnsqContext.Rdb,
+    h.AnsqContext.Ctx,
+    fmt.Sprintf(config.LOCKS_TASKS_SCRAPERS_SEARCH_PROCESS, payload.TaskID),
+  )
+  if !mutex.Lock(30 * time.Second) {
+    return nil
+  }
+  defer mutex.Unlock()
+
+  if task, err := h.TasksRepository.Find(payload.TaskID); err == nil {
+    timestamp := time.Now().UnixMicro()
+
+    session := h.SessionsRepository.Resume(task.Params, "SearchTimeline", 1)
+    if session == nil {
+      log.Println("current session is empty")
+      return nil
+    }
+    h.SessionsRepository.Update(session, "timestamp", timestamp)
+    if cursor, count, err := h.Repository.Process(ctx, session, task); err == nil {
+      if cursor == "" {
+        delete(task.Params, "cursors")
+        h.AnsqContext.Rdb.ZRem(h.AnsqContext.Ctx, config.REDIS_KEY_TASKS_SEARCH_TARGET, task.ID)
+        h.TasksRepository.Updates(task, map[string]interface{}{
+          "params": task.Params,
+          "status": 2,
+        })
+        return nil
+      }
+
+      score, _ := h.AnsqContext.Rdb.ZScore(h.AnsqContext.Ctx, config.REDIS_KEY_TASKS_SEARCH_TARGET, task.ID).Result()
+      if count < 20 {
+        if score == 0 || timestamp-int64(score) < config.SCRAPERS_CURSOR_WAITING_TIMEOUT {
+          log.Println("waiting for cursor change", timestamp-int64(score))
+          return nil
+        }
+      }
+
+      if score > 0 {
+        h.AnsqContext.Rdb.ZAdd(
+          h.AnsqContext.Ctx,
+          config.REDIS_KEY_TASKS_SEARCH_TARGET,
+          &redis.Z{
+            Score:  float64(timestamp),
+            Member: task.ID,
+          },
+        )
+      }
+
+      cursors := make(map[string]interface{})
+      if _, ok := task.Params["cursors"]; ok {
+        cursors = task.Params["cursors"].(map[string]interface{})
+      }
+      cursors[session.Account] = cursor
+      task.Params["cursors"] = cursors
+      h.TasksRepository.Update(task, "params", task.Params)
+    } else {
+      if parsers.IsDrift(err) {
+        h.TasksRepository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      }
+      log.Println("error", err)
+    }
+  }
+  return nil
+}
+
+func (h *Search) Register() error {
+  h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_SCRAPERS_SEARCH_FLUSH, h.Flush)
+  h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_SCRAPERS_SEARCH_PROCESS, h.Process)
+  return nil
+}
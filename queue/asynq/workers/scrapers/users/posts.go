@@ -12,7 +12,7 @@ import (
 
   "scraper.local/twitter-scraper/common"
   "scraper.local/twitter-scraper/config"
-  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
   "scraper.local/twitter-scraper/repositories"
   scrapersRepositories "scraper.local/twitter-scraper/repositories/scrapers"
 )
@@ -33,12 +33,22 @@ func NewPosts(ansqContext *common.AnsqServerContext) *Posts {
     Db: h.AnsqContext.Db,
   }
   h.SessionsRepository = &repositories.SessionsRepository{
-    Db: h.AnsqContext.Db,
+    Db:  h.AnsqContext.Db,
+    Rdb: h.AnsqContext.Rdb,
+    Ctx: h.AnsqContext.Ctx,
   }
   h.UsersRepository = &repositories.UsersRepository{
     Db: h.AnsqContext.Db,
   }
   h.Repository.SessionsRepository = h.SessionsRepository
+  h.Repository.ArchivesRepository = &repositories.ArchivesRepository{
+    Db: h.AnsqContext.Db,
+  }
+  h.Repository.DriftRepository = &repositories.DriftRepository{
+    Rdb:  h.AnsqContext.Rdb,
+    Ctx:  h.AnsqContext.Ctx,
+    Nats: h.AnsqContext.Nats,
+  }
   h.Repository.UsersRepository = h.UsersRepository
   h.Repository.PostsRepository = &repositories.PostsRepository{
     Db:   h.AnsqContext.Db,
@@ -70,12 +80,12 @@ func (h *Posts) Flush(ctx context.Context, t *asynq.Task) error {
       log.Println("user can not be found", err)
       return nil
     }
-    session := h.SessionsRepository.Current()
+    session := h.SessionsRepository.Current("UserTweets")
     if session == nil {
       log.Println("current session is empty")
       return nil
     }
-    h.Repository.Process(session, user, task.Params)
+    h.Repository.Process(repositories.WithTask(ctx, task.ID), session, user, task.Params)
   }
   return nil
 }
@@ -101,27 +111,13 @@ func (h *Posts) Process(ctx context.Context, t *asynq.Task) error {
     if err != nil {
       return err
     }
-    var session *models.Session
-    if _, ok := task.Params["cursors"]; ok {
-      cursors := task.Params["cursors"].(map[string]interface{})
-      for account, _ := range cursors {
-        session, _ = h.SessionsRepository.Get(account)
-        if session != nil && session.Status == 8 {
-          break
-        }
-      }
-      if session == nil {
-        session = h.SessionsRepository.Special()
-      }
-    } else {
-      session = h.SessionsRepository.Special()
-    }
+    session := h.SessionsRepository.Resume(task.Params, "UserTweets", 8)
     if session == nil {
       log.Println("special session is empty")
       return nil
     }
     h.SessionsRepository.Update(session, "timestamp", timestamp)
-    if cursor, count, err := h.Repository.Process(session, user, task.Params); err == nil {
+    if cursor, count, err := h.Repository.Process(repositories.WithTask(ctx, task.ID), session, user, task.Params); err == nil {
       if cursor == "" {
         delete(task.Params, "cursors")
         h.AnsqContext.Rdb.ZRem(h.AnsqContext.Ctx, config.REDIS_KEY_TASKS_USERS_POSTS_TARGET, task.ID)
@@ -158,6 +154,9 @@ func (h *Posts) Process(ctx context.Context, t *asynq.Task) error {
       cursors[session.Account] = cursor
       task.Params["cursors"] = cursors
       h.TasksRepository.Update(task, "params", task.Params)
+    } else if parsers.IsDrift(err) {
+      h.TasksRepository.Update(task, "status", config.TASK_STATUS_PARSE_ERROR)
+      log.Println("error", err)
     }
   }
   return nil
@@ -2,6 +2,10 @@ package workers
 
 import (
   "context"
+  "fmt"
+  "log"
+  "time"
+
   "scraper.local/twitter-scraper/common"
   "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/repositories"
@@ -18,19 +22,59 @@ func NewSessions(ansqContext *common.AnsqServerContext) *Sessions {
     AnsqContext: ansqContext,
   }
   h.Repository = &repositories.SessionsRepository{
-    Db: h.AnsqContext.Db,
+    Db:  h.AnsqContext.Db,
+    Rdb: h.AnsqContext.Rdb,
+    Ctx: h.AnsqContext.Ctx,
   }
   return h
 }
 
 func (h *Sessions) Flush(ctx context.Context, t *asynq.Task) error {
-  if session := h.Repository.Current(); session != nil {
-    h.Repository.Flush(session)
+  if session := h.Repository.Current(""); session != nil {
+    h.Repository.Flush(ctx, session)
+  }
+  return nil
+}
+
+// Relogin logs the dead sessions in again with their stored credentials.
+func (h *Sessions) Relogin(ctx context.Context, t *asynq.Task) error {
+  for _, session := range h.Repository.Deads() {
+    mutex := common.NewMutex(
+      h.AnsqContext.Rdb,
+      h.AnsqContext.Ctx,
+      fmt.Sprintf(config.LOCKS_SESSIONS_RELOGIN, session.ID),
+    )
+    if !mutex.Lock(5 * time.Minute) {
+      continue
+    }
+    if err := h.Repository.Relogin(ctx, session); err != nil {
+      log.Println("sessions relogin failed", session.Account, err)
+    } else {
+      log.Println("sessions relogin success", session.Account)
+    }
+    mutex.Unlock()
+  }
+  return nil
+}
+
+// Probe runs the Viewer query with every logged-in session, the events past
+// the health days are pruned along the way.
+func (h *Sessions) Probe(ctx context.Context, t *asynq.Task) error {
+  for _, session := range h.Repository.Probes() {
+    if err := h.Repository.Probe(ctx, session); err != nil {
+      log.Println("sessions probe failed", session.Account, err)
+    }
+  }
+  before := time.Now().AddDate(0, 0, -config.SESSIONS_HEALTH_DAYS).UnixMicro()
+  if count := h.Repository.Events().Prune(before); count > 0 {
+    log.Println("sessions events pruned", count)
   }
   return nil
 }
 
 func (h *Sessions) Register() error {
   h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_SESSIONS_FLUSH, h.Flush)
+  h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_SESSIONS_RELOGIN, h.Relogin)
+  h.AnsqContext.Mux.HandleFunc(config.ASYNQ_JOBS_SESSIONS_PROBE, h.Probe)
   return nil
 }
@@ -0,0 +1,88 @@
+package repositories
+
+import (
+  "bytes"
+  "compress/gzip"
+  "context"
+  "io"
+  "time"
+
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
+)
+
+type archiveTaskKey struct{}
+
+type ArchivesRepository struct {
+  Db *gorm.DB
+}
+
+// WithTask tags the context with the task whose pages are archived.
+func WithTask(ctx context.Context, taskID string) context.Context {
+  return context.WithValue(ctx, archiveTaskKey{}, taskID)
+}
+
+func TaskFromContext(ctx context.Context) string {
+  if taskID, ok := ctx.Value(archiveTaskKey{}).(string); ok {
+    return taskID
+  }
+  return ""
+}
+
+func IsArchiving() bool {
+  return common.GetEnvString("SCRAPER_ARCHIVE_ENABLED") == "true"
+}
+
+func (r *ArchivesRepository) Find(id string) (entity *models.Archive, err error) {
+  err = r.Db.First(&entity, "id", id).Error
+  return
+}
+
+func (r *ArchivesRepository) Listings(endpoint string, since int64, lastID string, limit int) []*models.Archive {
+  var archives []*models.Archive
+  query := r.Db.Where("endpoint=? AND timestamp>=?", endpoint, since)
+  if lastID != "" {
+    query.Where("id>?", lastID)
+  }
+  query.Order("id asc").Limit(limit).Find(&archives)
+  return archives
+}
+
+// Create keeps the gzipped raw page when archiving is turned on, a nil
+// repository archives nothing.
+func (r *ArchivesRepository) Create(ctx context.Context, session *models.Session, endpoint string, body []byte) (err error) {
+  if r == nil || !IsArchiving() {
+    return
+  }
+
+  var buf bytes.Buffer
+  zw := gzip.NewWriter(&buf)
+  if _, err = zw.Write(body); err != nil {
+    return
+  }
+  if err = zw.Close(); err != nil {
+    return
+  }
+
+  entity := &models.Archive{
+    ID:        xid.New().String(),
+    TaskID:    TaskFromContext(ctx),
+    SessionID: session.ID,
+    Endpoint:  endpoint,
+    Content:   buf.Bytes(),
+    Timestamp: time.Now().UnixMilli(),
+  }
+  return r.Db.Create(&entity).Error
+}
+
+func (r *ArchivesRepository) Content(archive *models.Archive) (body []byte, err error) {
+  zr, err := gzip.NewReader(bytes.NewReader(archive.Content))
+  if err != nil {
+    return
+  }
+  defer zr.Close()
+  return io.ReadAll(zr)
+}
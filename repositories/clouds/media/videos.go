@@ -109,10 +109,9 @@ func (r *VideosRepository) Sync(video *models.Video) (err error) {
   if resp.StatusCode != http.StatusOK {
     err = errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
     return
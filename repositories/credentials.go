@@ -0,0 +1,80 @@
+package repositories
+
+import (
+  "errors"
+
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
+)
+
+type CredentialsRepository struct {
+  Db *gorm.DB
+}
+
+func (r *CredentialsRepository) Get(account string) (entity *models.Credential, err error) {
+  err = r.Db.Where("account", account).Take(&entity).Error
+  return
+}
+
+func (r *CredentialsRepository) Apply(
+  account string,
+  password string,
+  alternateIdentifier string,
+  totpSecret string,
+) (entity *models.Credential, err error) {
+  result := r.Db.Where("account", account).Take(&entity)
+  if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+    entity = &models.Credential{
+      ID:                  xid.New().String(),
+      Account:             account,
+      Password:            common.Secret(password),
+      AlternateIdentifier: alternateIdentifier,
+      TotpSecret:          common.Secret(totpSecret),
+    }
+    err = r.Db.Create(&entity).Error
+    return
+  }
+  if result.Error != nil {
+    return nil, result.Error
+  }
+
+  values := map[string]interface{}{
+    "password": common.Secret(password),
+    "failures": 0,
+  }
+  if alternateIdentifier != "" {
+    values["alternate_identifier"] = alternateIdentifier
+  }
+  if totpSecret != "" {
+    values["totp_secret"] = common.Secret(totpSecret)
+  }
+  err = r.Db.Model(&entity).Updates(values).Error
+  return
+}
+
+func (r *CredentialsRepository) Updates(entity *models.Credential, values map[string]interface{}) (err error) {
+  return r.Db.Model(&entity).Updates(values).Error
+}
+
+// Reseal writes the secrets of every credential again, sealing them with the
+// current key.
+func (r *CredentialsRepository) Reseal() (count int, err error) {
+  var credentials []*models.Credential
+  err = r.Db.FindInBatches(&credentials, 100, func(tx *gorm.DB, batch int) error {
+    for _, credential := range credentials {
+      err := r.Db.Model(&credential).UpdateColumns(map[string]interface{}{
+        "password":    credential.Password,
+        "totp_secret": credential.TotpSecret,
+      }).Error
+      if err != nil {
+        return err
+      }
+      count++
+    }
+    return nil
+  }).Error
+  return
+}
@@ -0,0 +1,98 @@
+package repositories
+
+import (
+  "context"
+  "encoding/json"
+  "fmt"
+  "log"
+  "strings"
+  "time"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/nats-io/nats.go"
+
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
+)
+
+type DriftRepository struct {
+  Rdb  *redis.Client
+  Ctx  context.Context
+  Nats *nats.Conn
+}
+
+// Report counts the page of the endpoint and validates it, unrecognized
+// responses raise an alert. A nil repository only validates.
+func (r *DriftRepository) Report(endpoint string, timeline *parsers.Timeline) (err error) {
+  err = timeline.Validate()
+  if r == nil {
+    return
+  }
+
+  key := fmt.Sprintf(config.REDIS_KEY_SCRAPERS_DRIFT, endpoint)
+  r.Rdb.HIncrBy(r.Ctx, key, "pages", 1)
+  for kind, count := range timeline.Unknown {
+    r.Rdb.HIncrBy(r.Ctx, key, "unknown:"+kind, int64(count))
+  }
+
+  if err == nil {
+    if timeline.Unavailable != "" {
+      r.Rdb.HIncrBy(r.Ctx, key, "unavailable", 1)
+    } else if timeline.IsEmpty() {
+      r.Rdb.HIncrBy(r.Ctx, key, "empty", 1)
+    }
+    return
+  }
+
+  r.alert(key, endpoint, err)
+
+  return
+}
+
+// Unrecognized reports a single result of the endpoint which was not
+// recognized, such as a tweet of an unknown typename.
+func (r *DriftRepository) Unrecognized(endpoint string, kind string) (err error) {
+  err = &parsers.DriftError{Reason: "result not recognized", Unknown: map[string]int{kind: 1}}
+  if r == nil {
+    return
+  }
+
+  key := fmt.Sprintf(config.REDIS_KEY_SCRAPERS_DRIFT, endpoint)
+  r.Rdb.HIncrBy(r.Ctx, key, "pages", 1)
+  r.Rdb.HIncrBy(r.Ctx, key, "unknown:"+kind, 1)
+  r.alert(key, endpoint, err)
+
+  return
+}
+
+func (r *DriftRepository) alert(key string, endpoint string, err error) {
+  timestamp := time.Now().UnixMilli()
+  r.Rdb.HIncrBy(r.Ctx, key, "unrecognized", 1)
+  r.Rdb.HSet(r.Ctx, key, "drifted_at", timestamp, "reason", err.Error())
+
+  log.Println("scrapers drift alert", endpoint, err)
+  if r.Nats != nil {
+    data, _ := json.Marshal(map[string]interface{}{
+      "endpoint":  endpoint,
+      "reason":    err.Error(),
+      "timestamp": timestamp,
+    })
+    r.Nats.Publish(config.NATS_SCRAPERS_DRIFT, data)
+    r.Nats.Flush()
+  }
+}
+
+func (r *DriftRepository) Stats() map[string]map[string]string {
+  stats := make(map[string]map[string]string)
+  pattern := fmt.Sprintf(config.REDIS_KEY_SCRAPERS_DRIFT, "*")
+  keys, _ := r.Rdb.Keys(r.Ctx, pattern).Result()
+  for _, key := range keys {
+    endpoint := strings.TrimPrefix(key, strings.TrimSuffix(pattern, "*"))
+    stats[endpoint], _ = r.Rdb.HGetAll(r.Ctx, key).Result()
+  }
+  return stats
+}
+
+func (r *DriftRepository) Reset(endpoint string) error {
+  return r.Rdb.Del(r.Ctx, fmt.Sprintf(config.REDIS_KEY_SCRAPERS_DRIFT, endpoint)).Err()
+}
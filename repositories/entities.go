@@ -0,0 +1,41 @@
+package repositories
+
+import (
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/models"
+)
+
+type EntitiesRepository struct {
+  Db *gorm.DB
+}
+
+func (r *EntitiesRepository) Listings(twitterIDs []int64, types []int) []*models.TweetEntity {
+  var entities []*models.TweetEntity
+  if len(twitterIDs) == 0 {
+    return entities
+  }
+  query := r.Db.Where("twitter_id IN ?", twitterIDs)
+  if len(types) > 0 {
+    query.Where("type IN ?", types)
+  }
+  query.Order("start asc").Find(&entities)
+  return entities
+}
+
+func (r *EntitiesRepository) Apply(twitterID int64, entities []*models.TweetEntity) (err error) {
+  return r.Db.Transaction(func(tx *gorm.DB) error {
+    if err := tx.Where("twitter_id", twitterID).Delete(&models.TweetEntity{}).Error; err != nil {
+      return err
+    }
+    if len(entities) == 0 {
+      return nil
+    }
+    for _, entity := range entities {
+      entity.ID = xid.New().String()
+      entity.TwitterID = twitterID
+    }
+    return tx.Create(&entities).Error
+  })
+}
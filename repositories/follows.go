@@ -0,0 +1,98 @@
+package repositories
+
+import (
+  "errors"
+
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/models"
+)
+
+type FollowsRepository struct {
+  Db *gorm.DB
+}
+
+func (r *FollowsRepository) Count(conditions map[string]interface{}) int64 {
+  var total int64
+  query := r.Db.Model(&models.Follow{})
+  if _, ok := conditions["user_id"]; ok {
+    query.Where("user_id", conditions["user_id"].(string))
+  }
+  if _, ok := conditions["follower_id"]; ok {
+    query.Where("follower_id", conditions["follower_id"].(string))
+  }
+  if _, ok := conditions["status"]; ok {
+    query.Where("status", conditions["status"].(int))
+  } else {
+    query.Where("status IN (1,2)")
+  }
+  query.Count(&total)
+  return total
+}
+
+func (r *FollowsRepository) Listings(conditions map[string]interface{}, current int, pageSize int) []*models.Follow {
+  var follows []*models.Follow
+  query := r.Db.Select([]string{
+    "id",
+    "user_id",
+    "follower_id",
+    "first_seen_at",
+    "last_seen_at",
+    "unfollowed_at",
+    "status",
+  })
+  if _, ok := conditions["user_id"]; ok {
+    query.Where("user_id", conditions["user_id"].(string))
+  }
+  if _, ok := conditions["follower_id"]; ok {
+    query.Where("follower_id", conditions["follower_id"].(string))
+  }
+  if _, ok := conditions["status"]; ok {
+    query.Where("status", conditions["status"].(int))
+  } else {
+    query.Where("status IN (1,2)")
+  }
+  query.Order("last_seen_at desc")
+  query.Offset((current - 1) * pageSize).Limit(pageSize).Find(&follows)
+  return follows
+}
+
+func (r *FollowsRepository) Apply(userID string, followerID string, timestamp int64) (err error) {
+  var entity models.Follow
+  result := r.Db.Where("user_id=? AND follower_id=?", userID, followerID).Take(&entity)
+  if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+    entity = models.Follow{
+      ID:          xid.New().String(),
+      UserID:      userID,
+      FollowerID:  followerID,
+      FirstSeenAt: timestamp,
+      LastSeenAt:  timestamp,
+      Status:      1,
+    }
+    err = r.Db.Create(&entity).Error
+  } else {
+    err = r.Db.Model(&entity).Updates(map[string]interface{}{
+      "last_seen_at":  timestamp,
+      "unfollowed_at": 0,
+      "status":        1,
+    }).Error
+  }
+  return
+}
+
+func (r *FollowsRepository) Unfollowed(conditions map[string]interface{}, before int64, timestamp int64) int64 {
+  query := r.Db.Model(&models.Follow{})
+  if _, ok := conditions["user_id"]; ok {
+    query.Where("user_id", conditions["user_id"].(string))
+  }
+  if _, ok := conditions["follower_id"]; ok {
+    query.Where("follower_id", conditions["follower_id"].(string))
+  }
+  query.Where("status=1 AND last_seen_at<?", before)
+  result := query.Updates(map[string]interface{}{
+    "unfollowed_at": timestamp,
+    "status":        2,
+  })
+  return result.RowsAffected
+}
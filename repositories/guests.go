@@ -0,0 +1,113 @@
+package repositories
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "time"
+
+  "github.com/rs/xid"
+
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/models"
+)
+
+var guestOperations = map[string]bool{
+  "UserByScreenName":    true,
+  "TweetResultByRestId": true,
+}
+
+// IsGuestOperation tells the operations which run without a logged-in session.
+func IsGuestOperation(operation string) bool {
+  return guestOperations[operation]
+}
+
+// Guest returns a guest session with budget on the operation, expired or
+// dead guest tokens are activated again and missing guests are created.
+func (r *SessionsRepository) Guest(ctx context.Context, operation string) (session *models.Session, err error) {
+  if !IsGuestOperation(operation) {
+    return nil, errors.New(fmt.Sprintf("operation %v needs a logged-in session", operation))
+  }
+
+  var sessions []*models.Session
+  r.Db.Where(
+    "node = ? AND type = ?",
+    common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+    config.SESSIONS_TYPE_GUEST,
+  ).Order("timestamp ASC").Find(&sessions)
+
+  timestamp := time.Now().UnixMicro()
+  for _, session := range sessions {
+    if session.Status != 1 || session.FlushedAt < timestamp-config.SESSIONS_GUEST_TOKEN_TTL {
+      if err = r.Activate(ctx, session); err != nil {
+        continue
+      }
+    }
+    if r.IsAvailable(session, operation) {
+      return session, nil
+    }
+  }
+
+  if len(sessions) >= config.SESSIONS_GUESTS_LIMIT {
+    if err == nil {
+      err = errors.New("guest sessions are exhausted")
+    }
+    return nil, err
+  }
+
+  id := xid.New().String()
+  session = &models.Session{
+    ID:      id,
+    Account: fmt.Sprintf("guest:%v:%v", common.GetEnvInt("SCRAPER_STORAGE_NODE"), id),
+    Node:    common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+    Agent:   common.GetEnvString("SCRAPER_AGENT"),
+    Type:    config.SESSIONS_TYPE_GUEST,
+    Data:    common.SecretJSONMap(&SessionData{}),
+  }
+  if err = r.Db.Create(&session).Error; err != nil {
+    return nil, err
+  }
+  if err = r.Activate(ctx, session); err != nil {
+    return nil, err
+  }
+  return session, nil
+}
+
+// Activate rotates the guest token of the session, the query ids come from
+// the operation registry since guests can not load the client bundle.
+func (r *SessionsRepository) Activate(ctx context.Context, session *models.Session) (err error) {
+  token, err := clients.ActivateGuest(ctx, r.Proxy(session), session.Agent)
+  if err != nil {
+    r.Status(session, 0, fmt.Sprintf("Activate %v", err))
+    return
+  }
+
+  data := &SessionData{
+    AccessToken: clients.BearerToken(),
+  }
+  operations := r.Operations()
+  for name, section := range r.Sections(data) {
+    if operation := operations.Find(name); operation != nil {
+      *section = operation.QueryID
+    }
+  }
+
+  timestamp := time.Now().UnixMicro()
+  values := map[string]interface{}{
+    "cookie":       common.Secret(fmt.Sprintf("gt=%v", token)),
+    "data":         common.SecretJSONMap(data),
+    "flushed_at":   timestamp,
+    "unblocked_at": 0,
+  }
+  if err = r.Updates(session, values); err != nil {
+    return
+  }
+  session.Cookie = values["cookie"].(common.Secret)
+  session.Data = values["data"].(common.SecretMap)
+  session.FlushedAt = timestamp
+  session.UnblockedAt = 0
+  r.Status(session, 1, "guest token activated")
+  return
+}
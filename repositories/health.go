@@ -0,0 +1,256 @@
+package repositories
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "net/http"
+  "strconv"
+  "time"
+
+  "github.com/tidwall/gjson"
+
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+)
+
+func (r *SessionsRepository) Events() *SessionEventsRepository {
+  return &SessionEventsRepository{
+    Db: r.Db,
+  }
+}
+
+// Event keeps what happened to the session, along with the status it has
+// been left in.
+func (r *SessionsRepository) Event(
+  session *models.Session,
+  kind string,
+  operation string,
+  code int,
+  success bool,
+  reason string,
+) {
+  r.Events().Create(&models.SessionEvent{
+    SessionID: session.ID,
+    Kind:      kind,
+    Operation: operation,
+    Code:      code,
+    Status:    session.Status,
+    Success:   success,
+    Reason:    reason,
+    Timestamp: time.Now().UnixMicro(),
+  })
+}
+
+// Status moves the session to the status and records the reason, the same
+// status is left untouched.
+func (r *SessionsRepository) Status(session *models.Session, status int, reason string) {
+  if session.Status == status {
+    return
+  }
+  r.Update(session, "status", status)
+  session.Status = status
+  r.Event(session, config.SESSIONS_EVENT_STATUS, "", 0, status != 0, reason)
+}
+
+// Unreachable records a request of the operation which never got a response.
+func (r *SessionsRepository) Unreachable(session *models.Session, operation string, err error) {
+  r.count(session, false)
+  r.Fail(session, fmt.Sprintf("%v %v", operation, err))
+  r.Event(session, config.SESSIONS_EVENT_NETWORK, operation, 0, false, err.Error())
+}
+
+// Health sums the requests of the recent days and the last failure.
+func (r *SessionsRepository) Health(session *models.Session) *SessionHealth {
+  health := &SessionHealth{
+    ProbedAt:    session.ProbedAt,
+    LastFailure: r.Events().LastFailure(session.ID),
+  }
+  if r.Rdb != nil {
+    for i := 0; i < config.SESSIONS_HEALTH_DAYS; i++ {
+      day := time.Now().AddDate(0, 0, -i).Format("20060102")
+      values, _ := r.Rdb.HGetAll(r.Ctx, fmt.Sprintf(config.REDIS_KEY_SESSIONS_HEALTH, session.ID, day)).Result()
+      requests, _ := strconv.ParseInt(values["requests"], 10, 64)
+      failures, _ := strconv.ParseInt(values["failures"], 10, 64)
+      health.Requests += requests
+      health.Failures += failures
+    }
+  }
+  if health.Requests > 0 {
+    health.SuccessRate = float64(health.Requests-health.Failures) / float64(health.Requests)
+  }
+  return health
+}
+
+// Probes lists the logged-in sessions the health probe runs on.
+func (r *SessionsRepository) Probes() (sessions []*models.Session) {
+  r.Db.Where(
+    "node = ? AND type = ? AND status IN ?",
+    common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+    config.SESSIONS_TYPE_USER,
+    []int{1, 8},
+  ).Order("probed_at ASC").Find(&sessions)
+  return
+}
+
+// Probe runs the Viewer query with the session, a 401 marks the session dead.
+func (r *SessionsRepository) Probe(ctx context.Context, session *models.Session) (err error) {
+  code := 0
+  defer func() {
+    reason := ""
+    if err != nil {
+      reason = err.Error()
+    }
+    r.Event(session, config.SESSIONS_EVENT_PROBE, "Viewer", code, err == nil, reason)
+    r.Update(session, "probed_at", time.Now().UnixMicro())
+  }()
+
+  operation := r.Operations().Find("Viewer")
+  if operation == nil {
+    return errors.New("Viewer operation not found")
+  }
+
+  var sessionData *SessionData
+  buf, _ := session.Data.MarshalJSON()
+  json.Unmarshal(buf, &sessionData)
+  if sessionData == nil || sessionData.AccessToken == "" {
+    return errors.New("access token not found")
+  }
+
+  req := r.viewer(ctx, session, operation, sessionData.AccessToken)
+  resp, err := clients.NewClient(r.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
+  if err != nil {
+    r.count(session, false)
+    return
+  }
+  defer resp.Body.Close()
+
+  code = resp.StatusCode
+  r.RateLimit(session, "Viewer", resp)
+
+  if resp.StatusCode != http.StatusOK {
+    if resp.StatusCode == 401 {
+      r.Status(session, 0, fmt.Sprintf("Viewer %v", resp.Status))
+    }
+    return errors.New(
+      fmt.Sprintf(
+        "request error: account[%s] status[%s] code[%d]",
+        session.Account,
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+  }
+
+  body, _ := io.ReadAll(resp.Body)
+  user := gjson.GetBytes(body, "data.viewer.user_results.result")
+  if !user.Get("rest_id").Exists() {
+    return errors.New("viewer can not be found")
+  }
+  if session.TwitterID == 0 {
+    r.Update(session, "twitter_id", user.Get("rest_id").Int())
+  }
+
+  return
+}
+
+// Viewer resolves the user id and screen name the cookies are logged in as,
+// before the cookies have a session of their own.
+func (r *SessionsRepository) Viewer(
+  ctx context.Context,
+  cookies map[string]string,
+  proxy string,
+) (twitterID int64, account string, err error) {
+  operation := r.Operations().Find("Viewer")
+  if operation == nil {
+    return 0, "", errors.New("Viewer operation not found")
+  }
+
+  session := &models.Session{
+    Agent:  common.GetEnvString("SCRAPER_AGENT"),
+    Cookie: common.Secret(clients.CookieHeader(cookies)),
+    Proxy:  common.Secret(proxy),
+  }
+  req := r.viewer(ctx, session, operation, clients.BearerToken())
+  resp, err := clients.NewClient(r.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
+  if err != nil {
+    return
+  }
+  defer resp.Body.Close()
+
+  if resp.StatusCode != http.StatusOK {
+    err = errors.New(
+      fmt.Sprintf(
+        "request error: status[%s] code[%d]",
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+    return
+  }
+
+  body, _ := io.ReadAll(resp.Body)
+  user := gjson.GetBytes(body, "data.viewer.user_results.result")
+  twitterID = user.Get("rest_id").Int()
+  account = user.Get("core.screen_name").String()
+  if account == "" {
+    account = user.Get("legacy.screen_name").String()
+  }
+  if twitterID == 0 || account == "" {
+    err = errors.New("viewer can not be found")
+  }
+  return
+}
+
+func (r *SessionsRepository) viewer(
+  ctx context.Context,
+  session *models.Session,
+  operation *parsers.Operation,
+  accessToken string,
+) *http.Request {
+  variables := map[string]interface{}{
+    "withCommunitiesMemberships": true,
+  }
+  features := map[string]interface{}{
+    "responsive_web_graphql_exclude_directive_enabled":                  true,
+    "verified_phone_label_enabled":                                      false,
+    "creator_subscriptions_tweet_preview_api_enabled":                   true,
+    "responsive_web_graphql_skip_user_profile_image_extensions_enabled": false,
+    "responsive_web_graphql_timeline_navigation_enabled":                true,
+  }
+  features = r.Operations().Features("Viewer", features)
+  fieldToggles := map[string]interface{}{
+    "isDelegate":              false,
+    "withAuxiliaryUserLabels": false,
+  }
+
+  url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/Viewer", operation.QueryID)
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, accessToken)
+  q := req.URL.Query()
+  b1, _ := json.Marshal(variables)
+  b2, _ := json.Marshal(features)
+  b3, _ := json.Marshal(fieldToggles)
+  q.Add("variables", string(b1))
+  q.Add("features", string(b2))
+  q.Add("fieldToggles", string(b3))
+  req.URL.RawQuery = q.Encode()
+  return req
+}
+
+func (r *SessionsRepository) count(session *models.Session, success bool) {
+  if r.Rdb == nil {
+    return
+  }
+  key := fmt.Sprintf(config.REDIS_KEY_SESSIONS_HEALTH, session.ID, time.Now().Format("20060102"))
+  r.Rdb.HIncrBy(r.Ctx, key, "requests", 1)
+  if !success {
+    r.Rdb.HIncrBy(r.Ctx, key, "failures", 1)
+  }
+  r.Rdb.Expire(r.Ctx, key, time.Duration(config.SESSIONS_HEALTH_DAYS)*24*time.Hour)
+}
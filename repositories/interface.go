@@ -1,10 +1,20 @@
 package repositories
 
+import (
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+)
+
 type SessionData struct {
-  AccessToken    string `json:"access_token"`
-  SecionUsers    string `json:"section_users"`
-  SectionPosts   string `json:"section_posts"`
-  SectionReplies string `json:"section_replies"`
+  AccessToken      string `json:"access_token"`
+  SecionUsers      string `json:"section_users"`
+  SectionPosts     string `json:"section_posts"`
+  SectionMedia     string `json:"section_media"`
+  SectionReplies   string `json:"section_replies"`
+  SectionTweet     string `json:"section_tweet"`
+  SectionSearch    string `json:"section_search"`
+  SectionFollowers string `json:"section_followers"`
+  SectionFollowing string `json:"section_following"`
 }
 
 type TokenInfo struct {
@@ -12,3 +22,18 @@ type TokenInfo struct {
   CsrfToken   int    `json:"csrf_token"`
   RefreshedAt int    `json:"refreshed_at"`
 }
+
+type OperationDiff struct {
+  OperationName string             `json:"operation_name"`
+  Action        string             `json:"action"`
+  Previous      *parsers.Operation `json:"previous"`
+  Current       *parsers.Operation `json:"current"`
+}
+
+type SessionHealth struct {
+  Requests    int64                `json:"requests"`
+  Failures    int64                `json:"failures"`
+  SuccessRate float64              `json:"success_rate"`
+  ProbedAt    int64                `json:"probed_at"`
+  LastFailure *models.SessionEvent `json:"last_failure"`
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+  "time"
+
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/models"
+)
+
+type MetricsRepository struct {
+  Db *gorm.DB
+}
+
+// Listings returns the latest snapshots of the tweet, oldest first.
+func (r *MetricsRepository) Listings(twitterID int64, limit int) []*models.Metric {
+  var metrics []*models.Metric
+  r.Db.Where("twitter_id", twitterID).Order("timestamp desc").Limit(limit).Find(&metrics)
+  for i, j := 0, len(metrics)-1; i < j; i, j = i+1, j-1 {
+    metrics[i], metrics[j] = metrics[j], metrics[i]
+  }
+  return metrics
+}
+
+func (r *MetricsRepository) Latest(twitterID int64) (entity *models.Metric, err error) {
+  err = r.Db.Where("twitter_id", twitterID).Order("timestamp desc").Take(&entity).Error
+  return
+}
+
+func (r *MetricsRepository) Apply(model interface{}, twitterID int64, metric *models.Metric) (err error) {
+  err = r.Db.Model(model).Where("twitter_id", twitterID).Updates(map[string]interface{}{
+    "favorite_count": metric.FavoriteCount,
+    "retweet_count":  metric.RetweetCount,
+    "reply_count":    metric.ReplyCount,
+    "quote_count":    metric.QuoteCount,
+    "bookmark_count": metric.BookmarkCount,
+    "view_count":     metric.ViewCount,
+  }).Error
+  if err != nil {
+    return
+  }
+  // a tweet seen again with the same counters adds no snapshot
+  if latest, err := r.Latest(twitterID); err == nil && isSameMetric(latest, metric) {
+    return nil
+  }
+  metric.ID = xid.New().String()
+  metric.TwitterID = twitterID
+  metric.Timestamp = time.Now().UnixMilli()
+  return r.Db.Create(&metric).Error
+}
+
+// Snapshot keeps the metrics of an archived page at the time it was archived,
+// the live counters of the tweet are left alone and a page reparsed again
+// adds no second snapshot.
+func (r *MetricsRepository) Snapshot(twitterID int64, metric *models.Metric, timestamp int64) (err error) {
+  var count int64
+  r.Db.Model(&models.Metric{}).Where("twitter_id=? AND timestamp=?", twitterID, timestamp).Count(&count)
+  if count > 0 {
+    return
+  }
+  metric.ID = xid.New().String()
+  metric.TwitterID = twitterID
+  metric.Timestamp = timestamp
+  return r.Db.Create(&metric).Error
+}
+
+func isSameMetric(a *models.Metric, b *models.Metric) bool {
+  return a.FavoriteCount == b.FavoriteCount &&
+    a.RetweetCount == b.RetweetCount &&
+    a.ReplyCount == b.ReplyCount &&
+    a.QuoteCount == b.QuoteCount &&
+    a.BookmarkCount == b.BookmarkCount &&
+    a.ViewCount == b.ViewCount
+}
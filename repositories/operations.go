@@ -0,0 +1,160 @@
+package repositories
+
+import (
+  "context"
+  "encoding/json"
+  "reflect"
+  "sort"
+  "strconv"
+
+  "github.com/go-redis/redis/v8"
+
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
+)
+
+type OperationsRepository struct {
+  Rdb *redis.Client
+  Ctx context.Context
+}
+
+// Save replaces the registry with the operations of the latest client bundle,
+// the replaced registry is kept for diffs only when something changed.
+func (r *OperationsRepository) Save(operations map[string]*parsers.Operation) (changed bool, err error) {
+  if r == nil || r.Rdb == nil || len(operations) == 0 {
+    return
+  }
+
+  current := r.Listings()
+  if reflect.DeepEqual(current, operations) {
+    return
+  }
+
+  values := make(map[string]interface{})
+  for name, operation := range operations {
+    data, _ := json.Marshal(operation)
+    values[name] = string(data)
+  }
+
+  _, err = r.Rdb.TxPipelined(r.Ctx, func(pipe redis.Pipeliner) error {
+    pipe.Del(r.Ctx, config.REDIS_KEY_SCRAPER_OPERATIONS_PREVIOUS)
+    if len(current) > 0 {
+      pipe.Rename(r.Ctx, config.REDIS_KEY_SCRAPER_OPERATIONS, config.REDIS_KEY_SCRAPER_OPERATIONS_PREVIOUS)
+    }
+    pipe.HSet(r.Ctx, config.REDIS_KEY_SCRAPER_OPERATIONS, values)
+    return nil
+  })
+  if err != nil {
+    return
+  }
+
+  return true, nil
+}
+
+func (r *OperationsRepository) SaveFeatureSwitches(switches map[string]bool) (err error) {
+  if r == nil || r.Rdb == nil || len(switches) == 0 {
+    return
+  }
+  values := make(map[string]interface{})
+  for name, value := range switches {
+    values[name] = strconv.FormatBool(value)
+  }
+  return r.Rdb.HSet(r.Ctx, config.REDIS_KEY_SCRAPER_FEATURES, values).Err()
+}
+
+func (r *OperationsRepository) Listings() map[string]*parsers.Operation {
+  return r.load(config.REDIS_KEY_SCRAPER_OPERATIONS)
+}
+
+func (r *OperationsRepository) Previous() map[string]*parsers.Operation {
+  return r.load(config.REDIS_KEY_SCRAPER_OPERATIONS_PREVIOUS)
+}
+
+func (r *OperationsRepository) Find(name string) *parsers.Operation {
+  if r == nil || r.Rdb == nil {
+    return nil
+  }
+  value, err := r.Rdb.HGet(r.Ctx, config.REDIS_KEY_SCRAPER_OPERATIONS, name).Result()
+  if err != nil {
+    return nil
+  }
+  var operation *parsers.Operation
+  if err := json.Unmarshal([]byte(value), &operation); err != nil {
+    return nil
+  }
+  return operation
+}
+
+// Features builds the features parameter the web client sends with the
+// operation, switches missing from the initial state fall back to defaults.
+// Unknown operations keep the defaults as is.
+func (r *OperationsRepository) Features(name string, defaults map[string]interface{}) map[string]interface{} {
+  operation := r.Find(name)
+  if operation == nil || len(operation.FeatureSwitches) == 0 {
+    return defaults
+  }
+
+  switches, _ := r.Rdb.HGetAll(r.Ctx, config.REDIS_KEY_SCRAPER_FEATURES).Result()
+  features := make(map[string]interface{})
+  for _, feature := range operation.FeatureSwitches {
+    if value, ok := switches[feature]; ok {
+      features[feature] = value == "true"
+    } else if value, ok := defaults[feature]; ok {
+      features[feature] = value
+    } else {
+      features[feature] = false
+    }
+  }
+  return features
+}
+
+func (r *OperationsRepository) Diff() (diffs []*OperationDiff) {
+  previous := r.Previous()
+  current := r.Listings()
+
+  names := make([]string, 0, len(current))
+  for name := range current {
+    names = append(names, name)
+  }
+  for name := range previous {
+    if _, ok := current[name]; !ok {
+      names = append(names, name)
+    }
+  }
+  sort.Strings(names)
+
+  for _, name := range names {
+    diff := &OperationDiff{
+      OperationName: name,
+      Previous:      previous[name],
+      Current:       current[name],
+    }
+    switch {
+    case diff.Previous == nil:
+      diff.Action = "added"
+    case diff.Current == nil:
+      diff.Action = "removed"
+    case !reflect.DeepEqual(diff.Previous, diff.Current):
+      diff.Action = "changed"
+    default:
+      continue
+    }
+    diffs = append(diffs, diff)
+  }
+  return
+}
+
+func (r *OperationsRepository) load(key string) map[string]*parsers.Operation {
+  operations := make(map[string]*parsers.Operation)
+  if r == nil || r.Rdb == nil {
+    return operations
+  }
+  values, _ := r.Rdb.HGetAll(r.Ctx, key).Result()
+  for name, value := range values {
+    var operation *parsers.Operation
+    if err := json.Unmarshal([]byte(value), &operation); err == nil {
+      operations[name] = operation
+    }
+  }
+  return operations
+}
@@ -1,6 +1,7 @@
 package vida
 
 import (
+  "context"
   "crypto/sha1"
   "encoding/hex"
   "encoding/json"
@@ -61,7 +62,7 @@ func (r *PostsRepository) Sync(aff int, post *models.Post) error {
         sync.UrlSha1 = urlSha1
 
         if photo.Width == 0 {
-          config, err := r.ScrapersPhotosRepository.Config(url)
+          config, err := r.ScrapersPhotosRepository.Config(context.Background(), url)
           if err != nil {
             continue
           }
@@ -133,7 +134,7 @@ func (r *PostsRepository) Sync(aff int, post *models.Post) error {
       urlSha1 := hex.EncodeToString(hash[:])
       photo, _ := r.MediaPhotosRepository.Get(url, urlSha1)
       if photo.Width == 0 {
-        config, err := r.ScrapersPhotosRepository.Config(url)
+        config, err := r.ScrapersPhotosRepository.Config(context.Background(), url)
         if err != nil {
           continue
         }
@@ -9,14 +9,20 @@ import (
   "github.com/rs/xid"
   "gorm.io/datatypes"
   "gorm.io/gorm"
+  "strconv"
+  "strings"
+  "time"
 
   "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/models"
 )
 
+// PostsRepository keeps the posts, ArchivedAt is set while archived pages
+// are reparsed so their metrics are kept as snapshots of that time.
 type PostsRepository struct {
-  Db   *gorm.DB
-  Nats *nats.Conn
+  Db         *gorm.DB
+  Nats       *nats.Conn
+  ArchivedAt int64
 }
 
 func (r *PostsRepository) Count(conditions map[string]interface{}) int64 {
@@ -27,6 +33,28 @@ func (r *PostsRepository) Count(conditions map[string]interface{}) int64 {
     subQuery.Where("account=?", conditions["account"].(string))
     query.Where("user_id IN(?)", subQuery)
   }
+  if _, ok := conditions["task_id"]; ok {
+    subQuery := r.Db.Model(&models.SearchPost{}).Select([]string{"post_id"})
+    subQuery.Where("task_id=?", conditions["task_id"].(string))
+    query.Where("id IN(?)", subQuery)
+  }
+  for key, entityType := range map[string]int{
+    "hashtag": config.TWEET_ENTITY_HASHTAG,
+    "mention": config.TWEET_ENTITY_MENTION,
+    "cashtag": config.TWEET_ENTITY_CASHTAG,
+  } {
+    if _, ok := conditions[key]; ok {
+      subQuery := r.Db.Model(&models.TweetEntity{}).Select([]string{"twitter_id"})
+      subQuery.Where("type=? AND value=?", entityType, conditions[key].(string))
+      query.Where("twitter_id IN(?)", subQuery)
+    }
+  }
+  if _, ok := conditions["kind"]; ok {
+    query.Where("kind", conditions["kind"].(int))
+  }
+  if retweets, ok := conditions["retweets"]; ok && !retweets.(bool) {
+    query.Where("kind<>?", config.POST_KIND_RETWEET)
+  }
   if _, ok := conditions["status"]; ok {
     query.Where("status", conditions["status"].(int))
   } else {
@@ -43,8 +71,18 @@ func (r *PostsRepository) Listings(conditions map[string]interface{}, current in
     "user_id",
     "twitter_id",
     "status_id",
+    "kind",
+    "reference_id",
     "content",
     "media",
+    "favorite_count",
+    "retweet_count",
+    "reply_count",
+    "quote_count",
+    "bookmark_count",
+    "view_count",
+    "removed_reason",
+    "removed_at",
     "timestamp",
   })
   if _, ok := conditions["account"]; ok {
@@ -52,9 +90,31 @@ func (r *PostsRepository) Listings(conditions map[string]interface{}, current in
     subQuery.Where("account=?", conditions["account"].(string))
     query.Where("user_id IN(?)", subQuery)
   }
+  if _, ok := conditions["task_id"]; ok {
+    subQuery := r.Db.Model(&models.SearchPost{}).Select([]string{"post_id"})
+    subQuery.Where("task_id=?", conditions["task_id"].(string))
+    query.Where("id IN(?)", subQuery)
+  }
+  for key, entityType := range map[string]int{
+    "hashtag": config.TWEET_ENTITY_HASHTAG,
+    "mention": config.TWEET_ENTITY_MENTION,
+    "cashtag": config.TWEET_ENTITY_CASHTAG,
+  } {
+    if _, ok := conditions[key]; ok {
+      subQuery := r.Db.Model(&models.TweetEntity{}).Select([]string{"twitter_id"})
+      subQuery.Where("type=? AND value=?", entityType, conditions[key].(string))
+      query.Where("twitter_id IN(?)", subQuery)
+    }
+  }
   if _, ok := conditions["timestamp"]; ok {
     query.Where("timestamp BETWEEN ? AND ?", conditions["timestamp"].([]int64)[0], conditions["timestamp"].([]int64)[1])
   }
+  if _, ok := conditions["kind"]; ok {
+    query.Where("kind", conditions["kind"].(int))
+  }
+  if retweets, ok := conditions["retweets"]; ok && !retweets.(bool) {
+    query.Where("kind<>?", config.POST_KIND_RETWEET)
+  }
   if _, ok := conditions["status"]; ok {
     query.Where("status", conditions["status"].(int))
   } else {
@@ -75,6 +135,11 @@ func (r *PostsRepository) Get(twitterID int64) (entity *models.Post, err error)
   return
 }
 
+func (r *PostsRepository) GetByInitialID(initialID int64) (entity *models.Post, err error) {
+  err = r.Db.Where("initial_id", initialID).Order("timestamp desc").Take(&entity).Error
+  return
+}
+
 func (r *PostsRepository) GetByLinkID(linkID int64) (entity *models.Post, err error) {
   err = r.Db.Where("twitter_id=@linkID OR status_id=@linkID", sql.Named("linkID", linkID)).Take(&entity).Error
   return
@@ -118,6 +183,25 @@ func (r *PostsRepository) Ranking(
   return posts
 }
 
+// Verifying lists posts not verified since the given time, the ones never
+// verified come first and newer posts before older ones.
+func (r *PostsRepository) Verifying(verifiedAt int64, limit int) []*models.Post {
+  var posts []*models.Post
+  r.Db.Select([]string{
+    "id",
+    "twitter_id",
+    "verified_at",
+    "removed_reason",
+    "removed_at",
+    "timestamp",
+  }).Where(
+    "verified_at<? AND removed_reason<>? AND status IN (1,2,3)",
+    verifiedAt,
+    config.POST_REMOVED_DELETED,
+  ).Order("verified_at ASC").Order("timestamp DESC").Limit(limit).Find(&posts)
+  return posts
+}
+
 func (r *PostsRepository) IsExists(twitterID int64) bool {
   var entity *models.Post
   result := r.Db.Where("twitter_id", twitterID).Take(&entity)
@@ -131,6 +215,8 @@ func (r *PostsRepository) Create(
   userID string,
   twitterID int64,
   statusID int64,
+  kind int,
+  referenceID string,
   content string,
   media datatypes.JSONMap,
   timestamp int64,
@@ -138,17 +224,19 @@ func (r *PostsRepository) Create(
 ) (id string, err error) {
   id = xid.New().String()
   entity := &models.Post{
-    ID:        id,
-    UserID:    userID,
-    TwitterID: twitterID,
-    StatusID:  statusID,
-    Content:   content,
-    Media:     media,
-    Timestamp: timestamp,
-    Status:    status,
+    ID:          id,
+    UserID:      userID,
+    TwitterID:   twitterID,
+    StatusID:    statusID,
+    Kind:        kind,
+    ReferenceID: referenceID,
+    Content:     content,
+    Media:       media,
+    Timestamp:   timestamp,
+    Status:      status,
   }
   err = r.Db.Create(&entity).Error
-  if err == nil {
+  if err == nil && status != config.POST_STATUS_REFERENCED {
     data, _ := json.Marshal(map[string]interface{}{
       "id": id,
     })
@@ -164,15 +252,95 @@ func (r *PostsRepository) Update(post *models.Post, column string, value interfa
 }
 
 func (r *PostsRepository) Updates(post *models.Post, values map[string]interface{}) (err error) {
-  err = r.Db.Model(&post).Updates(values).Error
+  referenced := post.Status == config.POST_STATUS_REFERENCED
+  err = r.Db.Transaction(func(tx *gorm.DB) error {
+    // an edited tweet keeps its earlier version as a revision, the rows of
+    // the earlier tweet id move over to the edited one
+    revisions := &PostRevisionsRepository{
+      Db: tx,
+    }
+    if isRevised(post, values) && !revisions.IsExists(post.ID, post.TwitterID) {
+      if err := revisions.Create(post); err != nil {
+        return err
+      }
+    }
+    if twitterID, ok := values["twitter_id"]; ok && twitterID.(int64) != post.TwitterID {
+      if err := tx.Model(&models.Metric{}).Where("twitter_id", post.TwitterID).Update("twitter_id", twitterID).Error; err != nil {
+        return err
+      }
+      if err := tx.Where("twitter_id", twitterID).Delete(&models.TweetEntity{}).Error; err != nil {
+        return err
+      }
+      if err := tx.Model(&models.TweetEntity{}).Where("twitter_id", post.TwitterID).Update("twitter_id", twitterID).Error; err != nil {
+        return err
+      }
+    }
+    return tx.Model(&post).Updates(values).Error
+  })
   if err == nil {
     data, _ := json.Marshal(map[string]interface{}{
       "id": post.ID,
     })
-    if status, ok := values["status"]; ok && status.(int) == 1 {
+    // a referenced post seen on its own is published like a new post
+    if status, ok := values["status"]; ok && (status.(int) == 1 || referenced && status.(int) == 3) {
       r.Nats.Publish(config.NATS_POSTS_CREATE, data)
       r.Nats.Flush()
     }
   }
   return nil
 }
+
+// isRevised tells an edit apart from a content refresh, such as the note
+// tweet replacing a truncated text. A new edit id on a post still holding an
+// earlier version is an edit too.
+func isRevised(post *models.Post, values map[string]interface{}) bool {
+  if twitterID, ok := values["twitter_id"]; ok && twitterID.(int64) != post.TwitterID {
+    return true
+  }
+  if editTweetIDs, ok := values["edit_tweet_ids"]; ok && editTweetIDs.(string) != post.EditTweetIDs {
+    ids := strings.Split(editTweetIDs.(string), ",")
+    return ids[len(ids)-1] != strconv.FormatInt(post.TwitterID, 10)
+  }
+  return false
+}
+
+func (r *PostsRepository) Verified(post *models.Post, reason int) (err error) {
+  timestamp := time.Now().UnixMilli()
+  values := map[string]interface{}{
+    "verified_at": timestamp,
+  }
+  if reason != post.RemovedReason {
+    values["removed_reason"] = reason
+    if reason > 0 {
+      values["removed_at"] = timestamp
+    } else {
+      values["removed_at"] = 0
+    }
+  }
+  err = r.Db.Model(&post).Updates(values).Error
+  if err == nil && reason > 0 && reason != post.RemovedReason {
+    data, _ := json.Marshal(map[string]interface{}{
+      "id":     post.ID,
+      "reason": reason,
+    })
+    r.Nats.Publish(config.NATS_POSTS_REMOVE, data)
+    r.Nats.Flush()
+  }
+  return
+}
+
+func (r *PostsRepository) Metrics(twitterID int64, metric *models.Metric) (err error) {
+  repository := &MetricsRepository{
+    Db: r.Db,
+  }
+  if r.ArchivedAt > 0 {
+    return repository.Snapshot(twitterID, metric, r.ArchivedAt)
+  }
+  return repository.Apply(&models.Post{}, twitterID, metric)
+}
+
+func (r *PostsRepository) Entities(twitterID int64, entities []*models.TweetEntity) (err error) {
+  return (&EntitiesRepository{
+    Db: r.Db,
+  }).Apply(twitterID, entities)
+}
@@ -0,0 +1,374 @@
+package repositories
+
+import (
+  "context"
+  "errors"
+  "fmt"
+  "hash/fnv"
+  "io"
+  "log"
+  "math"
+  "math/rand"
+  "net"
+  "net/http"
+  "net/url"
+  "strings"
+  "time"
+
+  "github.com/go-redis/redis/v8"
+  "github.com/rs/xid"
+  "github.com/tidwall/gjson"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/models"
+)
+
+// ProxiesRepository keeps the proxy groups and resolves the group://name
+// references of the sessions, ?assign=rotating picks the next proxy of the
+// group on every request, ?assign=scored favours the proxies of better
+// scores and the default sticky assignment keeps a session on the same
+// proxy. Quarantined proxies are left out of every assignment.
+type ProxiesRepository struct {
+  Db  *gorm.DB
+  Rdb *redis.Client
+  Ctx context.Context
+}
+
+func (r *ProxiesRepository) Find(id string) (entity *models.Proxy, err error) {
+  err = r.Db.First(&entity, "id=?", id).Error
+  return
+}
+
+func (r *ProxiesRepository) Create(group string, proxy string) (entity *models.Proxy, err error) {
+  if group == "" {
+    return nil, errors.New("proxy group can not be empty")
+  }
+  u, err := common.ParseProxy(proxy)
+  if err != nil {
+    return
+  }
+  if u == nil || u.Scheme == "group" {
+    return nil, errors.New("proxy of a group must be a proxy url or a tor slot")
+  }
+  entity = &models.Proxy{
+    ID:        xid.New().String(),
+    GroupName: group,
+    Url:       common.Secret(u.String()),
+    Status:    1,
+  }
+  err = r.Db.Create(&entity).Error
+  return
+}
+
+// Import adds the proxies which are not in the group yet, the references are
+// compared in the form they are stored.
+func (r *ProxiesRepository) Import(group string, proxies []string) (count int, err error) {
+  exists := make(map[string]bool)
+  for _, proxy := range r.Listings(group) {
+    exists[string(proxy.Url)] = true
+  }
+  for _, proxy := range proxies {
+    if u, _ := common.ParseProxy(proxy); u != nil && exists[u.String()] {
+      continue
+    }
+    var entity *models.Proxy
+    if entity, err = r.Create(group, proxy); err != nil {
+      return
+    }
+    exists[string(entity.Url)] = true
+    count++
+  }
+  return
+}
+
+func (r *ProxiesRepository) Listings(group string) (proxies []*models.Proxy) {
+  query := r.Db.Order("group_name ASC, id ASC")
+  if group != "" {
+    query.Where("group_name", group)
+  }
+  query.Find(&proxies)
+  return
+}
+
+func (r *ProxiesRepository) Actives(group string) (proxies []*models.Proxy) {
+  r.Db.Where("group_name = ? AND status = 1", group).Order("id ASC").Find(&proxies)
+  return
+}
+
+func (r *ProxiesRepository) Delete(entity *models.Proxy) error {
+  return r.Db.Delete(&entity).Error
+}
+
+func (r *ProxiesRepository) Update(entity *models.Proxy, column string, value interface{}) error {
+  return r.Db.Model(&entity).Update(column, value).Error
+}
+
+// Proxy resolves the reference to the proxy url a request goes through, the
+// key is what sticky assignments are bound to.
+func (r *ProxiesRepository) Proxy(reference string, key string) (string, error) {
+  u, err := common.ParseProxy(reference)
+  if err != nil || u == nil {
+    return "", err
+  }
+  if u.Scheme == "group" {
+    u, err = r.Resolve(u, key)
+  } else {
+    u, err = common.ResolveProxy(u.String(), key)
+  }
+  if err != nil {
+    return "", err
+  }
+  return u.String(), nil
+}
+
+// Resolve picks the proxy of the group, a group is a proxy provider like the
+// tor slots are.
+func (r *ProxiesRepository) Resolve(ref *url.URL, key string) (*url.URL, error) {
+  proxies := r.Actives(ref.Host)
+  if len(proxies) == 0 {
+    return nil, errors.New(fmt.Sprintf("proxy group %v is empty", ref.Host))
+  }
+
+  var i int
+  switch ref.Query().Get("assign") {
+  case "", "sticky":
+    var highest uint32
+    for j, proxy := range proxies {
+      h := fnv.New32a()
+      h.Write([]byte(key + ":" + proxy.ID))
+      if weight := h.Sum32(); j == 0 || weight > highest {
+        i, highest = j, weight
+      }
+    }
+  case "scored":
+    var total float64
+    weights := make([]float64, len(proxies))
+    for j, proxy := range proxies {
+      weights[j] = proxy.Score
+      if proxy.Checks == 0 {
+        weights[j] = config.PROXIES_QUARANTINE_SCORE
+      }
+      total += weights[j]
+    }
+    pick := rand.Float64() * total
+    for j, weight := range weights {
+      if pick < weight {
+        i = j
+        break
+      }
+      pick -= weight
+    }
+  case "rotating":
+    i = rand.Intn(len(proxies))
+    if r.Rdb != nil {
+      next, err := r.Rdb.Incr(r.Ctx, fmt.Sprintf(config.REDIS_KEY_PROXIES_ROTATION, ref.Host)).Result()
+      if err == nil {
+        i = int(next % int64(len(proxies)))
+      }
+    }
+  default:
+    return nil, errors.New(fmt.Sprintf("proxy assignment %v not supported", ref.Query().Get("assign")))
+  }
+
+  return common.ResolveProxy(string(proxies[i].Url), key)
+}
+
+// IsRoutable tells whether the reference can be resolved without resolving
+// it, a group only needs an active proxy so no rotation is spent.
+func (r *ProxiesRepository) IsRoutable(reference string) bool {
+  u, err := common.ParseProxy(reference)
+  if err != nil {
+    return false
+  }
+  if u == nil {
+    return true
+  }
+  if u.Scheme == "group" {
+    switch u.Query().Get("assign") {
+    case "", "sticky", "scored", "rotating":
+    default:
+      return false
+    }
+    var count int64
+    r.Db.Model(&models.Proxy{}).Where("group_name = ? AND status = 1", u.Host).Count(&count)
+    return count > 0
+  }
+  _, err = common.ResolveProxy(u.String(), "")
+  return err == nil
+}
+
+// Pick resolves a proxy of the group for requests not bound to a session,
+// such as the media downloads, an unset or exhausted group dials directly.
+func (r *ProxiesRepository) Pick(group string) string {
+  if group == "" {
+    return ""
+  }
+  proxy, err := r.Proxy(fmt.Sprintf("group://%v?assign=scored", group), "")
+  if err != nil {
+    log.Println("proxy can not be picked", group, err)
+    return ""
+  }
+  return proxy
+}
+
+// Checkables lists the proxies the health check runs on, quarantined ones
+// are checked again once their quarantine is over.
+func (r *ProxiesRepository) Checkables() (proxies []*models.Proxy) {
+  r.Db.Where(
+    "status = 1 OR (status = 2 AND quarantined_until < ?)",
+    time.Now().UnixMicro(),
+  ).Order("checked_at ASC").Find(&proxies)
+  return
+}
+
+// Check requests the probe url through the proxy, the latency and the exit
+// ip it reports are scored into the proxy.
+func (r *ProxiesRepository) Check(ctx context.Context, proxy *models.Proxy) (err error) {
+  var latency int64
+  var exitIp string
+  defer func() {
+    r.Score(proxy, latency, exitIp, err)
+  }()
+
+  u, err := common.ResolveProxy(string(proxy.Url), proxy.ID)
+  if err != nil {
+    return
+  }
+
+  req, _ := http.NewRequestWithContext(ctx, "GET", ProbeUrl(), nil)
+  client := clients.NewClient(u.String(), time.Duration(config.PROXIES_CHECK_TIMEOUT)*time.Second)
+  client.Retries = 0
+  started := time.Now()
+  resp, err := client.Do(ctx, req)
+  if err != nil {
+    return
+  }
+  defer resp.Body.Close()
+
+  body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+  latency = time.Since(started).Milliseconds()
+  if err != nil {
+    return
+  }
+  if resp.StatusCode != http.StatusOK {
+    return errors.New(
+      fmt.Sprintf(
+        "request error: status[%s] code[%d]",
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+  }
+
+  exitIp = gjson.GetBytes(body, "ip").String()
+  if exitIp == "" {
+    exitIp = strings.TrimSpace(string(body))
+  }
+  if net.ParseIP(exitIp) == nil {
+    exitIp = ""
+    return errors.New("exit ip can not be found")
+  }
+  return
+}
+
+// Score folds the check into the moving score of the proxy and keeps it in
+// the history. Proxies failing in a row or scoring too low are quarantined,
+// a quarantined proxy passing its check starts over with the score of it.
+func (r *ProxiesRepository) Score(proxy *models.Proxy, latency int64, exitIp string, err error) {
+  timestamp := time.Now().UnixMicro()
+
+  sample := 0.0
+  if err == nil {
+    sample = 100 * float64(config.PROXIES_LATENCY_LIMIT-latency) /
+      float64(config.PROXIES_LATENCY_LIMIT-config.PROXIES_LATENCY_TARGET)
+    sample = math.Max(0, math.Min(100, sample))
+  }
+
+  values := map[string]interface{}{
+    "latency":    latency,
+    "checks":     proxy.Checks + 1,
+    "checked_at": timestamp,
+  }
+  score := sample
+  if proxy.Checks > 0 && proxy.Status == 1 {
+    score = proxy.Score*(1-config.PROXIES_SCORE_WEIGHT) + sample*config.PROXIES_SCORE_WEIGHT
+  }
+  values["score"] = score
+
+  reason := ""
+  if err != nil {
+    reason = common.Truncate(err.Error(), 255)
+    values["failures"] = proxy.Failures + 1
+    values["message"] = reason
+  } else {
+    values["exit_ip"] = exitIp
+    values["failures"] = 0
+  }
+
+  switch {
+  case proxy.Status == 2 && err == nil:
+    values["status"] = 1
+    values["quarantined_until"] = 0
+  case proxy.Status == 2:
+    values["quarantined_until"] = timestamp + config.PROXIES_QUARANTINE_INTERVAL
+  case proxy.Failures+1 >= config.PROXIES_QUARANTINE_FAILURES && err != nil,
+    proxy.Checks+1 >= config.PROXIES_QUARANTINE_FAILURES && score < config.PROXIES_QUARANTINE_SCORE:
+    values["status"] = 2
+    values["quarantined_until"] = timestamp + config.PROXIES_QUARANTINE_INTERVAL
+    log.Println("proxy quarantined", proxy.ID, proxy.GroupName, score, reason)
+  }
+  r.Db.Model(&proxy).Updates(values)
+
+  r.Db.Create(&models.ProxyScore{
+    ID:        xid.New().String(),
+    ProxyID:   proxy.ID,
+    Latency:   latency,
+    ExitIp:    exitIp,
+    Success:   err == nil,
+    Score:     score,
+    Reason:    reason,
+    Timestamp: timestamp,
+  })
+}
+
+func (r *ProxiesRepository) Scores(proxyID string, limit int) (scores []*models.ProxyScore) {
+  r.Db.Where("proxy_id", proxyID).Order("timestamp desc").Limit(limit).Find(&scores)
+  return
+}
+
+// Release takes the proxy out of quarantine before its time is over.
+func (r *ProxiesRepository) Release(proxy *models.Proxy) error {
+  return r.Db.Model(&proxy).Updates(map[string]interface{}{
+    "status":            1,
+    "failures":          0,
+    "quarantined_until": 0,
+  }).Error
+}
+
+// ProbeUrl points the health checks at SCRAPER_PROXY_PROBE_URL when set, the
+// probe is expected to answer the exit ip as text or as {"ip": ...}.
+func ProbeUrl() string {
+  if url := common.GetEnvString("SCRAPER_PROXY_PROBE_URL"); url != "" {
+    return url
+  }
+  return config.PROXIES_PROBE_URL
+}
+
+// Reseal writes the url of every proxy again, sealing it with the current
+// key.
+func (r *ProxiesRepository) Reseal() (count int, err error) {
+  var proxies []*models.Proxy
+  err = r.Db.FindInBatches(&proxies, 100, func(tx *gorm.DB, batch int) error {
+    for _, proxy := range proxies {
+      if err := r.Db.Model(&proxy).UpdateColumn("url", proxy.Url).Error; err != nil {
+        return err
+      }
+      count++
+    }
+    return nil
+  }).Error
+  return
+}
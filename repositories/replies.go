@@ -16,9 +16,12 @@ import (
   "scraper.local/twitter-scraper/models"
 )
 
+// RepliesRepository keeps the replies, ArchivedAt works as it does for the
+// posts.
 type RepliesRepository struct {
-  Db   *gorm.DB
-  Nats *nats.Conn
+  Db         *gorm.DB
+  Nats       *nats.Conn
+  ArchivedAt int64
 }
 
 func (r *RepliesRepository) Count(conditions map[string]interface{}) int64 {
@@ -51,6 +54,8 @@ func (r *RepliesRepository) Listings(conditions map[string]interface{}, current
     "user_id",
     "post_id",
     "twitter_id",
+    "in_reply_to_status_id",
+    "conversation_id",
     "content",
     "media",
     "timestamp",
@@ -76,6 +81,22 @@ func (r *RepliesRepository) Listings(conditions map[string]interface{}, current
   return replies
 }
 
+func (r *RepliesRepository) Thread(postID string, limit int) []*models.Reply {
+  var replies []*models.Reply
+  r.Db.Select([]string{
+    "id",
+    "user_id",
+    "post_id",
+    "twitter_id",
+    "in_reply_to_status_id",
+    "conversation_id",
+    "content",
+    "media",
+    "timestamp",
+  }).Where("post_id=? AND status IN (1,2,3)", postID).Order("timestamp asc").Limit(limit).Find(&replies)
+  return replies
+}
+
 func (r *RepliesRepository) Find(id string) (entity *models.Reply, err error) {
   err = r.Db.First(&entity, "id=?", id).Error
   return
@@ -135,6 +156,8 @@ func (r *RepliesRepository) Create(
   userID string,
   postID string,
   twitterID int64,
+  inReplyToStatusID int64,
+  conversationID int64,
   content string,
   media datatypes.JSONMap,
   timestamp int64,
@@ -142,14 +165,16 @@ func (r *RepliesRepository) Create(
 ) (id string, err error) {
   id = xid.New().String()
   entity := &models.Reply{
-    ID:        id,
-    UserID:    userID,
-    PostID:    postID,
-    TwitterID: twitterID,
-    Content:   content,
-    Media:     media,
-    Timestamp: timestamp,
-    Status:    status,
+    ID:                id,
+    UserID:            userID,
+    PostID:            postID,
+    TwitterID:         twitterID,
+    InReplyToStatusID: inReplyToStatusID,
+    ConversationID:    conversationID,
+    Content:           content,
+    Media:             media,
+    Timestamp:         timestamp,
+    Status:            status,
   }
   err = r.Db.Create(&entity).Error
   if err == nil {
@@ -180,3 +205,19 @@ func (r *RepliesRepository) Updates(reply *models.Reply, values map[string]inter
   }
   return nil
 }
+
+func (r *RepliesRepository) Metrics(twitterID int64, metric *models.Metric) (err error) {
+  repository := &MetricsRepository{
+    Db: r.Db,
+  }
+  if r.ArchivedAt > 0 {
+    return repository.Snapshot(twitterID, metric, r.ArchivedAt)
+  }
+  return repository.Apply(&models.Reply{}, twitterID, metric)
+}
+
+func (r *RepliesRepository) Entities(twitterID int64, entities []*models.TweetEntity) (err error) {
+  return (&EntitiesRepository{
+    Db: r.Db,
+  }).Apply(twitterID, entities)
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+  "time"
+
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/models"
+)
+
+type PostRevisionsRepository struct {
+  Db *gorm.DB
+}
+
+func (r *PostRevisionsRepository) Listings(postID string, limit int) []*models.PostRevision {
+  var revisions []*models.PostRevision
+  r.Db.Where("post_id", postID).Order("timestamp asc").Limit(limit).Find(&revisions)
+  return revisions
+}
+
+// IsExists tells whether the version of the tweet id is kept already, a
+// tweet id never changes its content so one revision of it is enough.
+func (r *PostRevisionsRepository) IsExists(postID string, twitterID int64) bool {
+  var count int64
+  r.Db.Model(&models.PostRevision{}).Where("post_id=? AND twitter_id=?", postID, twitterID).Count(&count)
+  return count > 0
+}
+
+func (r *PostRevisionsRepository) Create(post *models.Post) (err error) {
+  entity := &models.PostRevision{
+    ID:        xid.New().String(),
+    PostID:    post.ID,
+    TwitterID: post.TwitterID,
+    Content:   post.Content,
+    Media:     post.Media,
+    Timestamp: time.Now().UnixMilli(),
+  }
+  return r.Db.Create(&entity).Error
+}
@@ -0,0 +1,97 @@
+package scrapers
+
+import (
+  "errors"
+  "fmt"
+
+  "github.com/tidwall/gjson"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type ArchivesRepository struct {
+  Db                 *gorm.DB
+  TasksRepository    *repositories.TasksRepository
+  UsersRepository    *repositories.UsersRepository
+  PostsRepository    *repositories.PostsRepository
+  RepliesRepository  *repositories.RepliesRepository
+  SearchRepository   *repositories.SearchRepository
+  ArchivesRepository *repositories.ArchivesRepository
+}
+
+// Reparse runs the current parsers over an archived page, follows pages only
+// refresh the users since replaying the edges would revive unfollowed ones.
+// The metrics of the page are kept as snapshots of the time it was archived.
+// Pages scraped outside of a task, such as by `scrapers posts get`, are
+// archived without a task and can not be reparsed.
+func (r *ArchivesRepository) Reparse(archive *models.Archive) (count int, err error) {
+  if archive.TaskID == "" {
+    err = errors.New(fmt.Sprintf("archive %v has no task", archive.ID))
+    return
+  }
+
+  body, err := r.ArchivesRepository.Content(archive)
+  if err != nil {
+    return
+  }
+
+  postsRepository := *r.PostsRepository
+  postsRepository.ArchivedAt = archive.Timestamp
+  repliesRepository := *r.RepliesRepository
+  repliesRepository.ArchivedAt = archive.Timestamp
+
+  task, err := r.TasksRepository.Find(archive.TaskID)
+  if err != nil {
+    err = errors.New(fmt.Sprintf("archive %v task %v not found", archive.ID, archive.TaskID))
+    return
+  }
+
+  switch archive.Endpoint {
+  case "UserTweets", "UserMedia", "Followers", "Following":
+    userID, _ := task.Params["user_id"].(string)
+    user, err := r.UsersRepository.Find(userID)
+    if err != nil {
+      return 0, errors.New(fmt.Sprintf("archive %v user %v not found", archive.ID, userID))
+    }
+    switch archive.Endpoint {
+    case "UserTweets":
+      _, count = (&PostsRepository{
+        UsersRepository: r.UsersRepository,
+        PostsRepository: &postsRepository,
+      }).ExtractPage(user, body)
+    case "UserMedia":
+      _, count = (&UserMediaRepository{
+        UsersRepository: r.UsersRepository,
+        PostsRepository: &postsRepository,
+      }).ExtractPage(user, body)
+    default:
+      timeline := parsers.ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline.timeline")
+      count = len((&FollowsRepository{
+        UsersRepository: r.UsersRepository,
+      }).ExtractUsers(timeline))
+    }
+  case "TweetDetail":
+    postID, _ := task.Params["post_id"].(string)
+    post, err := postsRepository.Find(postID)
+    if err != nil {
+      return 0, errors.New(fmt.Sprintf("archive %v post %v not found", archive.ID, postID))
+    }
+    _, count = (&RepliesRepository{
+      UsersRepository:   r.UsersRepository,
+      RepliesRepository: &repliesRepository,
+    }).ExtractPage(post, body)
+  case "SearchTimeline":
+    _, count = (&SearchRepository{
+      UsersRepository:  r.UsersRepository,
+      PostsRepository:  &postsRepository,
+      SearchRepository: r.SearchRepository,
+    }).ExtractPage(task, body)
+  default:
+    err = errors.New(fmt.Sprintf("archive endpoint %v not supported", archive.Endpoint))
+  }
+
+  return
+}
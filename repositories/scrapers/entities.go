@@ -0,0 +1,20 @@
+package scrapers
+
+import (
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+)
+
+func ExtractEntities(tweet *parsers.Tweet) []*models.TweetEntity {
+  entities := make([]*models.TweetEntity, len(tweet.Entities))
+  for i, entity := range tweet.Entities {
+    entities[i] = &models.TweetEntity{
+      Type:  entity.Type,
+      Start: entity.Start,
+      End:   entity.End,
+      Value: entity.Value,
+      Url:   entity.Url,
+    }
+  }
+  return entities
+}
@@ -0,0 +1,207 @@
+package scrapers
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "log"
+  "net/http"
+  "strings"
+  "time"
+
+  "github.com/tidwall/gjson"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type FollowsRepository struct {
+  Db                 *gorm.DB
+  SessionsRepository *repositories.SessionsRepository
+  UsersRepository    *repositories.UsersRepository
+  FollowsRepository  *repositories.FollowsRepository
+  ArchivesRepository *repositories.ArchivesRepository
+  DriftRepository    *repositories.DriftRepository
+}
+
+func (r *FollowsRepository) Process(ctx context.Context, session *models.Session, user *models.User, params map[string]interface{}) (cursor string, count int, err error) {
+  var sessionData *repositories.SessionData
+  buf, _ := session.Data.MarshalJSON()
+  json.Unmarshal(buf, &sessionData)
+
+  var operation string
+  var section string
+  switch params["type"] {
+  case "followers":
+    operation = "Followers"
+    section = sessionData.SectionFollowers
+  case "following":
+    operation = "Following"
+    section = sessionData.SectionFollowing
+  default:
+    err = errors.New(fmt.Sprintf("follows type %v not valid", params["type"]))
+    return
+  }
+  if section == "" {
+    err = errors.New(fmt.Sprintf("%v section is empty", params["type"]))
+    return
+  }
+
+  variables := map[string]interface{}{}
+  variables["userId"] = fmt.Sprintf("%v", user.UserID)
+  variables["count"] = 20
+  if _, ok := params["cursors"]; ok {
+    cursors := params["cursors"].(map[string]interface{})
+    if _, ok := cursors[session.Account]; ok {
+      variables["cursor"] = cursors[session.Account].(string)
+    }
+  }
+  variables["includePromotedContent"] = false
+  features := map[string]interface{}{
+    "responsive_web_graphql_exclude_directive_enabled":                        true,
+    "verified_phone_label_enabled":                                            false,
+    "creator_subscriptions_tweet_preview_api_enabled":                         true,
+    "responsive_web_graphql_timeline_navigation_enabled":                      true,
+    "responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+    "c9s_tweet_anatomy_moderator_badge_enabled":                               true,
+    "tweetypie_unmention_optimization_enabled":                                true,
+    "responsive_web_edit_tweet_api_enabled":                                   true,
+    "graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+    "view_counts_everywhere_api_enabled":                                      true,
+    "longform_notetweets_consumption_enabled":                                 true,
+    "responsive_web_twitter_article_tweet_consumption_enabled":                true,
+    "tweet_awards_web_tipping_enabled":                                        false,
+    "freedom_of_speech_not_reach_fetch_enabled":                               true,
+    "standardized_nudges_misinfo":                                             true,
+    "tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+    "rweb_video_timestamps_enabled":                                           true,
+    "longform_notetweets_rich_text_read_enabled":                              true,
+    "longform_notetweets_inline_media_enabled":                                true,
+    "responsive_web_enhance_cards_enabled":                                    false,
+  }
+  features = r.SessionsRepository.Operations().Features(operation, features)
+
+  timestamp := time.Now().UnixMicro()
+  if session.UnblockedAt > timestamp {
+    err = errors.New("waiting for scrapper unblock")
+    return
+  }
+
+  url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/%v", section, operation)
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, sessionData.AccessToken)
+  q := req.URL.Query()
+  b1, _ := json.Marshal(variables)
+  b2, _ := json.Marshal(features)
+  q.Add("variables", string(b1))
+  q.Add("features", string(b2))
+  req.URL.RawQuery = q.Encode()
+  resp, err := clients.NewClient(r.SessionsRepository.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
+  if err != nil {
+    r.SessionsRepository.Unreachable(session, operation, err)
+    if session.Proxy != "" {
+      log.Println("request can not be send", common.ProxyName(string(session.Proxy)))
+    }
+    return
+  }
+  defer resp.Body.Close()
+
+  r.SessionsRepository.RateLimit(session, operation, resp)
+
+  if resp.StatusCode != http.StatusOK {
+    if resp.StatusCode == 401 {
+      r.SessionsRepository.Status(session, 0, fmt.Sprintf("%v %v", operation, resp.Status))
+    }
+    err = errors.New(
+      fmt.Sprintf(
+        "request error: account[%s] status[%s] code[%d]",
+        session.Account,
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+    return
+  }
+
+  body, _ := io.ReadAll(resp.Body)
+  r.ArchivesRepository.Create(ctx, session, operation, body)
+  timeline := parsers.ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline.timeline")
+  if err = r.DriftRepository.Report(operation, timeline); err != nil {
+    return
+  }
+  for _, other := range r.ExtractUsers(timeline) {
+    if params["type"] == "followers" {
+      r.FollowsRepository.Apply(user.ID, other.ID, timestamp)
+    } else {
+      r.FollowsRepository.Apply(other.ID, user.ID, timestamp)
+    }
+    count++
+  }
+  cursor = timeline.Cursor("Bottom")
+
+  log.Println("scrapers follows result", params["type"], count, variables["cursor"], cursor)
+
+  if count > 0 {
+    params["applied"] = FollowsApplied(params) + count
+  }
+  if timeline.Unavailable == "" && len(timeline.Users()) == 0 && (cursor == "" || cursor == variables["cursor"] || strings.HasPrefix(cursor, "0|")) {
+    params["completed"] = true
+  }
+
+  if count == 0 {
+    cursor = ""
+  }
+
+  return
+}
+
+func (r *FollowsRepository) ExtractUsers(timeline *parsers.Timeline) (users []*models.User) {
+  scrapersUsersRepository := &UsersRepository{
+    UsersRepository: r.UsersRepository,
+  }
+  for _, info := range timeline.Users() {
+    user, err := scrapersUsersRepository.Apply(info)
+    if err != nil {
+      log.Println("user info extract error", err)
+      continue
+    }
+    users = append(users, user)
+  }
+  return
+}
+
+// FollowsApplied counts the edges the current pass has applied so far.
+func FollowsApplied(params map[string]interface{}) int {
+  switch value := params["applied"].(type) {
+  case float64:
+    return int(value)
+  case int:
+    return value
+  }
+  return 0
+}
+
+// FollowsCompleted tells whether the pass has reached the real last page of
+// the list after applying edges, only then the edges not seen are unfollowed.
+// A blank page of a protected account or a page of users which could not be
+// extracted ends the pass too, but must not sweep the known edges.
+func FollowsCompleted(params map[string]interface{}) bool {
+  return params["completed"] == true && FollowsApplied(params) > 0
+}
+
+// FollowsOperation maps the follows task type to its graphql operation.
+func FollowsOperation(followType interface{}) string {
+  switch followType {
+  case "followers":
+    return "Followers"
+  case "following":
+    return "Following"
+  }
+  return ""
+}
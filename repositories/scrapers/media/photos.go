@@ -1,6 +1,7 @@
 package media
 
 import (
+  "context"
   "crypto/sha1"
   "encoding/hex"
   "errors"
@@ -9,7 +10,6 @@ import (
   "image"
   "io"
   "math/rand"
-  "net"
   "net/http"
   "os"
   "time"
@@ -22,6 +22,7 @@ import (
   "github.com/rs/xid"
   "gorm.io/gorm"
 
+  "scraper.local/twitter-scraper/clients"
   "scraper.local/twitter-scraper/common"
   models "scraper.local/twitter-scraper/models/media"
 )
@@ -30,20 +31,9 @@ type PhotosRepository struct {
   Db *gorm.DB
 }
 
-func (r *PhotosRepository) Download(url string, urlSha1 string) (err error) {
-  tr := &http.Transport{
-    DisableKeepAlives: true,
-  }
-  session := &net.Dialer{}
-  tr.DialContext = session.DialContext
-
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(30) * time.Second,
-  }
-
-  req, _ := http.NewRequest("GET", url, nil)
-  resp, err := httpClient.Do(req)
+func (r *PhotosRepository) Download(ctx context.Context, url string, urlSha1 string) (err error) {
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  resp, err := clients.NewClient(proxy(r.Db), time.Duration(30)*time.Second).Do(ctx, req)
   if err != nil {
     return
   }
@@ -52,10 +42,9 @@ func (r *PhotosRepository) Download(url string, urlSha1 string) (err error) {
   if resp.StatusCode != http.StatusOK {
     err = errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
     return
@@ -167,20 +156,9 @@ func (r *PhotosRepository) Download(url string, urlSha1 string) (err error) {
   return
 }
 
-func (r *PhotosRepository) Config(url string) (config image.Config, err error) {
-  tr := &http.Transport{
-    DisableKeepAlives: true,
-  }
-  session := &net.Dialer{}
-  tr.DialContext = session.DialContext
-
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(30) * time.Second,
-  }
-
-  req, _ := http.NewRequest("GET", url, nil)
-  resp, err := httpClient.Do(req)
+func (r *PhotosRepository) Config(ctx context.Context, url string) (config image.Config, err error) {
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  resp, err := clients.NewClient(proxy(r.Db), time.Duration(30)*time.Second).Do(ctx, req)
   if err != nil {
     return
   }
@@ -189,10 +167,9 @@ func (r *PhotosRepository) Config(url string) (config image.Config, err error) {
   if resp.StatusCode != http.StatusOK {
     err = errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
     return
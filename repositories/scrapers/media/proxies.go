@@ -0,0 +1,21 @@
+package media
+
+import (
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+// proxy picks the proxy of a download from the pool of
+// SCRAPER_PROXY_MEDIA_GROUP, or of SCRAPER_PROXY_GROUP when it is unset.
+func proxy(db *gorm.DB) string {
+  group := common.GetEnvString("SCRAPER_PROXY_MEDIA_GROUP")
+  if group == "" {
+    group = common.GetEnvString("SCRAPER_PROXY_GROUP")
+  }
+  proxies := &repositories.ProxiesRepository{
+    Db: db,
+  }
+  return proxies.Pick(group)
+}
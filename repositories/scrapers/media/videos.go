@@ -2,6 +2,7 @@ package media
 
 import (
   "bufio"
+  "context"
   "crypto/sha1"
   "encoding/hex"
   "errors"
@@ -10,7 +11,6 @@ import (
   "io"
   "log"
   "math/rand"
-  "net"
   "net/http"
   "os"
   "os/exec"
@@ -22,6 +22,7 @@ import (
   "github.com/rs/xid"
   "gorm.io/gorm"
 
+  "scraper.local/twitter-scraper/clients"
   "scraper.local/twitter-scraper/common"
   models "scraper.local/twitter-scraper/models/media"
 )
@@ -30,20 +31,9 @@ type VideosRepository struct {
   Db *gorm.DB
 }
 
-func (r *VideosRepository) Download(url string, urlSha1 string) (err error) {
-  tr := &http.Transport{
-    DisableKeepAlives: true,
-  }
-  session := &net.Dialer{}
-  tr.DialContext = session.DialContext
-
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(15) * time.Minute,
-  }
-
-  req, _ := http.NewRequest("GET", url, nil)
-  resp, err := httpClient.Do(req)
+func (r *VideosRepository) Download(ctx context.Context, url string, urlSha1 string) (err error) {
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  resp, err := clients.NewClient(proxy(r.Db), time.Duration(15)*time.Minute).Do(ctx, req)
   if err != nil {
     return
   }
@@ -52,10 +42,9 @@ func (r *VideosRepository) Download(url string, urlSha1 string) (err error) {
   if resp.StatusCode != http.StatusOK {
     err = errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
     return
@@ -0,0 +1,17 @@
+package scrapers
+
+import (
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+)
+
+func ExtractMetrics(tweet *parsers.Tweet) *models.Metric {
+  return &models.Metric{
+    FavoriteCount: tweet.Metrics.FavoriteCount,
+    RetweetCount:  tweet.Metrics.RetweetCount,
+    ReplyCount:    tweet.Metrics.ReplyCount,
+    QuoteCount:    tweet.Metrics.QuoteCount,
+    BookmarkCount: tweet.Metrics.BookmarkCount,
+    ViewCount:     tweet.Metrics.ViewCount,
+  }
+}
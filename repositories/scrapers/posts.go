@@ -1,13 +1,14 @@
 package scrapers
 
 import (
+  "context"
   "encoding/json"
   "errors"
   "fmt"
   "io"
   "log"
-  "net"
   "net/http"
+  "regexp"
   "strconv"
   "strings"
   "time"
@@ -15,8 +16,11 @@ import (
   "github.com/tidwall/gjson"
   "gorm.io/gorm"
 
+  "scraper.local/twitter-scraper/clients"
   "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
   "scraper.local/twitter-scraper/repositories"
 )
 
@@ -25,9 +29,22 @@ type PostsRepository struct {
   SessionsRepository *repositories.SessionsRepository
   UsersRepository    *repositories.UsersRepository
   PostsRepository    *repositories.PostsRepository
+  ArchivesRepository *repositories.ArchivesRepository
+  DriftRepository    *repositories.DriftRepository
 }
 
-func (r *PostsRepository) Process(session *models.Session, user *models.User, params map[string]interface{}) (cursor string, count int, err error) {
+func (r *PostsRepository) Process(ctx context.Context, session *models.Session, user *models.User, params map[string]interface{}) (cursor string, count int, err error) {
+  if mediaOnly, ok := params["media_only"].(bool); ok && mediaOnly {
+    return (&UserMediaRepository{
+      Db:                 r.Db,
+      SessionsRepository: r.SessionsRepository,
+      UsersRepository:    r.UsersRepository,
+      PostsRepository:    r.PostsRepository,
+      ArchivesRepository: r.ArchivesRepository,
+      DriftRepository:    r.DriftRepository,
+    }).Process(ctx, session, user, params)
+  }
+
   var sessionData *repositories.SessionData
   buf, _ := session.Data.MarshalJSON()
   json.Unmarshal(buf, &sessionData)
@@ -68,50 +85,205 @@ func (r *PostsRepository) Process(session *models.Session, user *models.User, pa
     "longform_notetweets_inline_media_enabled":                                true,
     "responsive_web_enhance_cards_enabled":                                    false,
   }
+  features = r.SessionsRepository.Operations().Features("UserTweets", features)
   fieldToggles := map[string]interface{}{
     "withArticleRichContentState": false,
   }
-  tr := &http.Transport{
-    DisableKeepAlives: true,
+
+  timestamp := time.Now().UnixMicro()
+  if session.UnblockedAt > timestamp {
+    err = errors.New("waiting for scrapper unblock")
+    return
   }
-  if session.Slot > 0 {
-    tr.DialContext = (&common.ProxySession{
-      Proxy: fmt.Sprintf("socks5://127.0.0.1:%d?timeout=30s", 2080+session.Slot),
-    }).DialContext
-  } else {
-    tr.DialContext = (&net.Dialer{}).DialContext
+
+  url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/UserTweets", sessionData.SectionPosts)
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, sessionData.AccessToken)
+  q := req.URL.Query()
+  b1, _ := json.Marshal(variables)
+  b2, _ := json.Marshal(features)
+  b3, _ := json.Marshal(fieldToggles)
+  q.Add("variables", string(b1))
+  q.Add("features", string(b2))
+  q.Add("fieldToggles", string(b3))
+  req.URL.RawQuery = q.Encode()
+  resp, err := clients.NewClient(r.SessionsRepository.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
+  if err != nil {
+    r.SessionsRepository.Unreachable(session, "UserTweets", err)
+    if session.Proxy != "" {
+      log.Println("request can not be send", common.ProxyName(string(session.Proxy)))
+    }
+    return
   }
+  defer resp.Body.Close()
 
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(15) * time.Second,
+  r.SessionsRepository.RateLimit(session, "UserTweets", resp)
+
+  if resp.StatusCode != http.StatusOK {
+    if resp.StatusCode == 401 {
+      r.SessionsRepository.Status(session, 0, fmt.Sprintf("UserTweets %v", resp.Status))
+    }
+    err = errors.New(
+      fmt.Sprintf(
+        "request error: account[%s] status[%s] code[%d]",
+        session.Account,
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+    return
   }
 
-  timestamp := time.Now().UnixMicro()
-  if session.UnblockedAt > timestamp {
-    err = errors.New("waiting for scrapper unblock")
+  body, _ := io.ReadAll(resp.Body)
+  r.ArchivesRepository.Create(ctx, session, "UserTweets", body)
+  timeline, count := r.ExtractPage(user, body)
+  if err = r.DriftRepository.Report("UserTweets", timeline); err != nil {
     return
   }
+  cursor = timeline.Cursor("Bottom")
 
-  headers := map[string]string{
-    "User-Agent":    session.Agent,
-    "cookie":        session.Cookie,
-    "Authorization": fmt.Sprintf("Bearer %v", sessionData.AccessToken),
+  log.Println("scrapers posts result", count, variables["cursor"], cursor)
+
+  if count == 0 {
+    cursor = ""
   }
 
-  for _, p := range strings.Split(headers["cookie"], ";") {
-    parts := strings.SplitN(p, "=", 2)
-    if strings.Trim(parts[0], " ") == "ct0" {
-      headers["X-Csrf-Token"] = strings.Trim(parts[1], " ")
-      break
+  return
+}
+
+// ExtractPage parses a UserTweets page, the pinned tweet shows up on every
+// page so it is not counted.
+func (r *PostsRepository) ExtractPage(user *models.User, body []byte) (timeline *parsers.Timeline, count int) {
+  timeline = parsers.ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline_v2.timeline")
+  for _, entry := range timeline.Entries {
+    if entry.Tweet == nil {
+      continue
+    }
+    if !entry.Pinned {
+      count++
+    }
+    if _, err := r.ExtractTimelinePost(user, entry.Tweet); err != nil {
+      log.Println("post extract error", entry.EntryID, err)
     }
   }
+  return
+}
 
-  url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/UserTweets", sessionData.SectionPosts)
-  req, _ := http.NewRequest("GET", url, nil)
-  for key, val := range headers {
-    req.Header.Set(key, val)
+func (r *PostsRepository) Get(ctx context.Context, session *models.Session, twitterID int64) (post *models.Post, err error) {
+  result, err := r.Result(ctx, session, twitterID, false)
+  if err != nil {
+    return
+  }
+  tweet := parsers.ParseTweet(result)
+  if !tweet.IsAvailable() {
+    err = errors.New(fmt.Sprintf("tweet %v not found %v", twitterID, tweet.Reason))
+    return
+  }
+  return r.ExtractPost(tweet)
+}
+
+// Verify re-checks a stored post, deleted, unavailable and withheld tweets
+// are reported with their removal reason. Only a tombstone or an empty result
+// means deleted, a typename not known is reported as drift and the post is
+// left untouched.
+func (r *PostsRepository) Verify(ctx context.Context, session *models.Session, post *models.Post) (reason int, err error) {
+  result, err := r.Result(ctx, session, post.TwitterID, true)
+  if err != nil {
+    return
   }
+  tweet := parsers.ParseTweet(result)
+  switch tweet.Typename {
+  case "Tweet":
+    if len(tweet.WithheldInCountries) > 0 {
+      reason = config.POST_REMOVED_WITHHELD
+    } else if _, err := r.ExtractPost(tweet); err != nil {
+      log.Println("verify post extract error", post.TwitterID, err)
+    }
+  case "TweetUnavailable":
+    reason = config.POST_REMOVED_UNAVAILABLE
+    if strings.Contains(strings.ToLower(tweet.Reason), "withheld") {
+      reason = config.POST_REMOVED_WITHHELD
+    }
+  case "TweetTombstone", "":
+    reason = config.POST_REMOVED_DELETED
+    if strings.Contains(strings.ToLower(tweet.Reason), "withheld") {
+      reason = config.POST_REMOVED_WITHHELD
+    }
+  default:
+    err = r.DriftRepository.Unrecognized("TweetResultByRestId", "tweet:"+tweet.Typename)
+  }
+  return
+}
+
+// Result requests a single tweet, only TweetResultByRestId is used when
+// strict, the TweetDetail fallback can not tell a removed tweet apart.
+func (r *PostsRepository) Result(ctx context.Context, session *models.Session, twitterID int64, strict bool) (result gjson.Result, err error) {
+  var sessionData *repositories.SessionData
+  buf, _ := session.Data.MarshalJSON()
+  json.Unmarshal(buf, &sessionData)
+
+  var operation string
+  var section string
+  variables := map[string]interface{}{}
+  if sessionData.SectionTweet != "" {
+    operation = "TweetResultByRestId"
+    section = sessionData.SectionTweet
+    variables["tweetId"] = fmt.Sprint(twitterID)
+    variables["withCommunity"] = false
+    variables["includePromotedContent"] = false
+    variables["withVoice"] = false
+  } else if sessionData.SectionReplies != "" && !strict {
+    operation = "TweetDetail"
+    section = sessionData.SectionReplies
+    variables["focalTweetId"] = fmt.Sprint(twitterID)
+    variables["with_rux_injections"] = false
+    variables["includePromotedContent"] = false
+    variables["withCommunity"] = true
+    variables["withQuickPromoteEligibilityTweetFields"] = false
+    variables["withBirdwatchNotes"] = false
+    variables["withVoice"] = true
+    variables["withV2Timeline"] = true
+  } else {
+    err = errors.New("tweet section is empty")
+    return
+  }
+  features := map[string]interface{}{
+    "responsive_web_graphql_exclude_directive_enabled":                        true,
+    "verified_phone_label_enabled":                                            false,
+    "creator_subscriptions_tweet_preview_api_enabled":                         true,
+    "responsive_web_graphql_timeline_navigation_enabled":                      true,
+    "responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+    "communities_web_enable_tweet_community_results_fetch":                    true,
+    "c9s_tweet_anatomy_moderator_badge_enabled":                               true,
+    "tweetypie_unmention_optimization_enabled":                                true,
+    "responsive_web_edit_tweet_api_enabled":                                   true,
+    "graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+    "view_counts_everywhere_api_enabled":                                      true,
+    "longform_notetweets_consumption_enabled":                                 true,
+    "responsive_web_twitter_article_tweet_consumption_enabled":                true,
+    "tweet_awards_web_tipping_enabled":                                        false,
+    "freedom_of_speech_not_reach_fetch_enabled":                               true,
+    "standardized_nudges_misinfo":                                             true,
+    "tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+    "rweb_video_timestamps_enabled":                                           true,
+    "longform_notetweets_rich_text_read_enabled":                              true,
+    "longform_notetweets_inline_media_enabled":                                true,
+    "responsive_web_enhance_cards_enabled":                                    false,
+  }
+  features = r.SessionsRepository.Operations().Features(operation, features)
+  fieldToggles := map[string]interface{}{
+    "withArticleRichContentState": false,
+  }
+
+  timestamp := time.Now().UnixMicro()
+  if session.UnblockedAt > timestamp {
+    err = errors.New("waiting for scrapper unblock")
+    return
+  }
+
+  url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/%v", section, operation)
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, sessionData.AccessToken)
   q := req.URL.Query()
   b1, _ := json.Marshal(variables)
   b2, _ := json.Marshal(features)
@@ -120,310 +292,223 @@ func (r *PostsRepository) Process(session *models.Session, user *models.User, pa
   q.Add("features", string(b2))
   q.Add("fieldToggles", string(b3))
   req.URL.RawQuery = q.Encode()
-  resp, err := httpClient.Do(req)
+  resp, err := clients.NewClient(r.SessionsRepository.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
   if err != nil {
-    if session.Slot > 0 {
-      log.Println("request can not be send", 2080+session.Slot)
+    r.SessionsRepository.Unreachable(session, operation, err)
+    if session.Proxy != "" {
+      log.Println("request can not be send", common.ProxyName(string(session.Proxy)))
     }
     return
   }
   defer resp.Body.Close()
 
+  r.SessionsRepository.RateLimit(session, operation, resp)
+
   if resp.StatusCode != http.StatusOK {
-    if resp.StatusCode == 401 {
-      r.SessionsRepository.Update(session, "status", 0)
-    }
-    if resp.StatusCode == 429 {
-      r.SessionsRepository.Update(session, "unblocked_at", timestamp+900000000)
+    if resp.StatusCode == 401 || (resp.StatusCode == 403 && session.Type == config.SESSIONS_TYPE_GUEST) {
+      r.SessionsRepository.Status(session, 0, fmt.Sprintf("%v %v", operation, resp.Status))
     }
     err = errors.New(
       fmt.Sprintf(
-        "request error: account[%s] status[%s] code[%d] cookie[%v]",
+        "request error: account[%s] status[%s] code[%d]",
         session.Account,
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
     return
   }
 
   body, _ := io.ReadAll(resp.Body)
-  container := gjson.GetBytes(body, "data.user.result.timeline_v2.timeline")
-  container.Get("instructions").ForEach(func(_, s gjson.Result) bool {
-    if s.Get("type").Str == "TimelinePinEntry" {
-      if s.Get("entry.content.itemContent.itemType").Str != "TimelineTweet" {
-        return true
-      }
-      twitterID, _ := strconv.ParseInt(strings.Trim(s.Get("entry.content.itemContent.tweet_results.result.rest_id").Raw, "\""), 10, 64)
-      if twitterID == 0 {
-        log.Println("twitter_id zero TimelinePinEntry")
-        return true
-      }
-      statusID, _ := strconv.ParseInt(strings.Trim(s.Get("entry.content.itemContent.tweet_results.result.legacy.quoted_status_id_str").Raw, "\""), 10, 64)
-      content := s.Get("entry.content.itemContent.tweet_results.result.legacy.full_text").Str
-      createdAt, _ := time.Parse(time.RubyDate, s.Get("entry.content.itemContent.tweet_results.result.legacy.created_at").Str)
-      media := &MediaInfo{}
-      err := r.ExtractUserInfo(user, s.Get("entry.content.itemContent.tweet_results.result.core.user_results.result"))
-      if err != nil {
-        log.Println("user info extract error", err)
-        return true
-      }
-      s.Get("entry.content.itemContent.tweet_results.result.legacy.entities.media").ForEach(func(_, s gjson.Result) bool {
-        if s.Get("type").Str == "photo" {
-          media.Photos = append(media.Photos, &PhotoInfo{
-            Url: s.Get("media_url_https").Str,
-          })
-        }
-        if s.Get("type").Str == "video" {
-          videoInfo := &VideoInfo{}
-          videoInfo.Cover = s.Get("media_url_https").Str
-          videoInfo.DurationMillis, _ = strconv.Atoi(s.Get("video_info.duration_millis").Raw)
-          s.Get("video_info.aspect_ratio").ForEach(func(_, s gjson.Result) bool {
-            videoInfo.AspectRatio = append(videoInfo.AspectRatio, int(s.Value().(float64)))
-            return true
-          })
-          s.Get("video_info.variants").ForEach(func(_, s gjson.Result) bool {
-            variant := &VideoVariant{}
-            if s.Get("bitrate").Raw != "" {
-              variant.Bitrate = int(s.Get("bitrate").Value().(float64))
-            }
-            variant.ContentType = s.Get("content_type").Str
-            variant.Url = s.Get("url").Str
-            videoInfo.Variants = append(videoInfo.Variants, variant)
-            return true
-          })
-          media.Videos = append(media.Videos, videoInfo)
-        }
-        return true
-      })
-      status := 1
-      if media.Photos == nil && media.Videos == nil {
-        status = 3
-      }
-      post, err := r.PostsRepository.Get(twitterID)
-      if errors.Is(err, gorm.ErrRecordNotFound) {
-        r.PostsRepository.Create(
-          user.ID,
-          twitterID,
-          statusID,
-          content,
-          common.JSONMap(&media),
-          createdAt.UnixMilli(),
-          status,
-        )
-      } else {
-        if post.Status != 1 && post.Status != 2 && post.Status != 3 {
-          r.PostsRepository.Updates(post, map[string]interface{}{
-            "user_id":   user.ID,
-            "status_id": statusID,
-            "content":   content,
-            "media":     common.JSONMap(&media),
-            "status":    status,
-          })
-        }
-      }
-      return true
+
+  if operation == "TweetResultByRestId" {
+    if !gjson.GetBytes(body, "data.tweetResult").Exists() {
+      err = errors.New(fmt.Sprintf("tweet %v result missing %v", twitterID, gjson.GetBytes(body, "errors.0.message").Str))
+      return
     }
-    if s.Get("type").Str == "TimelineAddEntries" {
-      s.Get("entries").ForEach(func(_, s gjson.Result) bool {
-        if s.Get("content.entryType").Str == "TimelineTimelineItem" {
-          if s.Get("content.itemContent.tweet_results.result.__typename").Str == "Tweet" {
-            twitterID, _ := strconv.ParseInt(strings.Trim(s.Get("content.itemContent.tweet_results.result.legacy.id_str").Raw, "\""), 10, 64)
-            if twitterID == 0 {
-              log.Println("twitter_id zero Tweet", s.Get("content.itemContent").Raw)
-              return true
-            }
-            statusID, _ := strconv.ParseInt(strings.Trim(s.Get("content.itemContent.tweet_results.result.legacy.quoted_status_id_str").Raw, "\""), 10, 64)
-            content := s.Get("content.itemContent.tweet_results.result.legacy.full_text").Str
-            createdAt, _ := time.Parse(time.RubyDate, s.Get("content.itemContent.tweet_results.result.legacy.created_at").Str)
-            log.Println("content", twitterID, statusID, content, createdAt)
-            count++
-            media := &MediaInfo{}
-            err := r.ExtractUserInfo(user, s.Get("content.itemContent.tweet_results.result.core.user_results.result"))
-            if err != nil {
-              log.Println("user info extract error", err)
-              return true
-            }
-            s.Get("content.itemContent.tweet_results.result.legacy.entities.media").ForEach(func(_, s gjson.Result) bool {
-              if s.Get("type").Str == "photo" {
-                media.Photos = append(media.Photos, &PhotoInfo{
-                  Url: s.Get("media_url_https").Str,
-                })
-              }
-              if s.Get("type").Str == "video" {
-                videoInfo := &VideoInfo{}
-                videoInfo.DurationMillis, _ = strconv.Atoi(strings.Trim(s.Get("video_info.duration_millis").Str, "\""))
-                s.Get("video_info.aspect_ratio").ForEach(func(_, s gjson.Result) bool {
-                  videoInfo.AspectRatio = append(videoInfo.AspectRatio, int(s.Value().(float64)))
-                  return true
-                })
-                s.Get("video_info.variants").ForEach(func(_, s gjson.Result) bool {
-                  variant := &VideoVariant{}
-                  if s.Get("bitrate").Raw != "" {
-                    variant.Bitrate = int(s.Get("bitrate").Value().(float64))
-                  }
-                  variant.ContentType = s.Get("content_type").Str
-                  variant.Url = s.Get("url").Str
-                  videoInfo.Variants = append(videoInfo.Variants, variant)
-                  return true
-                })
-                media.Videos = append(media.Videos, videoInfo)
-              }
-              return true
-            })
-            status := 1
-            if media.Photos == nil && media.Videos == nil {
-              status = 3
-            }
-            post, err := r.PostsRepository.Get(twitterID)
-            if errors.Is(err, gorm.ErrRecordNotFound) {
-              r.PostsRepository.Create(
-                user.ID,
-                twitterID,
-                statusID,
-                content,
-                common.JSONMap(&media),
-                createdAt.UnixMilli(),
-                status,
-              )
-            } else {
-              if post.Status != 1 && post.Status != 2 && post.Status != 3 {
-                r.PostsRepository.Updates(post, map[string]interface{}{
-                  "user_id":   user.ID,
-                  "status_id": statusID,
-                  "content":   content,
-                  "media":     common.JSONMap(&media),
-                  "status":    status,
-                })
-              }
-            }
-            return true
-          }
-          if s.Get("content.itemContent.tweet_results.result.__typename").Str == "TweetWithVisibilityResults" {
-            twitterID, _ := strconv.ParseInt(strings.Trim(s.Get("content.itemContent.tweet_results.result.tweet.rest_id").Raw, "\""), 10, 64)
-            if twitterID == 0 {
-              log.Println("twitter_id zero TweetWithVisibilityResults", s.Get("content.itemContent").Raw)
-              return true
-            }
-            statusID, _ := strconv.ParseInt(strings.Trim(s.Get("content.itemContent.tweet_results.result.tweet.legacy.quoted_status_id_str").Raw, "\""), 10, 64)
-            content := s.Get("content.itemContent.tweet_results.result.tweet.legacy.full_text").Str
-            createdAt, _ := time.Parse(time.RubyDate, s.Get("content.itemContent.tweet_results.result.tweet.legacy.created_at").Str)
-            count++
-            media := &MediaInfo{}
-            err := r.ExtractUserInfo(user, s.Get("content.itemContent.tweet_results.result.core.user_results.result"))
-            if err != nil {
-              //log.Println("user info extract error", err)
-              return true
-            }
-            s.Get("content.itemContent.tweet_results.result.tweet.legacy.entities.media").ForEach(func(_, s gjson.Result) bool {
-              if s.Get("type").Str == "photo" {
-                media.Photos = append(media.Photos, &PhotoInfo{
-                  Url: s.Get("media_url_https").Str,
-                })
-              }
-              if s.Get("type").Str == "video" {
-                videoInfo := &VideoInfo{}
-                videoInfo.DurationMillis, _ = strconv.Atoi(strings.Trim(s.Get("video_info.duration_millis").Str, "\""))
-                s.Get("video_info.aspect_ratio").ForEach(func(_, s gjson.Result) bool {
-                  videoInfo.AspectRatio = append(videoInfo.AspectRatio, int(s.Value().(float64)))
-                  return true
-                })
-                s.Get("video_info.variants").ForEach(func(_, s gjson.Result) bool {
-                  variant := &VideoVariant{}
-                  if s.Get("bitrate").Raw != "" {
-                    variant.Bitrate = int(s.Get("bitrate").Value().(float64))
-                  }
-                  variant.ContentType = s.Get("content_type").Str
-                  variant.Url = s.Get("url").Str
-                  videoInfo.Variants = append(videoInfo.Variants, variant)
-                  return true
-                })
-                media.Videos = append(media.Videos, videoInfo)
-              }
-              return true
-            })
-            status := 1
-            if media.Photos == nil && media.Videos == nil {
-              status = 3
-            }
-            post, err := r.PostsRepository.Get(twitterID)
-            if errors.Is(err, gorm.ErrRecordNotFound) {
-              r.PostsRepository.Create(
-                user.ID,
-                twitterID,
-                statusID,
-                content,
-                common.JSONMap(&media),
-                createdAt.UnixMilli(),
-                status,
-              )
-            } else {
-              if post.Status != 1 && post.Status != 2 && post.Status != 3 {
-                r.PostsRepository.Updates(post, map[string]interface{}{
-                  "user_id":   user.ID,
-                  "status_id": statusID,
-                  "content":   content,
-                  "media":     common.JSONMap(&media),
-                  "status":    status,
-                })
-              }
-            }
-            return true
-          }
-        }
-        if s.Get("content.entryType").Str == "TimelineTimelineCursor" {
-          if s.Get("content.cursorType").Str == "Bottom" {
-            cursor = s.Get("content.value").Str
+    result = gjson.GetBytes(body, "data.tweetResult.result")
+  } else {
+    entryID := fmt.Sprintf("tweet-%v", twitterID)
+    container := gjson.GetBytes(body, "data.threaded_conversation_with_injections_v2")
+    container.Get("instructions").ForEach(func(_, s gjson.Result) bool {
+      if s.Get("type").Str == "TimelineAddEntries" {
+        s.Get("entries").ForEach(func(_, s gjson.Result) bool {
+          if s.Get("entryId").Str == entryID {
+            result = s.Get("content.itemContent.tweet_results.result")
+            return false
           }
-        }
-        return true
-      })
-    }
-    return true
-  })
-
-  log.Println("scrapers posts result", count, variables["cursor"], cursor)
-
-  if count == 0 {
-    cursor = ""
+          return true
+        })
+      }
+      return !result.Exists()
+    })
   }
 
   return
 }
 
-func (r *PostsRepository) ExtractUserInfo(user *models.User, s gjson.Result) (err error) {
-  account := s.Get("legacy.screen_name").Str
-  userID, _ := strconv.ParseInt(strings.Trim(s.Get("rest_id").Raw, "\""), 10, 64)
-  name := s.Get("legacy.name").Str
-  description := s.Get("legacy.description").Str
-  avatar := strings.Replace(s.Get("legacy.profile_image_url_https").Str, "_normal.", ".", 1)
-  favouritesCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.favourites_count").Raw, "\""))
-  followersCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.followers_count").Raw, "\""))
-  friendsCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.friends_count").Raw, "\""))
-  listedCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.listed_count").Raw, "\""))
-  mediaCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.media_count").Raw, "\""))
-  createdAt, _ := time.Parse(time.RubyDate, s.Get("legacy.created_at").Str)
-
-  if userID != user.UserID {
-    //log.Println("extract info", userID, twitterID, user.UserID, user.TwitterID, name, description)
+// ExtractTimelinePost only accepts tweets authored by the timeline owner,
+// promoted tweets of other accounts are skipped.
+func (r *PostsRepository) ExtractTimelinePost(user *models.User, tweet *parsers.Tweet) (post *models.Post, err error) {
+  if tweet.User == nil || tweet.User.UserID != user.UserID {
     err = errors.New("user user_id not match")
     return
   }
+  return r.ExtractPost(tweet)
+}
+
+func (r *PostsRepository) ExtractPost(tweet *parsers.Tweet) (post *models.Post, err error) {
+  return r.extractPost(tweet, false)
+}
+
+// extractPost keeps the tweets referenced by a retweet or a quote as
+// referenced posts, they are not published until the tweet is seen on its
+// own, so the replies and media of untracked accounts are not scraped.
+func (r *PostsRepository) extractPost(tweet *parsers.Tweet, referenced bool) (post *models.Post, err error) {
+  if !tweet.IsAvailable() {
+    err = errors.New(fmt.Sprintf("tweet typename %v not supported", tweet.Typename))
+    return
+  }
+  if tweet.User == nil {
+    err = errors.New(fmt.Sprintf("tweet %v user is empty", tweet.TwitterID))
+    return
+  }
 
-  r.UsersRepository.Updates(user, map[string]interface{}{
-    "account":          account,
-    "user_id":          userID,
-    "name":             name,
-    "description":      description,
-    "avatar":           avatar,
-    "favourites_count": favouritesCount,
-    "followers_count":  followersCount,
-    "friends_count":    friendsCount,
-    "listed_count":     listedCount,
-    "media_count":      mediaCount,
-    "timestamp":        createdAt.UnixMilli(),
-  })
+  twitterID := tweet.TwitterID
+  statusID := tweet.QuotedStatusID
+  content := tweet.Content
+  editControl := tweet.EditControl
 
-  return
+  user, err := (&UsersRepository{
+    UsersRepository: r.UsersRepository,
+  }).Apply(tweet.User)
+  if err != nil {
+    return
+  }
+
+  // the referenced tweet is stored as its own post with its own author,
+  // a retweet keeps no media so it is not synced as the account's own.
+  kind := config.POST_KIND_ORIGINAL
+  var referenceID string
+  var reference *models.Post
+  if tweet.Retweeted != nil {
+    kind = config.POST_KIND_RETWEET
+    reference, err = r.extractPost(tweet.Retweeted, true)
+  } else if tweet.Quoted != nil {
+    kind = config.POST_KIND_QUOTE
+    reference, err = r.extractPost(tweet.Quoted, true)
+  } else if tweet.InReplyToStatusID > 0 {
+    kind = config.POST_KIND_REPLY
+    reference, _ = r.PostsRepository.Get(tweet.InReplyToStatusID)
+  }
+  if err != nil {
+    log.Println("reference post extract error", twitterID, err)
+    err = nil
+  }
+  if reference != nil {
+    referenceID = reference.ID
+  }
+
+  media := &MediaInfo{}
+  if kind != config.POST_KIND_RETWEET {
+    media = tweet.Media
+  }
+  status := 1
+  if media.IsEmpty() {
+    status = 3
+  }
+  if referenced {
+    status = config.POST_STATUS_REFERENCED
+  }
+
+  post, err = r.PostsRepository.Get(twitterID)
+  if errors.Is(err, gorm.ErrRecordNotFound) && editControl.InitialID > 0 && editControl.InitialID != twitterID {
+    // an edited tweet takes over the row of its earlier version
+    post, err = r.PostsRepository.GetByInitialID(editControl.InitialID)
+    if errors.Is(err, gorm.ErrRecordNotFound) {
+      post, err = r.PostsRepository.Get(editControl.InitialID)
+    }
+    if err == nil && post.TwitterID > twitterID {
+      return
+    }
+  }
+  if errors.Is(err, gorm.ErrRecordNotFound) {
+    _, err = r.PostsRepository.Create(
+      user.ID,
+      twitterID,
+      statusID,
+      kind,
+      referenceID,
+      content,
+      common.JSONMap(&media),
+      tweet.Timestamp,
+      status,
+    )
+    if err != nil {
+      return
+    }
+  } else if err != nil {
+    return
+  } else if post.Status != 1 && post.Status != 2 && post.Status != 3 && !referenced {
+    r.PostsRepository.Updates(post, map[string]interface{}{
+      "user_id":      user.ID,
+      "twitter_id":   twitterID,
+      "status_id":    statusID,
+      "kind":         kind,
+      "reference_id": referenceID,
+      "content":      content,
+      "media":        common.JSONMap(&media),
+      "status":       status,
+    })
+  } else {
+    values := map[string]interface{}{}
+    if post.TwitterID != twitterID {
+      values["twitter_id"] = twitterID
+      values["status_id"] = statusID
+      values["content"] = content
+      values["media"] = common.JSONMap(&media)
+    } else if post.Content != content {
+      values["content"] = content
+    }
+    if post.Kind == 0 {
+      values["kind"] = kind
+      values["reference_id"] = referenceID
+    }
+    if len(values) > 0 {
+      r.PostsRepository.Updates(post, values)
+    }
+  }
+
+  if editControl.InitialID > 0 {
+    editTweetIDs := make([]string, len(editControl.EditTweetIDs))
+    for i, editTweetID := range editControl.EditTweetIDs {
+      editTweetIDs[i] = strconv.FormatInt(editTweetID, 10)
+    }
+    post, err = r.PostsRepository.Get(twitterID)
+    if err != nil {
+      return
+    }
+    if post.EditTweetIDs != strings.Join(editTweetIDs, ",") || post.EditableUntil != editControl.EditableUntil {
+      r.PostsRepository.Updates(post, map[string]interface{}{
+        "initial_id":     editControl.InitialID,
+        "edit_tweet_ids": strings.Join(editTweetIDs, ","),
+        "editable_until": editControl.EditableUntil,
+      })
+    }
+  }
+
+  r.PostsRepository.Metrics(twitterID, ExtractMetrics(tweet))
+  r.PostsRepository.Entities(twitterID, ExtractEntities(tweet))
+
+  return r.PostsRepository.Get(twitterID)
+}
+
+func ParseStatusUrl(url string) (twitterID int64, err error) {
+  re := regexp.MustCompile(`^(?:https?://)?(?:www\.|mobile\.)?(?:twitter|x)\.com/[A-Za-z0-9_]+/status(?:es)?/([0-9]+)`)
+  matches := re.FindStringSubmatch(strings.TrimSpace(url))
+  if len(matches) < 2 {
+    err = errors.New(fmt.Sprintf("status url %v not valid", url))
+    return
+  }
+  return strconv.ParseInt(matches[1], 10, 64)
 }
@@ -1,22 +1,23 @@
 package scrapers
 
 import (
+  "context"
   "encoding/json"
   "errors"
   "fmt"
   "io"
   "log"
-  "net"
   "net/http"
-  "strconv"
-  "strings"
   "time"
 
   "github.com/tidwall/gjson"
   "gorm.io/gorm"
 
+  "scraper.local/twitter-scraper/clients"
   "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
   "scraper.local/twitter-scraper/repositories"
 )
 
@@ -25,9 +26,11 @@ type RepliesRepository struct {
   SessionsRepository *repositories.SessionsRepository
   UsersRepository    *repositories.UsersRepository
   RepliesRepository  *repositories.RepliesRepository
+  ArchivesRepository *repositories.ArchivesRepository
+  DriftRepository    *repositories.DriftRepository
 }
 
-func (r *RepliesRepository) Process(session *models.Session, post *models.Post, params map[string]interface{}) (cursor string, count int, err error) {
+func (r *RepliesRepository) Process(ctx context.Context, session *models.Session, post *models.Post, params map[string]interface{}) (cursor string, count int, err error) {
   var sessionData *repositories.SessionData
   buf, _ := session.Data.MarshalJSON()
   json.Unmarshal(buf, &sessionData)
@@ -74,52 +77,67 @@ func (r *RepliesRepository) Process(session *models.Session, post *models.Post,
     "responsive_web_media_download_video_enabled":                             false,
     "responsive_web_enhance_cards_enabled":                                    false,
   }
+  features = r.SessionsRepository.Operations().Features("TweetDetail", features)
   fieldToggles := map[string]interface{}{
     "withArticleRichContentState": false,
   }
 
-  tr := &http.Transport{
-    DisableKeepAlives: true,
+  body, err := r.Request(ctx, session, sessionData, variables, features, fieldToggles)
+  if err != nil {
+    return
   }
-  if session.Slot > 0 {
-    tr.DialContext = (&common.ProxySession{
-      Proxy: fmt.Sprintf("socks5://127.0.0.1:%d?timeout=30s", 2080+session.Slot),
-    }).DialContext
-  } else {
-    tr.DialContext = (&net.Dialer{}).DialContext
+
+  timeline, count := r.ExtractPage(post, body)
+  if err = r.DriftRepository.Report("TweetDetail", timeline); err != nil {
+    return
+  }
+  cursor = timeline.Cursor("Bottom")
+
+  // "show more replies" inside a conversation module are paged separately,
+  // the response appends the hidden items to the module with TimelineAddToModule.
+  modules := timeline.Cursors("ShowMore", "ShowMoreThreads")
+  for i := 0; i < len(modules) && i < config.SCRAPERS_REPLIES_MODULES_LIMIT; i++ {
+    variables["cursor"] = modules[i]
+    body, err := r.Request(ctx, session, sessionData, variables, features, fieldToggles)
+    if err != nil {
+      log.Println("replies module request error", err)
+      break
+    }
+    module, n := r.ExtractPage(post, body)
+    if err := r.DriftRepository.Report("TweetDetail", module); err != nil {
+      log.Println("replies module drift", err)
+      break
+    }
+    count += n
   }
 
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(15) * time.Second,
+  log.Println("scrapers replies result", count, len(modules), cursor)
+
+  if count == 0 {
+    cursor = ""
   }
 
+  return
+}
+
+func (r *RepliesRepository) Request(
+  ctx context.Context,
+  session *models.Session,
+  sessionData *repositories.SessionData,
+  variables map[string]interface{},
+  features map[string]interface{},
+  fieldToggles map[string]interface{},
+) (body []byte, err error) {
   timestamp := time.Now().UnixMicro()
   if session.UnblockedAt > timestamp {
     err = errors.New("waiting for scrapper unblock")
     return
   }
 
-  headers := map[string]string{
-    "User-Agent":    session.Agent,
-    "cookie":        session.Cookie,
-    "Authorization": fmt.Sprintf("Bearer %v", sessionData.AccessToken),
-  }
-
-  for _, p := range strings.Split(headers["cookie"], ";") {
-    parts := strings.SplitN(p, "=", 2)
-    if strings.Trim(parts[0], " ") == "ct0" {
-      headers["X-Csrf-Token"] = strings.Trim(parts[1], " ")
-      break
-    }
-  }
-
   url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/TweetDetail", sessionData.SectionReplies)
   log.Println("url", url)
-  req, _ := http.NewRequest("GET", url, nil)
-  for key, val := range headers {
-    req.Header.Set(key, val)
-  }
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, sessionData.AccessToken)
   q := req.URL.Query()
   b1, _ := json.Marshal(variables)
   b2, _ := json.Marshal(features)
@@ -128,185 +146,125 @@ func (r *RepliesRepository) Process(session *models.Session, post *models.Post,
   q.Add("features", string(b2))
   q.Add("fieldToggles", string(b3))
   req.URL.RawQuery = q.Encode()
-  resp, err := httpClient.Do(req)
+  resp, err := clients.NewClient(r.SessionsRepository.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
   if err != nil {
-    if session.Slot > 0 {
-      log.Println("request can not be send", 2080+session.Slot)
+    r.SessionsRepository.Unreachable(session, "TweetDetail", err)
+    if session.Proxy != "" {
+      log.Println("request can not be send", common.ProxyName(string(session.Proxy)))
     }
     return
   }
   defer resp.Body.Close()
 
+  r.SessionsRepository.RateLimit(session, "TweetDetail", resp)
+
   if resp.StatusCode != http.StatusOK {
     if resp.StatusCode == 401 {
-      r.SessionsRepository.Update(session, "status", 0)
-    }
-    if resp.StatusCode == 429 {
-      r.SessionsRepository.Update(session, "unblocked_at", timestamp+900000000)
+      r.SessionsRepository.Status(session, 0, fmt.Sprintf("TweetDetail %v", resp.Status))
     }
     err = errors.New(
       fmt.Sprintf(
-        "request error: account[%s] status[%s] code[%d] cookie[%v]",
+        "request error: account[%s] status[%s] code[%d]",
         session.Account,
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
     return
   }
 
-  body, _ := io.ReadAll(resp.Body)
+  body, err = io.ReadAll(resp.Body)
+  if err == nil {
+    r.ArchivesRepository.Create(ctx, session, "TweetDetail", body)
+  }
+  return
+}
 
-  container := gjson.GetBytes(body, "data.threaded_conversation_with_injections_v2")
-  container.Get("instructions").ForEach(func(_, s gjson.Result) bool {
-    if s.Get("type").Str == "TimelineAddEntries" {
-      s.Get("entries").ForEach(func(_, s gjson.Result) bool {
-        if s.Get("content.entryType").Str == "TimelineTimelineModule" {
-          s.Get("content.items").ForEach(func(_, s gjson.Result) bool {
-            if s.Get("item.itemContent.itemType").Str != "TimelineTweet" {
-              return true
-            }
-            twitterID, _ := strconv.ParseInt(s.Get("item.itemContent.tweet_results.result.rest_id").Str, 10, 64)
-            if twitterID == 0 {
-              log.Println("twitter_id zero TimelineTimelineModule")
-              return true
-            }
-            content := s.Get("item.itemContent.tweet_results.result.legacy.full_text").Str
-            createdAt, _ := time.Parse(time.RubyDate, s.Get("item.itemContent.tweet_results.result.legacy.created_at").Str)
-            log.Println("content", twitterID, content, createdAt, variables["focalTweetId"])
-            count++
-            media := &MediaInfo{}
-            user, err := r.ExtractUserInfo(s.Get("item.itemContent.tweet_results.result.core.user_results.result"))
-            if err != nil {
-              log.Println("user info extract error", err)
-              return true
-            }
-            if user.Status != 1 {
-              log.Println("user status is invalid", user.ID, user.Status)
-              return true
-            }
-            s.Get("item.itemContent.tweet_results.result.legacy.entities.media").ForEach(func(_, s gjson.Result) bool {
-              if s.Get("type").Str == "photo" {
-                media.Photos = append(media.Photos, &PhotoInfo{
-                  Url: s.Get("media_url_https").Str,
-                })
-              }
-              if s.Get("type").Str == "video" {
-                videoInfo := &VideoInfo{}
-                videoInfo.Cover = s.Get("media_url_https").Str
-                videoInfo.DurationMillis, _ = strconv.Atoi(s.Get("video_info.duration_millis").Str)
-                s.Get("video_info.aspect_ratio").ForEach(func(_, s gjson.Result) bool {
-                  videoInfo.AspectRatio = append(videoInfo.AspectRatio, int(s.Value().(float64)))
-                  return true
-                })
-                s.Get("video_info.variants").ForEach(func(_, s gjson.Result) bool {
-                  variant := &VideoVariant{}
-                  if s.Get("bitrate").Raw != "" {
-                    variant.Bitrate = int(s.Get("bitrate").Value().(float64))
-                  }
-                  variant.ContentType = s.Get("content_type").Str
-                  variant.Url = s.Get("url").Str
-                  videoInfo.Variants = append(videoInfo.Variants, variant)
-                  return true
-                })
-                media.Videos = append(media.Videos, videoInfo)
-              }
-              return true
-            })
-            status := 1
-            if media.Photos == nil && media.Videos == nil {
-              status = 3
-            }
-            reply, err := r.RepliesRepository.Get(twitterID)
-            if errors.Is(err, gorm.ErrRecordNotFound) {
-              r.RepliesRepository.Create(
-                user.ID,
-                post.ID,
-                twitterID,
-                content,
-                common.JSONMap(media),
-                createdAt.UnixMilli(),
-                status,
-              )
-              r.UsersRepository.Update(user, "replies_count", gorm.Expr("replies_count+1"))
-            } else {
-              if reply.Status != 1 && reply.Status != 2 && reply.Status != 3 {
-                r.RepliesRepository.Updates(reply, map[string]interface{}{
-                  "user_id": user.ID,
-                  "post_id": post.ID,
-                  "content": content,
-                  "media":   common.JSONMap(media),
-                  "status":  status,
-                })
-              }
-            }
-            return true
-          })
-        }
-        if s.Get("content.itemContent.itemType").Str == "TimelineTimelineCursor" {
-          if s.Get("content.itemContent.cursorType").Str == "Bottom" {
-            cursor = s.Get("content.itemContent.value").Str
-          }
-        }
-        return true
-      })
+// ExtractPage parses a TweetDetail page, replies are the tweets of
+// conversation modules, the focal tweet and its ancestors come as plain
+// timeline items.
+func (r *RepliesRepository) ExtractPage(post *models.Post, body []byte) (timeline *parsers.Timeline, count int) {
+  timeline = parsers.ParseTimeline(gjson.GetBytes(body, "data.threaded_conversation_with_injections_v2"))
+  for _, entry := range timeline.Entries {
+    if entry.Tweet == nil || entry.ModuleID == "" {
+      continue
     }
-    return true
-  })
-
-  log.Println("scrapers replies result", count, variables["cursor"], cursor)
-
-  if count == 0 {
-    cursor = ""
+    if err := r.ExtractReply(post, entry.Tweet); err != nil {
+      log.Println("reply extract error", err)
+      continue
+    }
+    count++
   }
-
   return
 }
 
-func (r *RepliesRepository) ExtractUserInfo(s gjson.Result) (*models.User, error) {
-  account := s.Get("legacy.screen_name").Str
-  userID, _ := strconv.ParseInt(strings.Trim(s.Get("rest_id").Raw, "\""), 10, 64)
-  name := s.Get("legacy.name").Str
-  description := s.Get("legacy.description").Str
-  avatar := strings.Replace(s.Get("legacy.profile_image_url_https").Str, "_normal.", ".", 1)
-  favouritesCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.favourites_count").Raw, "\""))
-  followersCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.followers_count").Raw, "\""))
-  friendsCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.friends_count").Raw, "\""))
-  listedCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.listed_count").Raw, "\""))
-  mediaCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.media_count").Raw, "\""))
-  createdAt, _ := time.Parse(time.RubyDate, s.Get("legacy.created_at").Str)
-
-  user, err := r.UsersRepository.GetByUserID(userID)
+func (r *RepliesRepository) ExtractReply(post *models.Post, tweet *parsers.Tweet) (err error) {
+  if !tweet.IsAvailable() {
+    err = errors.New(fmt.Sprintf("tweet typename %v not supported", tweet.Typename))
+    return
+  }
+  if tweet.User == nil {
+    err = errors.New(fmt.Sprintf("tweet %v user is empty", tweet.TwitterID))
+    return
+  }
+  twitterID := tweet.TwitterID
+  inReplyToStatusID := tweet.InReplyToStatusID
+  conversationID := tweet.ConversationID
+  content := tweet.Content
+  media := tweet.Media
+  user, err := (&UsersRepository{
+    UsersRepository: r.UsersRepository,
+  }).Apply(tweet.User)
+  if err != nil {
+    return
+  }
+  if user.Status != 1 {
+    err = errors.New(fmt.Sprintf("user %v status %v is invalid", user.ID, user.Status))
+    return
+  }
+  status := 1
+  if media.IsEmpty() {
+    status = 3
+  }
+  reply, err := r.RepliesRepository.Get(twitterID)
   if errors.Is(err, gorm.ErrRecordNotFound) {
-    user.ID, _ = r.UsersRepository.Create(
-      account,
-      userID,
-      name,
-      description,
-      avatar,
-      favouritesCount,
-      followersCount,
-      friendsCount,
-      listedCount,
-      mediaCount,
-      createdAt.UnixMilli(),
+    _, err = r.RepliesRepository.Create(
+      user.ID,
+      post.ID,
+      twitterID,
+      inReplyToStatusID,
+      conversationID,
+      content,
+      common.JSONMap(media),
+      tweet.Timestamp,
+      status,
     )
-  } else {
-    r.UsersRepository.Updates(user, map[string]interface{}{
-      "account":          account,
-      "user_id":          userID,
-      "name":             name,
-      "description":      description,
-      "avatar":           avatar,
-      "favourites_count": favouritesCount,
-      "followers_count":  followersCount,
-      "friends_count":    friendsCount,
-      "listed_count":     listedCount,
-      "media_count":      mediaCount,
-      "timestamp":        createdAt.UnixMilli(),
+    if err != nil {
+      return
+    }
+    r.UsersRepository.Update(user, "replies_count", gorm.Expr("replies_count+1"))
+  } else if err != nil {
+    return
+  } else if reply.Status != 1 && reply.Status != 2 && reply.Status != 3 {
+    err = r.RepliesRepository.Updates(reply, map[string]interface{}{
+      "user_id":               user.ID,
+      "post_id":               post.ID,
+      "in_reply_to_status_id": inReplyToStatusID,
+      "conversation_id":       conversationID,
+      "content":               content,
+      "media":                 common.JSONMap(media),
+      "status":                status,
+    })
+  } else if reply.ConversationID == 0 {
+    err = r.RepliesRepository.Updates(reply, map[string]interface{}{
+      "in_reply_to_status_id": inReplyToStatusID,
+      "conversation_id":       conversationID,
     })
   }
+  if err != nil {
+    return
+  }
+  r.RepliesRepository.Entities(twitterID, ExtractEntities(tweet))
 
-  return user, nil
+  return r.RepliesRepository.Metrics(twitterID, ExtractMetrics(tweet))
 }
@@ -0,0 +1,167 @@
+package scrapers
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "log"
+  "net/http"
+  "time"
+
+  "github.com/tidwall/gjson"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type SearchRepository struct {
+  Db                 *gorm.DB
+  SessionsRepository *repositories.SessionsRepository
+  UsersRepository    *repositories.UsersRepository
+  PostsRepository    *repositories.PostsRepository
+  SearchRepository   *repositories.SearchRepository
+  ArchivesRepository *repositories.ArchivesRepository
+  DriftRepository    *repositories.DriftRepository
+}
+
+func (r *SearchRepository) Process(ctx context.Context, session *models.Session, task *models.Task) (cursor string, count int, err error) {
+  var sessionData *repositories.SessionData
+  buf, _ := session.Data.MarshalJSON()
+  json.Unmarshal(buf, &sessionData)
+
+  if sessionData.SectionSearch == "" {
+    err = errors.New("search section is empty")
+    return
+  }
+
+  if _, ok := task.Params["query"]; !ok {
+    err = errors.New("search query is empty")
+    return
+  }
+
+  variables := map[string]interface{}{}
+  variables["rawQuery"] = task.Params["query"].(string)
+  variables["count"] = 20
+  if _, ok := task.Params["cursors"]; ok {
+    cursors := task.Params["cursors"].(map[string]interface{})
+    if _, ok := cursors[session.Account]; ok {
+      variables["cursor"] = cursors[session.Account].(string)
+    }
+  }
+  variables["querySource"] = "typed_query"
+  variables["product"] = "Latest"
+  features := map[string]interface{}{
+    "responsive_web_graphql_exclude_directive_enabled":                        true,
+    "verified_phone_label_enabled":                                            false,
+    "creator_subscriptions_tweet_preview_api_enabled":                         true,
+    "responsive_web_graphql_timeline_navigation_enabled":                      true,
+    "responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+    "communities_web_enable_tweet_community_results_fetch":                    true,
+    "c9s_tweet_anatomy_moderator_badge_enabled":                               true,
+    "tweetypie_unmention_optimization_enabled":                                true,
+    "responsive_web_edit_tweet_api_enabled":                                   true,
+    "graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+    "view_counts_everywhere_api_enabled":                                      true,
+    "longform_notetweets_consumption_enabled":                                 true,
+    "responsive_web_twitter_article_tweet_consumption_enabled":                true,
+    "tweet_awards_web_tipping_enabled":                                        false,
+    "freedom_of_speech_not_reach_fetch_enabled":                               true,
+    "standardized_nudges_misinfo":                                             true,
+    "tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+    "rweb_video_timestamps_enabled":                                           true,
+    "longform_notetweets_rich_text_read_enabled":                              true,
+    "longform_notetweets_inline_media_enabled":                                true,
+    "responsive_web_enhance_cards_enabled":                                    false,
+  }
+  features = r.SessionsRepository.Operations().Features("SearchTimeline", features)
+
+  timestamp := time.Now().UnixMicro()
+  if session.UnblockedAt > timestamp {
+    err = errors.New("waiting for scrapper unblock")
+    return
+  }
+
+  url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/SearchTimeline", sessionData.SectionSearch)
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, sessionData.AccessToken)
+  q := req.URL.Query()
+  b1, _ := json.Marshal(variables)
+  b2, _ := json.Marshal(features)
+  q.Add("variables", string(b1))
+  q.Add("features", string(b2))
+  req.URL.RawQuery = q.Encode()
+  resp, err := clients.NewClient(r.SessionsRepository.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
+  if err != nil {
+    r.SessionsRepository.Unreachable(session, "SearchTimeline", err)
+    if session.Proxy != "" {
+      log.Println("request can not be send", common.ProxyName(string(session.Proxy)))
+    }
+    return
+  }
+  defer resp.Body.Close()
+
+  r.SessionsRepository.RateLimit(session, "SearchTimeline", resp)
+
+  if resp.StatusCode != http.StatusOK {
+    if resp.StatusCode == 401 {
+      r.SessionsRepository.Status(session, 0, fmt.Sprintf("SearchTimeline %v", resp.Status))
+    }
+    err = errors.New(
+      fmt.Sprintf(
+        "request error: account[%s] status[%s] code[%d]",
+        session.Account,
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+    return
+  }
+
+  body, _ := io.ReadAll(resp.Body)
+  r.ArchivesRepository.Create(repositories.WithTask(ctx, task.ID), session, "SearchTimeline", body)
+  timeline, count := r.ExtractPage(task, body)
+  if err = r.DriftRepository.Report("SearchTimeline", timeline); err != nil {
+    return
+  }
+  cursor = timeline.Cursor("Bottom")
+
+  log.Println("scrapers search result", count, variables["cursor"], cursor)
+
+  if count == 0 {
+    cursor = ""
+  }
+
+  return
+}
+
+func (r *SearchRepository) ExtractPage(task *models.Task, body []byte) (timeline *parsers.Timeline, count int) {
+  timeline = parsers.ParseTimeline(gjson.GetBytes(body, "data.search_by_raw_query.search_timeline.timeline"))
+  for _, entry := range timeline.Entries {
+    if entry.Tweet == nil || entry.ModuleID != "" {
+      continue
+    }
+    if err := r.ExtractPost(task, entry.Tweet); err != nil {
+      log.Println("search post extract error", err)
+      continue
+    }
+    count++
+  }
+  return
+}
+
+func (r *SearchRepository) ExtractPost(task *models.Task, tweet *parsers.Tweet) (err error) {
+  post, err := (&PostsRepository{
+    UsersRepository: r.UsersRepository,
+    PostsRepository: r.PostsRepository,
+  }).ExtractPost(tweet)
+  if err != nil {
+    return
+  }
+  return r.SearchRepository.Apply(task.ID, post.ID)
+}
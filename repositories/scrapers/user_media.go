@@ -0,0 +1,162 @@
+package scrapers
+
+import (
+  "context"
+  "encoding/json"
+  "errors"
+  "fmt"
+  "io"
+  "log"
+  "net/http"
+  "time"
+
+  "github.com/tidwall/gjson"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/clients"
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
+  "scraper.local/twitter-scraper/repositories"
+)
+
+type UserMediaRepository struct {
+  Db                 *gorm.DB
+  SessionsRepository *repositories.SessionsRepository
+  UsersRepository    *repositories.UsersRepository
+  PostsRepository    *repositories.PostsRepository
+  ArchivesRepository *repositories.ArchivesRepository
+  DriftRepository    *repositories.DriftRepository
+}
+
+func (r *UserMediaRepository) Process(ctx context.Context, session *models.Session, user *models.User, params map[string]interface{}) (cursor string, count int, err error) {
+  var sessionData *repositories.SessionData
+  buf, _ := session.Data.MarshalJSON()
+  json.Unmarshal(buf, &sessionData)
+
+  if sessionData.SectionMedia == "" {
+    err = errors.New("media section is empty")
+    return
+  }
+
+  variables := map[string]interface{}{}
+  variables["userId"] = fmt.Sprintf("%v", user.UserID)
+  variables["count"] = 20
+  if _, ok := params["cursors"]; ok {
+    cursors := params["cursors"].(map[string]interface{})
+    if _, ok := cursors[session.Account]; ok {
+      variables["cursor"] = cursors[session.Account].(string)
+    }
+  }
+  variables["includePromotedContent"] = false
+  variables["withClientEventToken"] = false
+  variables["withBirdwatchNotes"] = false
+  variables["withVoice"] = true
+  variables["withV2Timeline"] = true
+  features := map[string]interface{}{
+    "responsive_web_graphql_exclude_directive_enabled":                        true,
+    "verified_phone_label_enabled":                                            false,
+    "creator_subscriptions_tweet_preview_api_enabled":                         true,
+    "responsive_web_graphql_timeline_navigation_enabled":                      true,
+    "responsive_web_graphql_skip_user_profile_image_extensions_enabled":       false,
+    "communities_web_enable_tweet_community_results_fetch":                    true,
+    "c9s_tweet_anatomy_moderator_badge_enabled":                               true,
+    "tweetypie_unmention_optimization_enabled":                                true,
+    "responsive_web_edit_tweet_api_enabled":                                   true,
+    "graphql_is_translatable_rweb_tweet_is_translatable_enabled":              true,
+    "view_counts_everywhere_api_enabled":                                      true,
+    "longform_notetweets_consumption_enabled":                                 true,
+    "responsive_web_twitter_article_tweet_consumption_enabled":                true,
+    "tweet_awards_web_tipping_enabled":                                        false,
+    "freedom_of_speech_not_reach_fetch_enabled":                               true,
+    "standardized_nudges_misinfo":                                             true,
+    "tweet_with_visibility_results_prefer_gql_limited_actions_policy_enabled": true,
+    "rweb_video_timestamps_enabled":                                           true,
+    "longform_notetweets_rich_text_read_enabled":                              true,
+    "longform_notetweets_inline_media_enabled":                                true,
+    "responsive_web_enhance_cards_enabled":                                    false,
+  }
+  features = r.SessionsRepository.Operations().Features("UserMedia", features)
+  fieldToggles := map[string]interface{}{
+    "withArticlePlainText": false,
+  }
+
+  timestamp := time.Now().UnixMicro()
+  if session.UnblockedAt > timestamp {
+    err = errors.New("waiting for scrapper unblock")
+    return
+  }
+
+  url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/UserMedia", sessionData.SectionMedia)
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, sessionData.AccessToken)
+  q := req.URL.Query()
+  b1, _ := json.Marshal(variables)
+  b2, _ := json.Marshal(features)
+  b3, _ := json.Marshal(fieldToggles)
+  q.Add("variables", string(b1))
+  q.Add("features", string(b2))
+  q.Add("fieldToggles", string(b3))
+  req.URL.RawQuery = q.Encode()
+  resp, err := clients.NewClient(r.SessionsRepository.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
+  if err != nil {
+    r.SessionsRepository.Unreachable(session, "UserMedia", err)
+    if session.Proxy != "" {
+      log.Println("request can not be send", common.ProxyName(string(session.Proxy)))
+    }
+    return
+  }
+  defer resp.Body.Close()
+
+  r.SessionsRepository.RateLimit(session, "UserMedia", resp)
+
+  if resp.StatusCode != http.StatusOK {
+    if resp.StatusCode == 401 {
+      r.SessionsRepository.Status(session, 0, fmt.Sprintf("UserMedia %v", resp.Status))
+    }
+    err = errors.New(
+      fmt.Sprintf(
+        "request error: account[%s] status[%s] code[%d]",
+        session.Account,
+        resp.Status,
+        resp.StatusCode,
+      ),
+    )
+    return
+  }
+
+  body, _ := io.ReadAll(resp.Body)
+  r.ArchivesRepository.Create(ctx, session, "UserMedia", body)
+  timeline, count := r.ExtractPage(user, body)
+  if err = r.DriftRepository.Report("UserMedia", timeline); err != nil {
+    return
+  }
+  cursor = timeline.Cursor("Bottom")
+
+  log.Println("scrapers media result", count, variables["cursor"], cursor)
+
+  if count == 0 {
+    cursor = ""
+  }
+
+  return
+}
+
+// ExtractPage parses a UserMedia page, the first page wraps the media grid
+// in a TimelineTimelineModule entry, following pages append to it with
+// TimelineAddToModule instructions.
+func (r *UserMediaRepository) ExtractPage(user *models.User, body []byte) (timeline *parsers.Timeline, count int) {
+  timeline = parsers.ParseUserTimeline(gjson.GetBytes(body, "data.user.result"), "timeline_v2.timeline")
+  postsRepository := &PostsRepository{
+    UsersRepository: r.UsersRepository,
+    PostsRepository: r.PostsRepository,
+  }
+  for _, tweet := range timeline.Tweets() {
+    if _, err := postsRepository.ExtractTimelinePost(user, tweet); err != nil {
+      log.Println("media post extract error", err)
+      continue
+    }
+    count++
+  }
+  return
+}
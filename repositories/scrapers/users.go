@@ -1,22 +1,23 @@
 package scrapers
 
 import (
+  "context"
   "encoding/json"
   "errors"
   "fmt"
   "io"
   "log"
-  "net"
   "net/http"
-  "strconv"
-  "strings"
   "time"
 
   "github.com/tidwall/gjson"
   "gorm.io/gorm"
 
+  "scraper.local/twitter-scraper/clients"
   "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
   "scraper.local/twitter-scraper/repositories"
 )
 
@@ -26,7 +27,7 @@ type UsersRepository struct {
   UsersRepository    *repositories.UsersRepository
 }
 
-func (r *UsersRepository) Process(session *models.Session, account string) (user *models.User, err error) {
+func (r *UsersRepository) Process(ctx context.Context, session *models.Session, account string) (user *models.User, err error) {
   var sessionData *repositories.SessionData
   buf, _ := session.Data.MarshalJSON()
   json.Unmarshal(buf, &sessionData)
@@ -47,24 +48,10 @@ func (r *UsersRepository) Process(session *models.Session, account string) (user
     "responsive_web_graphql_skip_user_profile_image_extensions_enabled": false,
     "responsive_web_graphql_timeline_navigation_enabled":                true,
   }
+  features = r.SessionsRepository.Operations().Features("UserByScreenName", features)
   fieldToggles := map[string]interface{}{
     "withAuxiliaryUserLabels": false,
   }
-  tr := &http.Transport{
-    DisableKeepAlives: true,
-  }
-  if session.Slot > 0 {
-    tr.DialContext = (&common.ProxySession{
-      Proxy: fmt.Sprintf("socks5://127.0.0.1:%d?timeout=30s", 2080+session.Slot),
-    }).DialContext
-  } else {
-    tr.DialContext = (&net.Dialer{}).DialContext
-  }
-
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(15) * time.Second,
-  }
 
   timestamp := time.Now().UnixMicro()
   if session.UnblockedAt > timestamp {
@@ -72,25 +59,9 @@ func (r *UsersRepository) Process(session *models.Session, account string) (user
     return
   }
 
-  headers := map[string]string{
-    "User-Agent":    session.Agent,
-    "cookie":        session.Cookie,
-    "Authorization": fmt.Sprintf("Bearer %v", sessionData.AccessToken),
-  }
-
-  for _, p := range strings.Split(headers["cookie"], ";") {
-    parts := strings.SplitN(p, "=", 2)
-    if strings.Trim(parts[0], " ") == "ct0" {
-      headers["X-Csrf-Token"] = strings.Trim(parts[1], " ")
-      break
-    }
-  }
-
   url := fmt.Sprintf("https://twitter.com/i/api/graphql/%v/UserByScreenName", sessionData.SecionUsers)
-  req, _ := http.NewRequest("GET", url, nil)
-  for key, val := range headers {
-    req.Header.Set(key, val)
-  }
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Authorize(req, session, sessionData.AccessToken)
   q := req.URL.Query()
   b1, _ := json.Marshal(variables)
   b2, _ := json.Marshal(features)
@@ -99,29 +70,28 @@ func (r *UsersRepository) Process(session *models.Session, account string) (user
   q.Add("features", string(b2))
   q.Add("fieldToggles", string(b3))
   req.URL.RawQuery = q.Encode()
-  resp, err := httpClient.Do(req)
+  resp, err := clients.NewClient(r.SessionsRepository.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
   if err != nil {
-    if session.Slot > 0 {
-      log.Println("request can not be send", 2080+session.Slot)
+    r.SessionsRepository.Unreachable(session, "UserByScreenName", err)
+    if session.Proxy != "" {
+      log.Println("request can not be send", common.ProxyName(string(session.Proxy)))
     }
     return
   }
   defer resp.Body.Close()
 
+  r.SessionsRepository.RateLimit(session, "UserByScreenName", resp)
+
   if resp.StatusCode != http.StatusOK {
-    if resp.StatusCode == 401 {
-      r.SessionsRepository.Update(session, "status", 0)
-    }
-    if resp.StatusCode == 429 {
-      r.SessionsRepository.Update(session, "unblocked_at", timestamp+900000000)
+    if resp.StatusCode == 401 || (resp.StatusCode == 403 && session.Type == config.SESSIONS_TYPE_GUEST) {
+      r.SessionsRepository.Status(session, 0, fmt.Sprintf("UserByScreenName %v", resp.Status))
     }
     err = errors.New(
       fmt.Sprintf(
-        "request error: account[%s] status[%s] code[%d] cookie[%v]",
+        "request error: account[%s] status[%s] code[%d]",
         session.Account,
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
     return
@@ -135,57 +105,45 @@ func (r *UsersRepository) Process(session *models.Session, account string) (user
     return
   }
 
-  user, err = r.ExtractUserInfo(container)
+  user, err = r.Apply(parsers.ParseUser(container))
 
   return
 }
 
-func (r *UsersRepository) ExtractUserInfo(s gjson.Result) (user *models.User, err error) {
-  account := s.Get("legacy.screen_name").Str
-  userID, _ := strconv.ParseInt(strings.Trim(s.Get("rest_id").Raw, "\""), 10, 64)
-  name := s.Get("legacy.name").Str
-  description := s.Get("legacy.description").Str
-  avatar := strings.Replace(s.Get("legacy.profile_image_url_https").Str, "_normal.", ".", 1)
-  favouritesCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.favourites_count").Raw, "\""))
-  followersCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.followers_count").Raw, "\""))
-  friendsCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.friends_count").Raw, "\""))
-  listedCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.listed_count").Raw, "\""))
-  mediaCount, _ := strconv.Atoi(strings.Trim(s.Get("legacy.media_count").Raw, "\""))
-  createdAt, _ := time.Parse(time.RubyDate, s.Get("legacy.created_at").Str)
-
-  if userID == 0 {
-    err = errors.New("user info extract error")
+func (r *UsersRepository) Apply(info *parsers.User) (user *models.User, err error) {
+  if info.UserID == 0 {
+    err = errors.New(fmt.Sprintf("user info extract error %v", info.Reason))
     return
   }
 
-  user, err = r.UsersRepository.GetByUserID(userID)
+  user, err = r.UsersRepository.GetByUserID(info.UserID)
   if errors.Is(err, gorm.ErrRecordNotFound) {
     user.ID, _ = r.UsersRepository.Create(
-      account,
-      userID,
-      name,
-      description,
-      avatar,
-      favouritesCount,
-      followersCount,
-      friendsCount,
-      listedCount,
-      mediaCount,
-      createdAt.UnixMilli(),
+      info.Account,
+      info.UserID,
+      info.Name,
+      info.Description,
+      info.Avatar,
+      info.FavouritesCount,
+      info.FollowersCount,
+      info.FriendsCount,
+      info.ListedCount,
+      info.MediaCount,
+      info.Timestamp,
     )
   } else {
     r.UsersRepository.Updates(user, map[string]interface{}{
-      "account":          account,
-      "user_id":          userID,
-      "name":             name,
-      "description":      description,
-      "avatar":           avatar,
-      "favourites_count": favouritesCount,
-      "followers_count":  followersCount,
-      "friends_count":    friendsCount,
-      "listed_count":     listedCount,
-      "media_count":      mediaCount,
-      "timestamp":        createdAt.UnixMilli(),
+      "account":          info.Account,
+      "user_id":          info.UserID,
+      "name":             info.Name,
+      "description":      info.Description,
+      "avatar":           info.Avatar,
+      "favourites_count": info.FavouritesCount,
+      "followers_count":  info.FollowersCount,
+      "friends_count":    info.FriendsCount,
+      "listed_count":     info.ListedCount,
+      "media_count":      info.MediaCount,
+      "timestamp":        info.Timestamp,
     })
   }
 
@@ -0,0 +1,36 @@
+package repositories
+
+import (
+  "errors"
+  "time"
+
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/models"
+)
+
+type SearchRepository struct {
+  Db *gorm.DB
+}
+
+func (r *SearchRepository) Count(taskID string) int64 {
+  var total int64
+  r.Db.Model(&models.SearchPost{}).Where("task_id", taskID).Count(&total)
+  return total
+}
+
+func (r *SearchRepository) Apply(taskID string, postID string) (err error) {
+  var entity models.SearchPost
+  result := r.Db.Where("task_id=? AND post_id=?", taskID, postID).Take(&entity)
+  if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+    entity = models.SearchPost{
+      ID:        xid.New().String(),
+      TaskID:    taskID,
+      PostID:    postID,
+      Timestamp: time.Now().UnixMilli(),
+    }
+    err = r.Db.Create(&entity).Error
+  }
+  return
+}
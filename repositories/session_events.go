@@ -0,0 +1,52 @@
+package repositories
+
+import (
+  "github.com/rs/xid"
+  "gorm.io/gorm"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/models"
+)
+
+type SessionEventsRepository struct {
+  Db *gorm.DB
+}
+
+func (r *SessionEventsRepository) Create(event *models.SessionEvent) (err error) {
+  event.ID = xid.New().String()
+  event.Reason = common.Truncate(event.Reason, 255)
+  return r.Db.Create(&event).Error
+}
+
+func (r *SessionEventsRepository) Count(sessionID string) int64 {
+  var total int64
+  r.Db.Model(&models.SessionEvent{}).Where("session_id", sessionID).Count(&total)
+  return total
+}
+
+func (r *SessionEventsRepository) Listings(sessionID string, current int, pageSize int) []*models.SessionEvent {
+  var events []*models.SessionEvent
+  r.Db.Where("session_id", sessionID).
+    Order("timestamp desc").
+    Offset((current - 1) * pageSize).
+    Limit(pageSize).
+    Find(&events)
+  return events
+}
+
+// Prune removes the events older than the timestamp.
+func (r *SessionEventsRepository) Prune(before int64) int64 {
+  return r.Db.Where("timestamp < ?", before).Delete(&models.SessionEvent{}).RowsAffected
+}
+
+func (r *SessionEventsRepository) LastFailure(sessionID string) *models.SessionEvent {
+  var events []*models.SessionEvent
+  r.Db.Where("session_id = ? AND success = ?", sessionID, false).
+    Order("timestamp desc").
+    Limit(1).
+    Find(&events)
+  if len(events) == 0 {
+    return nil
+  }
+  return events[0]
+}
@@ -1,27 +1,34 @@
 package repositories
 
 import (
+  "bytes"
   "context"
   "errors"
   "fmt"
   "io"
-  "net"
+  "log"
   "net/http"
   "regexp"
+  "strconv"
   "strings"
   "time"
 
   "github.com/PuerkitoBio/goquery"
+  "github.com/go-redis/redis/v8"
   "github.com/nats-io/nats.go"
   "github.com/rs/xid"
   "gorm.io/gorm"
 
+  "scraper.local/twitter-scraper/clients"
   "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
   "scraper.local/twitter-scraper/models"
+  "scraper.local/twitter-scraper/parsers"
 )
 
 type SessionsRepository struct {
   Db   *gorm.DB
+  Rdb  *redis.Client
   Ctx  context.Context
   Nats *nats.Conn
 }
@@ -39,67 +46,467 @@ func (r *SessionsRepository) Get(account string) (entity *models.Session, err er
 func (r *SessionsRepository) Apply(
   account string,
   cookie string,
-  slot int,
+  proxy string,
 ) (session *models.Session, err error) {
+  twitterID := clients.TwitterID(clients.Cookie(cookie, "twid"))
   result := r.Db.Where("account", account).Take(&session)
   if errors.Is(result.Error, gorm.ErrRecordNotFound) {
     session = &models.Session{
-      ID:      xid.New().String(),
-      Account: account,
-      Node:    common.GetEnvInt("SCRAPER_STORAGE_NODE"),
-      Agent:   common.GetEnvString("SCRAPER_AGENT"),
-      Cookie:  cookie,
-      Slot:    slot,
-      Data:    common.JSONMap(&SessionData{}),
-      Status:  1,
+      ID:        xid.New().String(),
+      Account:   account,
+      TwitterID: twitterID,
+      Node:      common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+      Agent:     common.GetEnvString("SCRAPER_AGENT"),
+      Cookie:    common.Secret(cookie),
+      Proxy:     common.Secret(proxy),
+      Data:      common.SecretJSONMap(&SessionData{}),
+      Status:    1,
     }
     err = r.Db.Create(&session).Error
   } else {
+    if twitterID > 0 && session.TwitterID != twitterID {
+      r.Update(session, "twitter_id", twitterID)
+    }
     if session.Status != 1 && session.Status != 2 {
-      r.Db.Model(&session).Update("status", 1)
+      r.Status(session, 1, "cookie applied")
     }
   }
   return
 }
 
-func (r *SessionsRepository) Current() (session *models.Session) {
-  r.Db.Where("node = ? AND status = 1", common.GetEnvInt("SCRAPER_STORAGE_NODE")).Order("timestamp ASC").Take(&session)
+// Import applies the session of exported cookies, an empty account falls back
+// to the session of the twid user, or to the screen name the cookies are
+// logged in as. A session is never created without its account name.
+func (r *SessionsRepository) Import(
+  ctx context.Context,
+  account string,
+  cookies map[string]string,
+  proxy string,
+) (session *models.Session, err error) {
+  for _, name := range []string{"auth_token", "ct0"} {
+    if cookies[name] == "" {
+      return nil, errors.New(fmt.Sprintf("%v cookie not found", name))
+    }
+  }
+
+  twitterID := clients.TwitterID(cookies["twid"])
+  if account == "" {
+    if twitterID == 0 {
+      return nil, errors.New("twid cookie not found")
+    }
+    var entity *models.Session
+    if err := r.Db.Where("twitter_id", twitterID).Take(&entity).Error; err == nil {
+      account = entity.Account
+    } else {
+      viewerID, name, err := r.Viewer(ctx, cookies, proxy)
+      if err != nil {
+        return nil, errors.New(fmt.Sprintf("account of twid %v can not be resolved: %v", twitterID, err))
+      }
+      if viewerID != twitterID {
+        return nil, errors.New(fmt.Sprintf("twid %v not match the viewer %v", twitterID, viewerID))
+      }
+      account = name
+    }
+  }
+
+  cookie := clients.CookieHeader(cookies)
+  result := r.Db.Where("account", account).Take(&session)
+  if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+    session = &models.Session{
+      ID:        xid.New().String(),
+      Account:   account,
+      TwitterID: twitterID,
+      Node:      common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+      Agent:     common.GetEnvString("SCRAPER_AGENT"),
+      Cookie:    common.Secret(cookie),
+      Proxy:     common.Secret(proxy),
+      Data:      common.SecretJSONMap(&SessionData{}),
+      Status:    1,
+    }
+    err = r.Db.Create(&session).Error
+    return
+  }
+  if result.Error != nil {
+    return nil, result.Error
+  }
+
+  values := map[string]interface{}{
+    "cookie": common.Secret(cookie),
+  }
+  if twitterID > 0 {
+    values["twitter_id"] = twitterID
+  }
+  if proxy != "" {
+    values["proxy"] = common.Secret(proxy)
+  }
+  err = r.Db.Model(&session).Updates(values).Error
+  if err == nil && session.Status != 1 && session.Status != 2 {
+    r.Status(session, 1, "cookies imported")
+  }
   return
 }
 
-func (r *SessionsRepository) Special() (session *models.Session) {
-  r.Db.Where("node = ? AND status = 8", common.GetEnvInt("SCRAPER_STORAGE_NODE")).Order("timestamp ASC").Take(&session)
+// Login runs the onboarding flow with the credential and applies the cookies
+// it produced, the outcome is kept on the credential.
+func (r *SessionsRepository) Login(
+  ctx context.Context,
+  credential *models.Credential,
+  proxy string,
+) (session *models.Session, err error) {
+  resolved, err := r.Proxies().Proxy(proxy, credential.Account)
+  if err != nil {
+    return
+  }
+  flow := clients.NewLoginFlow(resolved, common.GetEnvString("SCRAPER_AGENT"), &clients.Credentials{
+    Username:            credential.Account,
+    Password:            string(credential.Password),
+    AlternateIdentifier: credential.AlternateIdentifier,
+    TotpSecret:          string(credential.TotpSecret),
+  })
+  cookies, err := flow.Login(ctx)
+  if err == nil {
+    session, err = r.Import(ctx, credential.Account, cookies, proxy)
+  }
+  if err != nil {
+    r.Credentials().Updates(credential, map[string]interface{}{
+      "failed_at": time.Now().UnixMicro(),
+      "failures":  credential.Failures + 1,
+      "message":   err.Error(),
+    })
+    return
+  }
+
+  r.Credentials().Updates(credential, map[string]interface{}{
+    "logged_in_at": time.Now().UnixMicro(),
+    "failures":     0,
+    "message":      "",
+  })
+  err = r.Flush(ctx, session)
   return
 }
 
-func (r *SessionsRepository) Flush(session *models.Session) (err error) {
-  tr := &http.Transport{
-    DisableKeepAlives: true,
+// Relogin logs the dead session in again, failed credentials wait for the
+// interval and are given up after the failures limit.
+func (r *SessionsRepository) Relogin(ctx context.Context, session *models.Session) (err error) {
+  credential, err := r.Credentials().Get(session.Account)
+  if err != nil {
+    return
   }
-  if session.Slot > 0 {
-    tr.DialContext = (&common.ProxySession{
-      Proxy: fmt.Sprintf("socks5://127.0.0.1:%d?timeout=30s", 2080+session.Slot),
-    }).DialContext
-  } else {
-    tr.DialContext = (&net.Dialer{}).DialContext
+  if credential.Failures >= config.SESSIONS_RELOGIN_FAILURES_LIMIT {
+    return errors.New("login failures exceeded")
+  }
+  if credential.FailedAt > time.Now().UnixMicro()-config.SESSIONS_RELOGIN_INTERVAL {
+    return errors.New("waiting for login retry")
+  }
+  _, err = r.Login(ctx, credential, string(session.Proxy))
+  return
+}
+
+func (r *SessionsRepository) Deads() (sessions []*models.Session) {
+  r.Db.Where(
+    "node = ? AND type = ? AND status = 0",
+    common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+    config.SESSIONS_TYPE_USER,
+  ).Order("updated_at ASC").Find(&sessions)
+  return
+}
+
+// Reseal writes the cookie and data of every session again, sealing them
+// with the current key. Rows sealed by keys no longer configured fail.
+func (r *SessionsRepository) Reseal() (count int, err error) {
+  current, _, err := common.SecretKeys()
+  if err != nil {
+    return
+  }
+  if current == nil {
+    return 0, errors.New("secret key is empty")
   }
 
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(15) * time.Second,
+  var sessions []*models.Session
+  err = r.Db.FindInBatches(&sessions, 100, func(tx *gorm.DB, batch int) error {
+    for _, session := range sessions {
+      err := r.Db.Model(&session).UpdateColumns(map[string]interface{}{
+        "cookie": session.Cookie,
+        "data":   session.Data,
+        "proxy":  session.Proxy,
+      }).Error
+      if err != nil {
+        return err
+      }
+      count++
+    }
+    return nil
+  }).Error
+  return
+}
+
+func (r *SessionsRepository) Proxies() *ProxiesRepository {
+  return &ProxiesRepository{
+    Db:  r.Db,
+    Rdb: r.Rdb,
+    Ctx: r.Ctx,
   }
+}
 
-  headers := map[string]string{
-    "User-Agent": session.Agent,
-    "cookie":     session.Cookie,
+// Proxy resolves the proxy of the session for a request, a reference which
+// can not be resolved is kept so that the request fails rather than going
+// out directly.
+func (r *SessionsRepository) Proxy(session *models.Session) string {
+  reference := r.reference(session)
+  proxy, err := r.Proxies().Proxy(reference, session.Account)
+  if err != nil {
+    log.Println("session proxy can not be resolved", session.Account, err)
+    return reference
   }
+  return proxy
+}
 
-  url := "https://twitter.com/i/bookmarks"
-  req, _ := http.NewRequest("GET", url, nil)
-  for key, val := range headers {
-    req.Header.Set(key, val)
+// reference is the proxy reference of the session, sessions without one
+// are routed through the pool of SCRAPER_PROXY_GROUP when it is set.
+func (r *SessionsRepository) reference(session *models.Session) string {
+  if session.Proxy == "" {
+    if group := common.GetEnvString("SCRAPER_PROXY_GROUP"); group != "" {
+      return fmt.Sprintf("group://%v", group)
+    }
   }
-  resp, err := httpClient.Do(req)
+  return string(session.Proxy)
+}
+
+func (r *SessionsRepository) Credentials() *CredentialsRepository {
+  return &CredentialsRepository{
+    Db: r.Db,
+  }
+}
+
+// Current picks an active session with rate limit budget left for the
+// operation, an empty operation skips the budget check.
+func (r *SessionsRepository) Current(operation string) *models.Session {
+  return r.Schedule(operation, 1)
+}
+
+func (r *SessionsRepository) Actives() (sessions []*models.Session) {
+  r.Db.Where(
+    "node = ? AND type = ? AND status = 1 AND unblocked_at < ?",
+    common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+    config.SESSIONS_TYPE_USER,
+    time.Now().UnixMicro(),
+  ).Order("timestamp ASC").Find(&sessions)
+  return
+}
+
+func (r *SessionsRepository) Special(operation string) *models.Session {
+  return r.Schedule(operation, 8)
+}
+
+// Schedule returns the least recently used session of the status which still
+// has budget on the operation, public operations fall back to guests.
+func (r *SessionsRepository) Schedule(operation string, status int) *models.Session {
+  var sessions []*models.Session
+  r.Db.Where(
+    "node = ? AND type = ? AND status = ? AND unblocked_at < ?",
+    common.GetEnvInt("SCRAPER_STORAGE_NODE"),
+    config.SESSIONS_TYPE_USER,
+    status,
+    time.Now().UnixMicro(),
+  ).Order("timestamp ASC").Limit(config.SESSIONS_SCHEDULE_LIMIT).Find(&sessions)
+  routable := make(map[string]bool)
+  for _, session := range sessions {
+    if r.isAvailable(session, operation, routable) {
+      return session
+    }
+  }
+  if IsGuestOperation(operation) {
+    session, err := r.Guest(r.Ctx, operation)
+    if err != nil {
+      log.Println("guest session failed", err)
+    }
+    return session
+  }
+  return nil
+}
+
+// Resume prefers a session which already holds a cursor of the task, since
+// cursors are bound to the account that fetched them.
+func (r *SessionsRepository) Resume(params map[string]interface{}, operation string, status int) *models.Session {
+  if _, ok := params["cursors"]; ok {
+    cursors := params["cursors"].(map[string]interface{})
+    for account, _ := range cursors {
+      session, err := r.Get(account)
+      if err == nil && session.Status == status && r.IsAvailable(session, operation) {
+        return session
+      }
+    }
+  }
+  return r.Schedule(operation, status)
+}
+
+func (r *SessionsRepository) IsAvailable(session *models.Session, operation string) bool {
+  return r.isAvailable(session, operation, nil)
+}
+
+// isAvailable only checks the proxy reference of the session can be routed,
+// the proxy itself is resolved once for the request. The routable references
+// are kept in the map while a schedule walks its candidates.
+func (r *SessionsRepository) isAvailable(session *models.Session, operation string, routable map[string]bool) bool {
+  if session.UnblockedAt > time.Now().UnixMicro() {
+    return false
+  }
+  reference := r.reference(session)
+  ok, checked := routable[reference]
+  if !checked {
+    ok = r.Proxies().IsRoutable(reference)
+    if routable != nil {
+      routable[reference] = ok
+    }
+  }
+  if !ok {
+    return false
+  }
+  if r.Rdb == nil || operation == "" {
+    return true
+  }
+  values, _ := r.Rdb.HGetAll(
+    r.Ctx,
+    fmt.Sprintf(config.REDIS_KEY_SESSIONS_RATE_LIMITS, session.ID, operation),
+  ).Result()
+  if len(values) == 0 {
+    return true
+  }
+  remaining, _ := strconv.Atoi(values["remaining"])
+  reset, _ := strconv.ParseInt(values["reset"], 10, 64)
+  return remaining > 0 || reset <= time.Now().Unix()
+}
+
+// RateLimit keeps the x-rate-limit-* budget of the operation, a 429 without
+// headers falls back to the default penalty.
+func (r *SessionsRepository) RateLimit(session *models.Session, operation string, resp *http.Response) {
+  r.count(session, resp.StatusCode == http.StatusOK)
+  if resp.StatusCode != http.StatusOK {
+    r.Fail(session, fmt.Sprintf("%v %v", operation, resp.Status))
+    r.Event(session, config.SESSIONS_EVENT_HTTP, operation, resp.StatusCode, false, resp.Status)
+  }
+
+  limit, _ := strconv.Atoi(resp.Header.Get("x-rate-limit-limit"))
+  remaining, err := strconv.Atoi(resp.Header.Get("x-rate-limit-remaining"))
+  reset, _ := strconv.ParseInt(resp.Header.Get("x-rate-limit-reset"), 10, 64)
+  if resp.StatusCode == http.StatusTooManyRequests {
+    remaining = 0
+    if reset <= time.Now().Unix() {
+      reset = time.Now().Unix() + config.SESSIONS_RATE_LIMIT_PENALTY
+    }
+  } else if err != nil || reset == 0 {
+    return
+  }
+
+  if r.Rdb == nil {
+    if remaining == 0 {
+      r.Update(session, "unblocked_at", reset*1000000)
+    }
+    return
+  }
+
+  key := fmt.Sprintf(config.REDIS_KEY_SESSIONS_RATE_LIMITS, session.ID, operation)
+  r.Rdb.HSet(r.Ctx, key, map[string]interface{}{
+    "limit":     limit,
+    "remaining": remaining,
+    "reset":     reset,
+  })
+  r.Rdb.ExpireAt(r.Ctx, key, time.Unix(reset, 0))
+}
+
+func (r *SessionsRepository) Count(conditions map[string]interface{}) int64 {
+  var total int64
+  r.conditions(r.Db.Model(&models.Session{}), conditions).Count(&total)
+  return total
+}
+
+func (r *SessionsRepository) Listings(conditions map[string]interface{}, current int, pageSize int) []*models.Session {
+  var sessions []*models.Session
+  query := r.conditions(r.Db.Omit("cookie", "data"), conditions)
+  query.Order("created_at desc")
+  query.Offset((current - 1) * pageSize).Limit(pageSize).Find(&sessions)
+  return sessions
+}
+
+func (r *SessionsRepository) conditions(query *gorm.DB, conditions map[string]interface{}) *gorm.DB {
+  if _, ok := conditions["account"]; ok {
+    query.Where("account=?", conditions["account"].(string))
+  }
+  if _, ok := conditions["node"]; ok {
+    query.Where("node=?", conditions["node"].(int))
+  }
+  if _, ok := conditions["type"]; ok {
+    query.Where("type=?", conditions["type"].(int))
+  }
+  if _, ok := conditions["status"]; ok {
+    query.Where("status=?", conditions["status"].(int))
+  }
+  return query
+}
+
+// Block keeps the scheduler away from the session until the timestamp.
+func (r *SessionsRepository) Block(session *models.Session, unblockedAt int64) error {
+  return r.Db.Model(&session).Update("unblocked_at", unblockedAt).Error
+}
+
+// Unblock hands the session back to the scheduler and drops the rate
+// limits it has been given.
+func (r *SessionsRepository) Unblock(session *models.Session) error {
+  r.ResetRateLimits(session)
+  return r.Db.Model(&session).Updates(map[string]interface{}{
+    "unblocked_at": 0,
+    "last_error":   "",
+  }).Error
+}
+
+func (r *SessionsRepository) Delete(session *models.Session) error {
+  r.ResetRateLimits(session)
+  return r.Db.Delete(&session).Error
+}
+
+func (r *SessionsRepository) ResetRateLimits(session *models.Session) {
+  if r.Rdb == nil {
+    return
+  }
+  keys, _ := r.Rdb.Keys(r.Ctx, fmt.Sprintf(config.REDIS_KEY_SESSIONS_RATE_LIMITS, session.ID, "*")).Result()
+  if len(keys) > 0 {
+    r.Rdb.Del(r.Ctx, keys...)
+  }
+}
+
+func (r *SessionsRepository) RateLimits(session *models.Session) map[string]map[string]string {
+  limits := make(map[string]map[string]string)
+  if r.Rdb == nil {
+    return limits
+  }
+  pattern := fmt.Sprintf(config.REDIS_KEY_SESSIONS_RATE_LIMITS, session.ID, "*")
+  keys, _ := r.Rdb.Keys(r.Ctx, pattern).Result()
+  for _, key := range keys {
+    operation := key[len(pattern)-1:]
+    limits[operation], _ = r.Rdb.HGetAll(r.Ctx, key).Result()
+  }
+  return limits
+}
+
+// Fail keeps the last error of the session for the operators.
+func (r *SessionsRepository) Fail(session *models.Session, message string) {
+  r.Update(session, "last_error", common.Truncate(message, 255))
+}
+
+func (r *SessionsRepository) Flush(ctx context.Context, session *models.Session) (err error) {
+  defer func() {
+    reason := ""
+    if err != nil {
+      reason = err.Error()
+      r.Fail(session, fmt.Sprintf("Flush %v", err))
+    }
+    r.Event(session, config.SESSIONS_EVENT_FLUSH, "", 0, err == nil, reason)
+  }()
+
+  url := "https://twitter.com/i/bookmarks"
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Browse(req, session)
+  resp, err := clients.NewClient(r.Proxy(session), time.Duration(15)*time.Second).Do(ctx, req)
   if err != nil {
     return
   }
@@ -108,15 +515,21 @@ func (r *SessionsRepository) Flush(session *models.Session) (err error) {
   if resp.StatusCode != http.StatusOK {
     return errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
   }
 
-  doc, err := goquery.NewDocumentFromReader(resp.Body)
+  body, err := io.ReadAll(resp.Body)
+  if err != nil {
+    return
+  }
+
+  r.Operations().SaveFeatureSwitches(parsers.ParseFeatureSwitches(string(body)))
+
+  doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
   if err != nil {
     return
   }
@@ -124,7 +537,7 @@ func (r *SessionsRepository) Flush(session *models.Session) (err error) {
   doc.Find("script").Each(func(i int, s *goquery.Selection) {
     if src, ok := s.Attr("src"); ok {
       if strings.Contains(src, "client-web/main.") || strings.Contains(src, "client-web-legacy/main.") {
-        err = r.ExtractMainJS(session, src)
+        err = r.ExtractMainJS(ctx, session, src)
       }
     }
   })
@@ -132,27 +545,10 @@ func (r *SessionsRepository) Flush(session *models.Session) (err error) {
   return
 }
 
-func (r *SessionsRepository) ExtractMainJS(session *models.Session, url string) (err error) {
-  tr := &http.Transport{
-    DisableKeepAlives: true,
-  }
-  tr.DialContext = (&net.Dialer{}).DialContext
-
-  httpClient := &http.Client{
-    Transport: tr,
-    Timeout:   time.Duration(15) * time.Second,
-  }
-
-  headers := map[string]string{
-    "User-Agent": session.Agent,
-    "cookie":     session.Cookie,
-  }
-
-  req, _ := http.NewRequest("GET", url, nil)
-  for key, val := range headers {
-    req.Header.Set(key, val)
-  }
-  resp, err := httpClient.Do(req)
+func (r *SessionsRepository) ExtractMainJS(ctx context.Context, session *models.Session, url string) (err error) {
+  req, _ := http.NewRequestWithContext(ctx, "GET", url, nil)
+  clients.Browse(req, session)
+  resp, err := clients.NewClient("", time.Duration(15)*time.Second).Do(ctx, req)
   if err != nil {
     return
   }
@@ -161,10 +557,9 @@ func (r *SessionsRepository) ExtractMainJS(session *models.Session, url string)
   if resp.StatusCode != http.StatusOK {
     return errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
   }
@@ -181,32 +576,48 @@ func (r *SessionsRepository) ExtractMainJS(session *models.Session, url string)
   data := &SessionData{}
   data.AccessToken = matches[0]
 
-  re = regexp.MustCompile(`"([a-zA-Z0-9-_]*)",operationName:"UserByScreenName"`)
-  matches = re.FindStringSubmatch(content)
-  if len(matches) > 1 {
-    data.SecionUsers = matches[1]
-  }
-
-  re = regexp.MustCompile(`"([a-zA-Z0-9-_]*)",operationName:"UserTweets"`)
-  matches = re.FindStringSubmatch(content)
-  if len(matches) > 1 {
-    data.SectionPosts = matches[1]
+  operations := parsers.ParseOperations(content)
+  for name, section := range r.Sections(data) {
+    if operation, ok := operations[name]; ok {
+      *section = operation.QueryID
+    }
   }
 
-  re = regexp.MustCompile(`"([a-zA-Z0-9-_]*)",operationName:"TweetDetail"`)
-  matches = re.FindStringSubmatch(content)
-  if len(matches) > 1 {
-    data.SectionReplies = matches[1]
+  if changed, err := r.Operations().Save(operations); err != nil {
+    log.Println("operations can not be saved", err)
+  } else if changed {
+    log.Println("operations registry changed", len(operations))
   }
 
   r.Db.Model(&session).Updates(map[string]interface{}{
-    "data":       common.JSONMap(data),
+    "data":       common.SecretJSONMap(data),
     "flushed_at": time.Now().UnixMicro(),
   })
 
   return
 }
 
+// Sections maps the graphql operations to the query ids of the data.
+func (r *SessionsRepository) Sections(data *SessionData) map[string]*string {
+  return map[string]*string{
+    "UserByScreenName":    &data.SecionUsers,
+    "UserTweets":          &data.SectionPosts,
+    "UserMedia":           &data.SectionMedia,
+    "TweetDetail":         &data.SectionReplies,
+    "TweetResultByRestId": &data.SectionTweet,
+    "SearchTimeline":      &data.SectionSearch,
+    "Followers":           &data.SectionFollowers,
+    "Following":           &data.SectionFollowing,
+  }
+}
+
+func (r *SessionsRepository) Operations() *OperationsRepository {
+  return &OperationsRepository{
+    Rdb: r.Rdb,
+    Ctx: r.Ctx,
+  }
+}
+
 func (r *SessionsRepository) Update(session *models.Session, column string, value interface{}) (err error) {
   r.Db.Model(&session).Update(column, value)
   return nil
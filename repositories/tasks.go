@@ -114,8 +114,29 @@ func (r *TasksRepository) Apply(name string, action int, params map[string]inter
     }
     r.Db.Create(&task)
   } else {
+    if task.Params == nil {
+      task.Params = map[string]interface{}{}
+    }
+    values := map[string]interface{}{}
+    for key, value := range params {
+      if _, ok := task.Params[key]; !ok && value == false {
+        // flags which older tasks lack default to false
+        continue
+      }
+      if fmt.Sprint(task.Params[key]) != fmt.Sprint(value) {
+        task.Params[key] = value
+        values["params"] = task.Params
+      }
+    }
+    if _, ok := values["params"]; ok {
+      // cursors belong to the previous params and can not be resumed
+      delete(task.Params, "cursors")
+    }
     if task.Status != 1 && task.Status != 2 {
-      r.Db.Model(&task).Update("status", 1)
+      values["status"] = 1
+    }
+    if len(values) > 0 {
+      r.Db.Model(&task).Updates(values)
     }
   }
   return
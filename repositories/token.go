@@ -1,6 +1,7 @@
 package repositories
 
 import (
+  "bytes"
   "context"
   "errors"
   "fmt"
@@ -16,6 +17,7 @@ import (
 
   "scraper.local/twitter-scraper/common"
   "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/parsers"
 )
 
 type TokenRepository struct {
@@ -54,15 +56,25 @@ func (r *TokenRepository) Refresh() (err error) {
   if resp.StatusCode != http.StatusOK {
     return errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
   }
 
-  doc, err := goquery.NewDocumentFromReader(resp.Body)
+  body, err := io.ReadAll(resp.Body)
+  if err != nil {
+    return
+  }
+
+  operations := &OperationsRepository{
+    Rdb: r.Rdb,
+    Ctx: r.Ctx,
+  }
+  operations.SaveFeatureSwitches(parsers.ParseFeatureSwitches(string(body)))
+
+  doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
   if err != nil {
     return
   }
@@ -112,10 +124,9 @@ func (r *TokenRepository) ExtractMainJS(url string) (err error) {
   if resp.StatusCode != http.StatusOK {
     return errors.New(
       fmt.Sprintf(
-        "request error: status[%s] code[%d] cookie[%v]",
+        "request error: status[%s] code[%d]",
         resp.Status,
         resp.StatusCode,
-        common.GetEnvString("cookie"),
       ),
     )
   }
@@ -135,16 +146,12 @@ func (r *TokenRepository) ExtractMainJS(url string) (err error) {
     "flushed_at":   time.Now().Unix(),
   }
 
-  re = regexp.MustCompile(`"([a-zA-Z0-9-_]*)",operationName:"UserTweets"`)
-  matches = re.FindStringSubmatch(content)
-  if len(matches) > 1 {
-    data["section_posts"] = matches[1]
+  operations := &OperationsRepository{
+    Rdb: r.Rdb,
+    Ctx: r.Ctx,
   }
-
-  re = regexp.MustCompile(`"([a-zA-Z0-9-_]*)",operationName:"TweetDetail"`)
-  matches = re.FindStringSubmatch(content)
-  if len(matches) > 1 {
-    data["section_replies"] = matches[1]
+  if _, err = operations.Save(parsers.ParseOperations(content)); err != nil {
+    return
   }
 
   r.Rdb.HMSet(
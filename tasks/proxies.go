@@ -0,0 +1,36 @@
+package tasks
+
+import (
+  "log"
+  "time"
+
+  "github.com/hibiken/asynq"
+
+  "scraper.local/twitter-scraper/common"
+  "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/queue/asynq/jobs"
+)
+
+type ProxiesTask struct {
+  Job         *jobs.Proxies
+  AnsqContext *common.AnsqClientContext
+}
+
+func NewProxiesTask(ansqContext *common.AnsqClientContext) *ProxiesTask {
+  return &ProxiesTask{
+    AnsqContext: ansqContext,
+  }
+}
+
+func (t *ProxiesTask) Check() (err error) {
+  log.Println("tasks proxies check")
+  if job, err := t.Job.Check(); err == nil {
+    t.AnsqContext.Conn.Enqueue(
+      job,
+      asynq.Queue(config.ASYNQ_QUEUE_SESSIONS),
+      asynq.MaxRetry(0),
+      asynq.Timeout(10*time.Minute),
+    )
+  }
+  return
+}
@@ -10,6 +10,8 @@ type ScrapersTask struct {
   UsersTask   *tasks.UsersTask
   PostsTask   *tasks.PostsTask
   RepliesTask *tasks.RepliesTask
+  SearchTask  *tasks.SearchTask
+  FollowsTask *tasks.FollowsTask
 }
 
 func NewScrapersTask(ansqContext *common.AnsqClientContext) *ScrapersTask {
@@ -38,3 +40,17 @@ func (t *ScrapersTask) Replies() *tasks.RepliesTask {
   }
   return t.RepliesTask
 }
+
+func (t *ScrapersTask) Search() *tasks.SearchTask {
+  if t.SearchTask == nil {
+    t.SearchTask = tasks.NewSearchTask(t.AnsqContext)
+  }
+  return t.SearchTask
+}
+
+func (t *ScrapersTask) Follows() *tasks.FollowsTask {
+  if t.FollowsTask == nil {
+    t.FollowsTask = tasks.NewFollowsTask(t.AnsqContext)
+  }
+  return t.FollowsTask
+}
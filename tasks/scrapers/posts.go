@@ -9,14 +9,17 @@ import (
 
   "scraper.local/twitter-scraper/common"
   "scraper.local/twitter-scraper/config"
+  "scraper.local/twitter-scraper/models"
   jobs "scraper.local/twitter-scraper/queue/asynq/jobs/scrapers"
   "scraper.local/twitter-scraper/repositories"
 )
 
 type PostsTask struct {
-  Job             *jobs.Posts
-  AnsqContext     *common.AnsqClientContext
-  TasksRepository *repositories.TasksRepository
+  Job                *jobs.Posts
+  AnsqContext        *common.AnsqClientContext
+  TasksRepository    *repositories.TasksRepository
+  PostsRepository    *repositories.PostsRepository
+  SessionsRepository *repositories.SessionsRepository
 }
 
 func NewPostsTask(ansqContext *common.AnsqClientContext) *PostsTask {
@@ -25,6 +28,14 @@ func NewPostsTask(ansqContext *common.AnsqClientContext) *PostsTask {
     TasksRepository: &repositories.TasksRepository{
       Db: ansqContext.Db,
     },
+    PostsRepository: &repositories.PostsRepository{
+      Db: ansqContext.Db,
+    },
+    SessionsRepository: &repositories.SessionsRepository{
+      Db:  ansqContext.Db,
+      Rdb: ansqContext.Rdb,
+      Ctx: ansqContext.Ctx,
+    },
   }
 }
 
@@ -107,3 +118,43 @@ func (t *PostsTask) Process(limit int) (err error) {
   }
   return
 }
+
+// Verify hands every active session its own batch of posts, budget is the
+// number of re-checks per session.
+func (t *PostsTask) Verify(budget int) (err error) {
+  log.Println("tasks scrapers posts verify")
+  var sessions []*models.Session
+  for _, session := range t.SessionsRepository.Actives() {
+    if t.SessionsRepository.IsAvailable(session, "TweetResultByRestId") {
+      sessions = append(sessions, session)
+    }
+  }
+  if len(sessions) == 0 {
+    return
+  }
+  timestamp := time.Now().UnixMilli()
+  posts := t.PostsRepository.Verifying(timestamp-config.SCRAPERS_POSTS_VERIFY_INTERVAL, budget*len(sessions))
+  for i, session := range sessions {
+    start := i * budget
+    if start >= len(posts) {
+      break
+    }
+    end := start + budget
+    if end > len(posts) {
+      end = len(posts)
+    }
+    var postIDs []string
+    for _, post := range posts[start:end] {
+      postIDs = append(postIDs, post.ID)
+    }
+    if job, err := t.Job.Verify(session.Account, postIDs); err == nil {
+      t.AnsqContext.Conn.Enqueue(
+        job,
+        asynq.Queue(config.ASYNQ_QUEUE_SCRAPERS_POSTS),
+        asynq.MaxRetry(0),
+        asynq.Timeout(5*time.Minute),
+      )
+    }
+  }
+  return
+}
@@ -34,3 +34,29 @@ func (t *SessionsTask) Flush() (err error) {
   }
   return
 }
+
+func (t *SessionsTask) Relogin() (err error) {
+  log.Println("tasks sessions relogin")
+  if job, err := t.Job.Relogin(); err == nil {
+    t.AnsqContext.Conn.Enqueue(
+      job,
+      asynq.Queue(config.ASYNQ_QUEUE_SESSIONS),
+      asynq.MaxRetry(0),
+      asynq.Timeout(10*time.Minute),
+    )
+  }
+  return
+}
+
+func (t *SessionsTask) Probe() (err error) {
+  log.Println("tasks sessions probe")
+  if job, err := t.Job.Probe(); err == nil {
+    t.AnsqContext.Conn.Enqueue(
+      job,
+      asynq.Queue(config.ASYNQ_QUEUE_SESSIONS),
+      asynq.MaxRetry(0),
+      asynq.Timeout(10*time.Minute),
+    )
+  }
+  return
+}